@@ -1,12 +1,14 @@
 module github.com/frigus02/kyml
 
 require (
+	github.com/google/cel-go v0.9.0
 	github.com/google/go-cmp v0.5.6 // indirect
 	github.com/google/gofuzz v1.2.0 // indirect
 	github.com/json-iterator/go v1.1.11 // indirect
 	github.com/pmezard/go-difflib v1.0.0
 	github.com/spf13/cobra v1.1.3
 	golang.org/x/net v0.23.0 // indirect
+	google.golang.org/protobuf v1.27.1
 	k8s.io/apimachinery v0.21.2
 	k8s.io/klog/v2 v2.9.0 // indirect
 	sigs.k8s.io/structured-merge-diff/v4 v4.1.1 // indirect
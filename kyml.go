@@ -4,12 +4,9 @@ import (
 	"os"
 
 	"github.com/frigus02/kyml/pkg/commands"
+	"github.com/frigus02/kyml/pkg/exitcode"
 )
 
 func main() {
-	if err := commands.NewRootCommand().Execute(); err != nil {
-		os.Exit(1)
-	}
-
-	os.Exit(0)
+	os.Exit(exitcode.Get(commands.NewRootCommand().Execute()))
 }
@@ -13,21 +13,32 @@ import (
 // another in the specified writer. If a YAML document has the same apiVersion,
 // kind, namespace and name as a previous one it replaces it in the output.
 func Cat(out io.Writer, files []string, fs fs.Filesystem) error {
+	documents, err := CatDecodeOnly(files, fs)
+	if err != nil {
+		return err
+	}
+
+	return k8syaml.Encode(out, documents)
+}
+
+// CatDecodeOnly works like Cat, but returns a slice of unstructured objects
+// instead of writing them to an output.
+func CatDecodeOnly(files []string, fs fs.Filesystem) ([]*unstructured.Unstructured, error) {
 	var documents []*unstructured.Unstructured
 	for _, filename := range files {
 		file, err := fs.Open(filename)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		docsInFile, err := k8syaml.Decode(file)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		err = file.Close()
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		documents = addOrReplaceExistingDocs(documents, docsInFile)
@@ -35,7 +46,7 @@ func Cat(out io.Writer, files []string, fs fs.Filesystem) error {
 
 	sortDocs(documents)
 
-	return k8syaml.Encode(out, documents)
+	return documents, nil
 }
 
 // Stream reads YAML documents from the specified reader and prints them one
@@ -59,10 +70,17 @@ func StreamDecodeOnly(stream io.Reader) ([]*unstructured.Unstructured, error) {
 		return nil, err
 	}
 
+	return Normalize(docsInStream), nil
+}
+
+// Normalize deduplicates and sorts the specified documents in the same way as
+// Cat and Stream do. Use it after changing the apiVersion, kind, namespace or
+// name of documents.
+func Normalize(docs []*unstructured.Unstructured) []*unstructured.Unstructured {
 	var documents []*unstructured.Unstructured
-	documents = addOrReplaceExistingDocs(documents, docsInStream)
+	documents = addOrReplaceExistingDocs(documents, docs)
 
 	sortDocs(documents)
 
-	return documents, nil
+	return documents
 }
@@ -0,0 +1,35 @@
+package cat
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/frigus02/kyml/pkg/fs"
+)
+
+// ExpandGlobs replaces glob patterns in the specified files with the files
+// they match in the filesystem, e.g. when the shell cannot expand them because
+// the files are read from a git revision. Every pattern has to match at least
+// one file. Other files are returned as is.
+func ExpandGlobs(files []string, fs fs.Filesystem) ([]string, error) {
+	var result []string
+	for _, file := range files {
+		if !strings.ContainsAny(file, "*?[") {
+			result = append(result, file)
+			continue
+		}
+
+		matches, err := fs.Glob(file)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("pattern %s matches no files", file)
+		}
+
+		result = append(result, matches...)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,57 @@
+package cat
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/frigus02/kyml/pkg/fs"
+)
+
+func TestExpandGlobs(t *testing.T) {
+	testFs := fs.NewFakeFilesystem()
+	for _, name := range []string{"prod/a.yaml", "prod/b.yaml", "prod/c.json", "staging/a.yaml"} {
+		if err := testFs.WriteFile(name, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		files   []string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:  "files",
+			files: []string{"staging/a.yaml", "prod/a.yaml"},
+			want:  []string{"staging/a.yaml", "prod/a.yaml"},
+		},
+		{
+			name:  "patterns",
+			files: []string{"staging/a.yaml", "prod/*.yaml", "prod/?.json"},
+			want:  []string{"staging/a.yaml", "prod/a.yaml", "prod/b.yaml", "prod/c.json"},
+		},
+		{
+			name:    "pattern without matches",
+			files:   []string{"dev/*.yaml"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid pattern",
+			files:   []string{"prod/[.yaml"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandGlobs(tt.files, testFs)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ExpandGlobs() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExpandGlobs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
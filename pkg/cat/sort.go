@@ -12,6 +12,7 @@ var gvkOrder = []schema.GroupVersionKind{
 	{Group: "", Version: "v1", Kind: "Namespace"},
 
 	// Custom resources require the definition.
+	{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"},
 	{Group: "apiextensions.k8s.io", Version: "v1beta1", Kind: "CustomResourceDefinition"},
 
 	// StorageClasses can be configured as default, so that PVCs can use them
@@ -45,6 +46,7 @@ var gvkOrder = []schema.GroupVersionKind{
 	{Group: "apps", Version: "v1", Kind: "ReplicaSet"},
 	{Group: "apps", Version: "v1", Kind: "StatefulSet"},
 	{Group: "batch", Version: "v1", Kind: "Job"},
+	{Group: "batch", Version: "v1", Kind: "CronJob"},
 	{Group: "batch", Version: "v1beta1", Kind: "CronJob"},
 	{Group: "", Version: "v1", Kind: "ReplicationController"},
 }
@@ -9,8 +9,12 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// newGitFilesystem creates the filesystem used with "--rev".
+var newGitFilesystem = fs.NewGitFilesystem
+
 type catOptions struct {
 	files []string
+	rev   string
 }
 
 // NewCmdCat creates a new cat command.
@@ -27,7 +31,9 @@ YAML documents are changed in the following ways:
 - Documents are deduplicated. If multiple YAML documents refer to the same Kubernetes resource, only the last one will appear in the result.
 - Documents are sorted by dependencies, e.g. namespaces come before deployments.
 
-The result of this command can be piped into other commands like "kyml test" or "kubectl apply".`,
+The result of this command can be piped into other commands like "kyml test" or "kubectl apply".
+
+Use "--rev" to read the files at a git revision of the local repository instead of the working tree, e.g. to see the manifests of another branch. Quote glob patterns, so they are expanded at the revision and not by the shell.`,
 		Example: `  # Cat one folder
   kyml cat production/*
 
@@ -35,7 +41,10 @@ The result of this command can be piped into other commands like "kyml test" or
   kyml cat base/* overlay-production/*
 
   # Specify files individually
-  kyml cat prod/deployment.yaml prod/service.yaml prod/ingress.yaml`,
+  kyml cat prod/deployment.yaml prod/service.yaml prod/ingress.yaml
+
+  # Cat files at a git revision
+  kyml cat --rev origin/main 'production/*'`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			err := o.Validate(args)
 			if err != nil {
@@ -46,6 +55,8 @@ The result of this command can be piped into other commands like "kyml test" or
 		},
 	}
 
+	cmd.Flags().StringVar(&o.rev, "rev", "", "Read files at the specified git revision of the local repository")
+
 	// Cat supports infinite positional file arguments, however zsh completions
 	// require each positional argument to be marked individually. We just mark
 	// the first few.
@@ -70,5 +81,17 @@ func (o *catOptions) Validate(args []string) error {
 
 // Run runs cat command.
 func (o *catOptions) Run(out io.Writer, fs fs.Filesystem) error {
-	return cat.Cat(out, o.files, fs)
+	files := o.files
+	if o.rev != "" {
+		var err error
+		if fs, err = newGitFilesystem("", o.rev); err != nil {
+			return err
+		}
+
+		if files, err = cat.ExpandGlobs(files, fs); err != nil {
+			return err
+		}
+	}
+
+	return cat.Cat(out, files, fs)
 }
@@ -1,8 +1,11 @@
 package cat
 
 import (
+	"bytes"
 	"reflect"
 	"testing"
+
+	"github.com/frigus02/kyml/pkg/fs"
 )
 
 func Test_catOptions_Validate(t *testing.T) {
@@ -45,3 +48,23 @@ func Test_catOptions_Validate(t *testing.T) {
 		})
 	}
 }
+
+func Test_catOptions_Run_rev(t *testing.T) {
+	originalNewGitFilesystem := newGitFilesystem
+	defer func() { newGitFilesystem = originalNewGitFilesystem }()
+	newGitFilesystem = func(dir, rev string) (fs.Filesystem, error) {
+		revFs := fs.NewFakeFilesystem()
+		err := revFs.WriteFile("prod/service.yaml", []byte("apiVersion: v1\nkind: Service\nmetadata:\n  name: "+rev+"\n"), 0644)
+		return revFs, err
+	}
+
+	o := &catOptions{files: []string{"prod/*.yaml"}, rev: "main"}
+	out := &bytes.Buffer{}
+	if err := o.Run(out, fs.NewFakeFilesystem()); err != nil {
+		t.Fatalf("catOptions.Run() error = %v", err)
+	}
+
+	if want := "---\napiVersion: v1\nkind: Service\nmetadata:\n  name: main\n"; out.String() != want {
+		t.Errorf("catOptions.Run() = %q, want %q", out.String(), want)
+	}
+}
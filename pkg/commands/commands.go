@@ -5,9 +5,16 @@ import (
 
 	"github.com/frigus02/kyml/pkg/commands/cat"
 	"github.com/frigus02/kyml/pkg/commands/completion"
+	"github.com/frigus02/kyml/pkg/commands/deprecations"
+	"github.com/frigus02/kyml/pkg/commands/diff"
+	"github.com/frigus02/kyml/pkg/commands/generate"
+	"github.com/frigus02/kyml/pkg/commands/hash"
+	"github.com/frigus02/kyml/pkg/commands/lint"
+	"github.com/frigus02/kyml/pkg/commands/migrate"
 	"github.com/frigus02/kyml/pkg/commands/resolve"
 	"github.com/frigus02/kyml/pkg/commands/test"
 	"github.com/frigus02/kyml/pkg/commands/tmpl"
+	"github.com/frigus02/kyml/pkg/commands/validate"
 	"github.com/frigus02/kyml/pkg/fs"
 	"github.com/spf13/cobra"
 )
@@ -28,9 +35,16 @@ func NewRootCommand() *cobra.Command {
 	c.AddCommand(
 		cat.NewCmdCat(os.Stdout, osFs),
 		completion.NewCmdCompletion(os.Stdout, c),
+		deprecations.NewCmdDeprecations(os.Stdin, os.Stdout, os.Stderr),
+		diff.NewCmdDiff(os.Stdin, os.Stdout, osFs),
+		generate.NewCmdGenerate(os.Stdin, os.Stdout, osFs),
+		hash.NewCmdHash(os.Stdin, os.Stdout),
+		lint.NewCmdLint(os.Stdin, os.Stdout, osFs, version),
+		migrate.NewCmdMigrate(os.Stdin, os.Stdout, os.Stderr),
 		resolve.NewCmdResolve(os.Stdin, os.Stdout),
 		test.NewCmdTest(os.Stdin, os.Stdout, osFs),
-		tmpl.NewCmdTmpl(os.Stdin, os.Stdout),
+		tmpl.NewCmdTmpl(os.Stdin, os.Stdout, osFs),
+		validate.NewCmdValidate(os.Stdin, os.Stdout, osFs),
 	)
 
 	return c
@@ -0,0 +1,95 @@
+package deprecations
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/frigus02/kyml/pkg/cat"
+	"github.com/frigus02/kyml/pkg/deprecation"
+	"github.com/frigus02/kyml/pkg/k8syaml"
+	"github.com/frigus02/kyml/pkg/kubeversion"
+	"github.com/frigus02/kyml/pkg/validate"
+	"github.com/spf13/cobra"
+)
+
+type deprecationsOptions struct {
+	kubernetesVersion string
+	failOnDeprecated  bool
+}
+
+// NewCmdDeprecations creates a new deprecations command.
+func NewCmdDeprecations(in io.Reader, out, errOut io.Writer) *cobra.Command {
+	var o deprecationsOptions
+
+	cmd := &cobra.Command{
+		Use:   "deprecations",
+		Short: "Detect deprecated and removed Kubernetes API versions",
+		Long: `Detect Kubernetes YAML documents, which use an API version that is deprecated or removed in the specified Kubernetes version. Data is read from stdin.
+
+For every affected document the command names the API, which should be used instead. Documents using deprecated APIs result in warnings on stderr. Documents using removed APIs result in an error and a non-zero exit code. Use "--fail-on-deprecated" to fail on deprecated APIs as well.
+
+If the command doesn't fail, it prints the documents to stdout, so it can be used as a stage in a deployment pipeline.`,
+		Example: `  # Make sure nothing uses APIs removed in the cluster version
+  kyml cat production/* |
+    kyml deprecations --kubernetes-version 1.29 |
+    kubectl apply -f -
+
+  # Fail on deprecated APIs in CI
+  kyml cat production/* | kyml deprecations --kubernetes-version 1.29 --fail-on-deprecated`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := o.Validate(args)
+			if err != nil {
+				return err
+			}
+
+			return o.Run(in, out, errOut)
+		},
+	}
+
+	cmd.Flags().StringVar(&o.kubernetesVersion, "kubernetes-version", validate.LatestBundledVersion().String(), "Kubernetes version to check against")
+	cmd.Flags().BoolVar(&o.failOnDeprecated, "fail-on-deprecated", false, "Fail if documents use deprecated APIs, which are still served")
+
+	return cmd
+}
+
+// Validate validates deprecations command.
+func (o *deprecationsOptions) Validate(args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("this command takes no positional arguments")
+	}
+
+	if _, err := kubeversion.Parse(o.kubernetesVersion); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Run runs deprecations command.
+func (o *deprecationsOptions) Run(in io.Reader, out, errOut io.Writer) error {
+	version, err := kubeversion.Parse(o.kubernetesVersion)
+	if err != nil {
+		return err
+	}
+
+	documents, err := cat.StreamDecodeOnly(in)
+	if err != nil {
+		return err
+	}
+
+	var problems []string
+	for _, finding := range deprecation.Check(documents, version) {
+		if finding.Removed || o.failOnDeprecated {
+			problems = append(problems, finding.String())
+		} else {
+			fmt.Fprintf(errOut, "Warning: %s\n", finding)
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("documents use APIs not supported by Kubernetes %s\n\n%s", version, strings.Join(problems, "\n"))
+	}
+
+	return k8syaml.Encode(out, documents)
+}
@@ -0,0 +1,134 @@
+package deprecations
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+var testManifestCronJob = `---
+apiVersion: batch/v1beta1
+kind: CronJob
+metadata:
+  name: backup
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+          - image: kyml/backup
+            name: backup
+  schedule: '@daily'
+`
+
+func Test_deprecationsOptions_Validate(t *testing.T) {
+	type args struct {
+		args []string
+	}
+	tests := []struct {
+		name    string
+		o       *deprecationsOptions
+		args    args
+		wantErr bool
+	}{
+		{
+			name:    "error if any args",
+			o:       &deprecationsOptions{kubernetesVersion: "1.29"},
+			args:    args{args: []string{"foo"}},
+			wantErr: true,
+		},
+		{
+			name:    "error if version is invalid",
+			o:       &deprecationsOptions{kubernetesVersion: "1"},
+			args:    args{args: []string{}},
+			wantErr: true,
+		},
+		{
+			name:    "success",
+			o:       &deprecationsOptions{kubernetesVersion: "1.29"},
+			args:    args{args: []string{}},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.o.Validate(tt.args.args); (err != nil) != tt.wantErr {
+				t.Errorf("deprecationsOptions.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_deprecationsOptions_Run(t *testing.T) {
+	type args struct {
+		in io.Reader
+	}
+	tests := []struct {
+		name             string
+		o                *deprecationsOptions
+		args             args
+		wantOut          string
+		wantErrOut       string
+		wantErr          bool
+		wantErrToContain string
+	}{
+		{
+			name:       "supported",
+			o:          &deprecationsOptions{kubernetesVersion: "1.20"},
+			args:       args{strings.NewReader(testManifestCronJob)},
+			wantOut:    testManifestCronJob,
+			wantErrOut: "",
+			wantErr:    false,
+		},
+		{
+			name:       "deprecated",
+			o:          &deprecationsOptions{kubernetesVersion: "1.22"},
+			args:       args{strings.NewReader(testManifestCronJob)},
+			wantOut:    testManifestCronJob,
+			wantErrOut: "Warning: CronJob/backup: batch/v1beta1 CronJob is deprecated since Kubernetes 1.21 and will be removed in 1.25, use batch/v1 instead\n",
+			wantErr:    false,
+		},
+		{
+			name:             "deprecated and fail on deprecated",
+			o:                &deprecationsOptions{kubernetesVersion: "1.22", failOnDeprecated: true},
+			args:             args{strings.NewReader(testManifestCronJob)},
+			wantOut:          "",
+			wantErrOut:       "",
+			wantErr:          true,
+			wantErrToContain: "CronJob/backup: batch/v1beta1 CronJob is deprecated since Kubernetes 1.21",
+		},
+		{
+			name:             "removed",
+			o:                &deprecationsOptions{kubernetesVersion: "1.29"},
+			args:             args{strings.NewReader(testManifestCronJob)},
+			wantOut:          "",
+			wantErrOut:       "",
+			wantErr:          true,
+			wantErrToContain: "CronJob/backup: batch/v1beta1 CronJob is removed in Kubernetes 1.25, use batch/v1 instead",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			errOut := &bytes.Buffer{}
+			err := tt.o.Run(tt.args.in, out, errOut)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("deprecationsOptions.Run() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil && !strings.Contains(err.Error(), tt.wantErrToContain) {
+				t.Errorf("deprecationsOptions.Run() error = %v, wantErrToContain %v", err, tt.wantErrToContain)
+				return
+			}
+			if gotOut := out.String(); gotOut != tt.wantOut {
+				t.Errorf("deprecationsOptions.Run() = %v, want %v", gotOut, tt.wantOut)
+				return
+			}
+			if gotErrOut := errOut.String(); gotErrOut != tt.wantErrOut {
+				t.Errorf("deprecationsOptions.Run() errOut = %v, want %v", gotErrOut, tt.wantErrOut)
+			}
+		})
+	}
+}
@@ -0,0 +1,301 @@
+package diff
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/frigus02/kyml/pkg/cat"
+	"github.com/frigus02/kyml/pkg/diff"
+	"github.com/frigus02/kyml/pkg/exitcode"
+	"github.com/frigus02/kyml/pkg/fs"
+	"github.com/frigus02/kyml/pkg/k8syaml"
+	"github.com/spf13/cobra"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// newGitFilesystem creates the filesystems used with "--rev-a" and "--rev-b".
+var newGitFilesystem = fs.NewGitFilesystem
+
+// errDifferent is returned if the file sets differ. It's not printed.
+var errDifferent = &exitcode.Error{Code: 1, Err: errors.New("file sets differ")}
+
+type diffOptions struct {
+	color      string
+	context    int
+	filesA     []string
+	filesB     []string
+	ignoreFile string
+	nameA      string
+	nameB      string
+	output     string
+	quiet      bool
+	revA       string
+	revB       string
+	summary    bool
+	useColor   bool
+	width      int
+}
+
+// NewCmdDiff creates a new diff command.
+func NewCmdDiff(in io.Reader, out io.Writer, fs fs.Filesystem) *cobra.Command {
+	var o diffOptions
+
+	cmd := &cobra.Command{
+		Use:   "diff <file>... -- <file>...",
+		Short: "Show the differences between two sets of Kubernetes YAML files",
+		Long: `Show the differences between two sets of Kubernetes YAML files. The sets are separated by "--". Files in each set are concatenated using the same rules as in "kyml cat". Use "-" as the only file of a set to read it from stdin. Flags have to be specified before the files.
+
+Unlike "kyml test" the command doesn't use a snapshot. It's meant for ad-hoc comparisons, e.g. between two environments or between the rendered output of two branches.
+
+The differences are printed to stdout in the format specified by "--output":
+- unified: a unified diff of the YAML documents. Every hunk is annotated with the resource it belongs to.
+- side-by-side: the YAML documents next to each other. Changed lines are marked with "|", lines only in the first set with "<" and lines only in the second set with ">".
+- structural: every change on a separate line with the resource and field path, e.g. "Deployment.apps/app spec.replicas: 3 -> 1".
+
+Use "--rev-a" and "--rev-b" to read the files of a set at a git revision of the local repository instead of the working tree. This shows, for example, what a branch changes in the manifests compared to main. Quote glob patterns, so they are expanded at the revision and not by the shell.
+
+Use "--summary" to additionally print which resources were added, removed or changed. Fields, which are expected to differ, can be excluded using an ignore file in the same format as in "kyml test".
+
+The exit code is 0 if the sets don't differ, 1 if they differ and 2 if an error occurred. Use "--quiet" to only set the exit code.`,
+		Example: `  # Compare two environments
+  kyml diff production/* -- staging/*
+
+  # Compare the rendered output of two directories
+  kyml diff --output side-by-side main-output/* -- branch-output/*
+
+  # Show what the current branch changes in the production manifests
+  kyml diff --rev-a origin/main 'production/*' -- production/*
+
+  # Compare templated output on stdin with files
+  kyml cat production/* | kyml tmpl -e TAG | kyml diff - -- deployed/*
+
+  # Print which resources differ
+  kyml diff --output structural --summary production/* -- staging/*
+
+  # Use the exit code in a script
+  if ! kyml diff --quiet production/* -- staging/*; then
+    echo "environments differ"
+  fi`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := o.Validate(args, cmd.ArgsLenAtDash())
+			if err != nil {
+				return &exitcode.Error{Code: 2, Err: err}
+			}
+
+			o.useColor = o.color == "always" || (o.color == "auto" && diff.IsTerminal(os.Stdout))
+
+			err = o.Run(in, out, fs)
+			if err == errDifferent {
+				cmd.SilenceErrors = true
+				return err
+			} else if err != nil {
+				return &exitcode.Error{Code: 2, Err: err}
+			}
+
+			return nil
+		},
+	}
+
+	cmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return &exitcode.Error{Code: 2, Err: err}
+	})
+
+	cmd.Flags().StringVarP(&o.output, "output", "o", "unified", "Output format (unified, side-by-side or structural)")
+	cmd.Flags().StringVar(&o.nameA, "name-a", "a", "Name of the first file set")
+	cmd.Flags().StringVar(&o.nameB, "name-b", "b", "Name of the second file set")
+	cmd.Flags().StringVar(&o.revA, "rev-a", "", "Read the first file set at the specified git revision of the local repository")
+	cmd.Flags().StringVar(&o.revB, "rev-b", "", "Read the second file set at the specified git revision of the local repository")
+	cmd.Flags().StringVar(&o.ignoreFile, "ignore-file", "", "File with rules for fields, which should be ignored in the diff")
+	cmd.Flags().BoolVar(&o.summary, "summary", false, "Print a summary of added, removed and changed resources")
+	cmd.Flags().BoolVarP(&o.quiet, "quiet", "q", false, "Don't print anything and only set the exit code")
+	cmd.Flags().StringVar(&o.color, "color", "auto", "Color the diff (auto, always or never)")
+	cmd.Flags().IntVar(&o.context, "context", 3, "Number of context lines shown around changes")
+	cmd.Flags().IntVar(&o.width, "width", 160, "Maximum line width of side-by-side diffs")
+
+	_ = cmd.MarkFlagFilename("ignore-file")
+
+	// Diff supports infinite positional file arguments, however zsh
+	// completions require each positional argument to be marked individually.
+	// We just mark the first few.
+	_ = cmd.MarkZshCompPositionalArgumentFile(1)
+	_ = cmd.MarkZshCompPositionalArgumentFile(2)
+	_ = cmd.MarkZshCompPositionalArgumentFile(3)
+	_ = cmd.MarkZshCompPositionalArgumentFile(4)
+	_ = cmd.MarkZshCompPositionalArgumentFile(5)
+
+	return cmd
+}
+
+// Validate validates diff command. The files of the second set start at the
+// specified index of args.
+func (o *diffOptions) Validate(args []string, dashIndex int) error {
+	if dashIndex == -1 {
+		return fmt.Errorf("separate the two file sets with --")
+	}
+
+	o.filesA = args[:dashIndex]
+	o.filesB = args[dashIndex:]
+	if len(o.filesA) == 0 || len(o.filesB) == 0 {
+		return fmt.Errorf("specify at least one file for each file set")
+	}
+
+	stdin := 0
+	for _, files := range [][]string{o.filesA, o.filesB} {
+		for _, file := range files {
+			if file == "-" {
+				if len(files) != 1 {
+					return fmt.Errorf("- must be the only file of a file set")
+				}
+
+				stdin++
+			}
+		}
+	}
+
+	if stdin > 1 {
+		return fmt.Errorf("only one file set can be read from stdin")
+	}
+
+	switch o.output {
+	case "unified", "side-by-side", "structural":
+	default:
+		return fmt.Errorf("invalid output \"%s\" (supported are unified, side-by-side and structural)", o.output)
+	}
+
+	switch o.color {
+	case "", "auto", "always", "never":
+	default:
+		return fmt.Errorf("invalid color \"%s\" (supported are auto, always and never)", o.color)
+	}
+
+	if o.context < 0 {
+		return fmt.Errorf("context must not be negative")
+	}
+
+	if o.width != 0 && o.width < 5 {
+		return fmt.Errorf("width must be at least 5")
+	}
+
+	return nil
+}
+
+// Run runs diff command. It returns errDifferent if the file sets differ.
+func (o *diffOptions) Run(in io.Reader, out io.Writer, fs fs.Filesystem) error {
+	docsA, err := load(o.filesA, o.revA, in, fs)
+	if err != nil {
+		return err
+	}
+
+	docsB, err := load(o.filesB, o.revB, in, fs)
+	if err != nil {
+		return err
+	}
+
+	if o.ignoreFile != "" {
+		data, err := fs.ReadFile(o.ignoreFile)
+		if err != nil {
+			return fmt.Errorf("cannot open ignore file: %v", err)
+		}
+
+		rules, err := diff.ParseIgnoreRules(data)
+		if err != nil {
+			return fmt.Errorf("error parsing ignore file: %v", err)
+		}
+
+		docsA = diff.Ignore(docsA, rules)
+		docsB = diff.Ignore(docsB, rules)
+	}
+
+	output, err := o.diff(docsA, docsB)
+	if err != nil {
+		return err
+	}
+
+	summary := diff.Summarize(docsA, docsB)
+	if !o.quiet {
+		if _, err := io.WriteString(out, output); err != nil {
+			return err
+		}
+
+		if o.summary {
+			if output != "" {
+				fmt.Fprintln(out)
+			}
+
+			if _, err := io.WriteString(out, summary.String()); err != nil {
+				return err
+			}
+		}
+	}
+
+	if output != "" || summary.Differs() {
+		return errDifferent
+	}
+
+	return nil
+}
+
+func (o *diffOptions) diff(docsA, docsB []*unstructured.Unstructured) (string, error) {
+	opts := diff.RenderOptions{
+		Context: o.context,
+		Color:   o.useColor,
+		Width:   o.width,
+	}
+
+	if o.output == "structural" {
+		return diff.RenderStructural(o.nameA, docsA, o.nameB, docsB, opts), nil
+	}
+
+	var bufferA bytes.Buffer
+	if err := k8syaml.Encode(&bufferA, docsA); err != nil {
+		return "", err
+	}
+
+	var bufferB bytes.Buffer
+	if err := k8syaml.Encode(&bufferB, docsB); err != nil {
+		return "", err
+	}
+
+	resources, err := k8syaml.LineResources(docsB)
+	if err != nil {
+		return "", err
+	}
+
+	opts.Header = func(line int) string {
+		if line < len(resources) {
+			return resources[line]
+		}
+
+		return ""
+	}
+
+	if o.output == "side-by-side" {
+		return diff.SideBySide(o.nameA, bufferA.String(), o.nameB, bufferB.String(), opts), nil
+	}
+
+	return diff.Render(o.nameA, bufferA.String(), o.nameB, bufferB.String(), opts), nil
+}
+
+// load concatenates the files of a set, optionally at a git revision.
+func load(files []string, rev string, in io.Reader, fs fs.Filesystem) ([]*unstructured.Unstructured, error) {
+	if len(files) == 1 && files[0] == "-" {
+		return cat.StreamDecodeOnly(in)
+	}
+
+	if rev != "" {
+		var err error
+		if fs, err = newGitFilesystem("", rev); err != nil {
+			return nil, err
+		}
+
+		if files, err = cat.ExpandGlobs(files, fs); err != nil {
+			return nil, err
+		}
+	}
+
+	return cat.CatDecodeOnly(files, fs)
+}
@@ -0,0 +1,256 @@
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/frigus02/kyml/pkg/fs"
+)
+
+var testDeploymentA = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  replicas: 3
+`
+
+var testDeploymentB = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  replicas: 1
+`
+
+var testService = `apiVersion: v1
+kind: Service
+metadata:
+  name: app
+`
+
+func mustCreateFs(t *testing.T) fs.Filesystem {
+	fs := fs.NewFakeFilesystem()
+	for name, data := range map[string]string{
+		"a/deployment.yaml": testDeploymentA,
+		"a/service.yaml":    testService,
+		"b/deployment.yaml": testDeploymentB,
+		"ignore.yaml":       "ignore:\n- match:\n    kind: Deployment\n  paths: [spec.replicas]\n",
+	} {
+		if err := fs.WriteFile(name, []byte(data), 0644); err != nil {
+			t.Fatalf("error writing %s: %v", name, err)
+		}
+	}
+
+	return fs
+}
+
+func Test_diffOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		o         *diffOptions
+		args      []string
+		dashIndex int
+		wantErr   bool
+	}{
+		{
+			name:      "valid",
+			o:         &diffOptions{output: "unified"},
+			args:      []string{"a.yaml", "b.yaml"},
+			dashIndex: 1,
+			wantErr:   false,
+		},
+		{
+			name:      "no separator",
+			o:         &diffOptions{output: "unified"},
+			args:      []string{"a.yaml", "b.yaml"},
+			dashIndex: -1,
+			wantErr:   true,
+		},
+		{
+			name:      "empty file set",
+			o:         &diffOptions{output: "unified"},
+			args:      []string{"a.yaml"},
+			dashIndex: 1,
+			wantErr:   true,
+		},
+		{
+			name:      "stdin",
+			o:         &diffOptions{output: "unified"},
+			args:      []string{"-", "b.yaml"},
+			dashIndex: 1,
+			wantErr:   false,
+		},
+		{
+			name:      "stdin with other files",
+			o:         &diffOptions{output: "unified"},
+			args:      []string{"-", "a.yaml", "b.yaml"},
+			dashIndex: 2,
+			wantErr:   true,
+		},
+		{
+			name:      "stdin twice",
+			o:         &diffOptions{output: "unified"},
+			args:      []string{"-", "-"},
+			dashIndex: 1,
+			wantErr:   true,
+		},
+		{
+			name:      "invalid output",
+			o:         &diffOptions{output: "html"},
+			args:      []string{"a.yaml", "b.yaml"},
+			dashIndex: 1,
+			wantErr:   true,
+		},
+		{
+			name:      "invalid color",
+			o:         &diffOptions{output: "unified", color: "sometimes"},
+			args:      []string{"a.yaml", "b.yaml"},
+			dashIndex: 1,
+			wantErr:   true,
+		},
+		{
+			name:      "width 1",
+			o:         &diffOptions{output: "side-by-side", width: 1},
+			args:      []string{"a.yaml", "b.yaml"},
+			dashIndex: 1,
+			wantErr:   true,
+		},
+		{
+			name:      "width 2",
+			o:         &diffOptions{output: "side-by-side", width: 2},
+			args:      []string{"a.yaml", "b.yaml"},
+			dashIndex: 1,
+			wantErr:   true,
+		},
+		{
+			name:      "width 5",
+			o:         &diffOptions{output: "side-by-side", width: 5},
+			args:      []string{"a.yaml", "b.yaml"},
+			dashIndex: 1,
+			wantErr:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.o.Validate(tt.args, tt.dashIndex); (err != nil) != tt.wantErr {
+				t.Errorf("diffOptions.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_diffOptions_Run(t *testing.T) {
+	tests := []struct {
+		name             string
+		o                *diffOptions
+		in               io.Reader
+		wantOut          string
+		wantErr          error
+		wantErrToContain string
+	}{
+		{
+			name: "unified",
+			o:    &diffOptions{output: "unified", filesA: []string{"a/deployment.yaml", "a/service.yaml"}, filesB: []string{"b/deployment.yaml"}, nameA: "a", nameB: "b", context: 1},
+			wantOut: "--- a\n+++ b\n" +
+				"@@ -1,6 +1 @@ Deployment/app\n----\n-apiVersion: v1\n-kind: Service\n-metadata:\n-  name: app\n ---\n" +
+				"@@ -11,2 +6,2 @@ Deployment/app\n spec:\n-  replicas: 3\n+  replicas: 1\n",
+			wantErr: errDifferent,
+		},
+		{
+			name: "structural with summary",
+			o:    &diffOptions{output: "structural", filesA: []string{"a/deployment.yaml", "a/service.yaml"}, filesB: []string{"b/deployment.yaml"}, nameA: "a", nameB: "b", summary: true},
+			wantOut: "--- a\n+++ b\nService/app: removed\nDeployment.apps/app spec.replicas: 3 -> 1\n\n" +
+				"0 added, 1 removed, 1 changed, 0 unchanged\n  removed  Service/app\n  changed  Deployment.apps/app\n",
+			wantErr: errDifferent,
+		},
+		{
+			name: "side by side",
+			o:    &diffOptions{output: "side-by-side", filesA: []string{"a/deployment.yaml"}, filesB: []string{"b/deployment.yaml"}, nameA: "a", nameB: "b", width: 33},
+			wantOut: "--- a             +++ b\n" +
+				"@@ -7 +7 @@ Deployment/app\n" +
+				"  replicas: 3   |   replicas: 1\n",
+			wantErr: errDifferent,
+		},
+		{
+			name:    "stdin",
+			o:       &diffOptions{output: "structural", filesA: []string{"-"}, filesB: []string{"b/deployment.yaml"}, nameA: "a", nameB: "b"},
+			in:      strings.NewReader(testDeploymentA),
+			wantOut: "--- a\n+++ b\nDeployment.apps/app spec.replicas: 3 -> 1\n",
+			wantErr: errDifferent,
+		},
+		{
+			name:    "quiet",
+			o:       &diffOptions{output: "unified", filesA: []string{"a/deployment.yaml"}, filesB: []string{"b/deployment.yaml"}, nameA: "a", nameB: "b", quiet: true, summary: true},
+			wantOut: "",
+			wantErr: errDifferent,
+		},
+		{
+			name:    "no differences",
+			o:       &diffOptions{output: "unified", filesA: []string{"a/deployment.yaml"}, filesB: []string{"a/deployment.yaml"}, nameA: "a", nameB: "b", summary: true},
+			wantOut: "0 added, 0 removed, 0 changed, 1 unchanged\n",
+			wantErr: nil,
+		},
+		{
+			name:    "ignore file",
+			o:       &diffOptions{output: "unified", filesA: []string{"a/deployment.yaml"}, filesB: []string{"b/deployment.yaml"}, nameA: "a", nameB: "b", ignoreFile: "ignore.yaml"},
+			wantOut: "",
+			wantErr: nil,
+		},
+		{
+			name:             "file doesn't exist",
+			o:                &diffOptions{output: "unified", filesA: []string{"c.yaml"}, filesB: []string{"b/deployment.yaml"}},
+			wantErrToContain: "file does not exist",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			err := tt.o.Run(tt.in, out, mustCreateFs(t))
+			if tt.wantErrToContain != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErrToContain) {
+					t.Errorf("diffOptions.Run() error = %v, wantErrToContain %v", err, tt.wantErrToContain)
+				}
+				return
+			}
+			if err != tt.wantErr {
+				t.Errorf("diffOptions.Run() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotOut := out.String(); gotOut != tt.wantOut {
+				t.Errorf("diffOptions.Run() = %q, want %q", gotOut, tt.wantOut)
+			}
+		})
+	}
+}
+
+func Test_diffOptions_Run_rev(t *testing.T) {
+	originalNewGitFilesystem := newGitFilesystem
+	defer func() { newGitFilesystem = originalNewGitFilesystem }()
+	newGitFilesystem = func(dir, rev string) (fs.Filesystem, error) {
+		if rev != "main" {
+			return nil, fmt.Errorf("unknown git revision %s", rev)
+		}
+
+		revFs := fs.NewFakeFilesystem()
+		err := revFs.WriteFile("a/deployment.yaml", []byte(testDeploymentB), 0644)
+		return revFs, err
+	}
+
+	o := &diffOptions{output: "structural", filesA: []string{"a/*.yaml"}, filesB: []string{"a/deployment.yaml"}, nameA: "main", nameB: "working tree", revA: "main"}
+	out := &bytes.Buffer{}
+	if err := o.Run(nil, out, mustCreateFs(t)); err != errDifferent {
+		t.Errorf("diffOptions.Run() error = %v, wantErr %v", err, errDifferent)
+	}
+	if want := "--- main\n+++ working tree\nDeployment.apps/app spec.replicas: 1 -> 3\n"; out.String() != want {
+		t.Errorf("diffOptions.Run() = %q, want %q", out.String(), want)
+	}
+
+	o.revA = "feature"
+	if err := o.Run(nil, &bytes.Buffer{}, mustCreateFs(t)); err == nil || !strings.Contains(err.Error(), "unknown git revision feature") {
+		t.Errorf("diffOptions.Run() error = %v, want unknown git revision", err)
+	}
+}
@@ -0,0 +1,135 @@
+package generate
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/frigus02/kyml/pkg/cat"
+	"github.com/frigus02/kyml/pkg/fs"
+	"github.com/frigus02/kyml/pkg/generate"
+	"github.com/frigus02/kyml/pkg/k8syaml"
+	"github.com/spf13/cobra"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+type generateOptions struct {
+	kind       string
+	name       string
+	namespace  string
+	files      []string
+	literals   []string
+	envFiles   []string
+	secretType string
+	append     bool
+}
+
+// NewCmdGenerate creates a new generate command.
+func NewCmdGenerate(in io.Reader, out io.Writer, fs fs.Filesystem) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate ConfigMaps and Secrets from files",
+		Long: `Generate ConfigMaps and Secrets from files, directories, literal values and env files and print them to stdout.
+
+Use "--append" to read Kubernetes YAML documents from stdin and add the generated document to them. The result is deduplicated and sorted in the same way as "kyml cat" does, so the command can be used as a stage in a deployment pipeline.`,
+	}
+
+	cmd.AddCommand(
+		newCmdGenerateResource("configmap", "ConfigMap", in, out, fs),
+		newCmdGenerateResource("secret", "Secret", in, out, fs),
+	)
+
+	return cmd
+}
+
+func newCmdGenerateResource(use, kind string, in io.Reader, out io.Writer, fs fs.Filesystem) *cobra.Command {
+	o := generateOptions{kind: kind}
+
+	cmd := &cobra.Command{
+		Use:   use + " <name>",
+		Short: "Generate a " + kind,
+		Long: `Generate a ` + kind + ` with the specified name and print it to stdout.
+
+Data is added from:
+- Files ("--from-file path" or "--from-file key=path"). By default the file name is used as key. If the path is a directory, every file in it is added. Subdirectories are ignored.
+- Literal values ("--from-literal key=value").
+- Env files ("--from-env-file path") with lines in the form KEY=VALUE. Empty lines and lines starting with # are ignored.
+
+All options can be repeated. Every key can only be specified once.`,
+		Example: `  # Generate a ` + kind + ` from a directory and deploy it together with other manifests
+  kyml cat production/* |
+    kyml generate ` + use + ` app-config --from-file config/ --from-literal env=production --append |
+    kubectl apply -f -
+
+  # Generate a ` + kind + ` into a file, which is read by "kyml cat"
+  kyml generate ` + use + ` app-config --from-env-file production.env > production/app-config.yaml`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := o.Validate(args)
+			if err != nil {
+				return err
+			}
+
+			return o.Run(in, out, fs)
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.namespace, "namespace", "n", "", "Namespace of the generated "+kind)
+	cmd.Flags().StringArrayVar(&o.files, "from-file", nil, "Add a file or all files in a directory (path or key=path)")
+	cmd.Flags().StringArrayVar(&o.literals, "from-literal", nil, "Add a literal value (key=value)")
+	cmd.Flags().StringArrayVar(&o.envFiles, "from-env-file", nil, "Add all values of an env file with lines in the form KEY=VALUE")
+	cmd.Flags().BoolVarP(&o.append, "append", "a", false, "Read documents from stdin and add the generated "+kind+" to them")
+	if kind == "Secret" {
+		cmd.Flags().StringVar(&o.secretType, "type", "Opaque", "Type of the generated Secret")
+	}
+
+	return cmd
+}
+
+// Validate validates generate command.
+func (o *generateOptions) Validate(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("specify exactly one name")
+	}
+
+	o.name = args[0]
+	return nil
+}
+
+// Run runs generate command.
+func (o *generateOptions) Run(in io.Reader, out io.Writer, fs fs.Filesystem) error {
+	data := make(generate.Data)
+	for _, file := range o.files {
+		if err := data.AddFile(file, fs); err != nil {
+			return err
+		}
+	}
+	for _, literal := range o.literals {
+		if err := data.AddLiteral(literal); err != nil {
+			return err
+		}
+	}
+	for _, envFile := range o.envFiles {
+		if err := data.AddEnvFile(envFile, fs); err != nil {
+			return err
+		}
+	}
+
+	var doc *unstructured.Unstructured
+	if o.kind == "Secret" {
+		doc = generate.Secret(o.name, o.namespace, o.secretType, data)
+	} else {
+		doc = generate.ConfigMap(o.name, o.namespace, data)
+	}
+
+	documents := []*unstructured.Unstructured{doc}
+	if o.append {
+		stream, err := cat.StreamDecodeOnly(in)
+		if err != nil {
+			return err
+		}
+
+		documents = cat.Normalize(append(stream, doc))
+	}
+
+	return k8syaml.Encode(out, documents)
+}
@@ -0,0 +1,147 @@
+package generate
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/frigus02/kyml/pkg/fs"
+)
+
+func Test_generateOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantName string
+		wantErr  bool
+	}{
+		{name: "name", args: []string{"app-config"}, wantName: "app-config"},
+		{name: "error without name", args: []string{}, wantErr: true},
+		{name: "error with multiple names", args: []string{"a", "b"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &generateOptions{kind: "ConfigMap"}
+			if err := o.Validate(tt.args); (err != nil) != tt.wantErr {
+				t.Errorf("generateOptions.Validate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if o.name != tt.wantName {
+				t.Errorf("generateOptions.Validate() name = %v, want %v", o.name, tt.wantName)
+			}
+		})
+	}
+}
+
+func Test_generateOptions_Run(t *testing.T) {
+	fs := fs.NewFakeFilesystem()
+	_ = fs.WriteFile("config/app.properties", []byte("debug=true\n"), 0644)
+	_ = fs.WriteFile("production.env", []byte("LOG_LEVEL=info\n"), 0644)
+
+	tests := []struct {
+		name    string
+		o       *generateOptions
+		in      io.Reader
+		wantOut string
+		wantErr bool
+	}{
+		{
+			name: "configmap",
+			o: &generateOptions{
+				kind:      "ConfigMap",
+				name:      "app-config",
+				namespace: "production",
+				files:     []string{"config"},
+				literals:  []string{"env=production"},
+				envFiles:  []string{"production.env"},
+			},
+			wantOut: `---
+apiVersion: v1
+data:
+  LOG_LEVEL: info
+  app.properties: |
+    debug=true
+  env: production
+kind: ConfigMap
+metadata:
+  name: app-config
+  namespace: production
+`,
+		},
+		{
+			name: "secret",
+			o: &generateOptions{
+				kind:       "Secret",
+				name:       "app-secret",
+				literals:   []string{"password=hunter2"},
+				secretType: "Opaque",
+			},
+			wantOut: `---
+apiVersion: v1
+data:
+  password: aHVudGVyMg==
+kind: Secret
+metadata:
+  name: app-secret
+type: Opaque
+`,
+		},
+		{
+			name: "append to stream",
+			o: &generateOptions{
+				kind:     "ConfigMap",
+				name:     "app-config",
+				literals: []string{"env=production"},
+				append:   true,
+			},
+			in: strings.NewReader(`---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app-config
+data:
+  env: staging
+`),
+			wantOut: `---
+apiVersion: v1
+data:
+  env: production
+kind: ConfigMap
+metadata:
+  name: app-config
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+`,
+		},
+		{
+			name: "file doesn't exist",
+			o: &generateOptions{
+				kind:  "ConfigMap",
+				name:  "app-config",
+				files: []string{"missing"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			if err := tt.o.Run(tt.in, out, fs); (err != nil) != tt.wantErr {
+				t.Errorf("generateOptions.Run() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotOut := out.String(); gotOut != tt.wantOut {
+				t.Errorf("generateOptions.Run() = %v, want %v", gotOut, tt.wantOut)
+			}
+		})
+	}
+}
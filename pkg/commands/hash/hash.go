@@ -0,0 +1,79 @@
+package hash
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/frigus02/kyml/pkg/cat"
+	"github.com/frigus02/kyml/pkg/confighash"
+	"github.com/frigus02/kyml/pkg/k8syaml"
+	"github.com/spf13/cobra"
+)
+
+type hashOptions struct {
+	mode string
+}
+
+// NewCmdHash creates a new hash command.
+func NewCmdHash(in io.Reader, out io.Writer) *cobra.Command {
+	var o hashOptions
+
+	cmd := &cobra.Command{
+		Use:   "hash",
+		Short: "Roll workloads when referenced ConfigMaps or Secrets change",
+		Long: `Make sure pods restart when the content of a referenced ConfigMap or Secret changes. Data is read from stdin and printed to stdout.
+
+In mode "suffix" the command appends a hash of the content to the names of ConfigMaps and Secrets and updates all references to them in the same namespace: volumes, projected and CSI volumes, envFrom, valueFrom and imagePullSecrets in pods and workloads, tls in Ingresses and secrets and imagePullSecrets in ServiceAccounts. ConfigMaps and Secrets without any of these references keep their names. Since old ConfigMaps and Secrets stay in the cluster until they are deleted, rollbacks can use them.
+
+In mode "annotation" the names stay the same. Instead the command writes a checksum of all ConfigMaps and Secrets referenced by a workload into the annotation "kyml.io/config-checksum" of its pod template.
+
+Only ConfigMaps and Secrets in the input are considered. Exclude one by setting the annotation "kyml.io/content-hash" to "false". Run the command only once on a set of documents, because every run appends another hash in mode "suffix".`,
+		Example: `  # Append content hashes before deploying to cluster
+  kyml cat production/* | kyml hash | kubectl apply -f -
+
+  # Keep names and roll workloads using an annotation
+  kyml cat production/* | kyml hash --mode annotation | kubectl apply -f -`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := o.Validate(args)
+			if err != nil {
+				return err
+			}
+
+			return o.Run(in, out)
+		},
+	}
+
+	cmd.Flags().StringVar(&o.mode, "mode", "suffix", "How to roll workloads (suffix or annotation)")
+
+	return cmd
+}
+
+// Validate validates hash command.
+func (o *hashOptions) Validate(args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("this command takes no positional arguments")
+	}
+
+	if o.mode != "suffix" && o.mode != "annotation" {
+		return fmt.Errorf("mode must be suffix or annotation")
+	}
+
+	return nil
+}
+
+// Run runs hash command.
+func (o *hashOptions) Run(in io.Reader, out io.Writer) error {
+	documents, err := cat.StreamDecodeOnly(in)
+	if err != nil {
+		return err
+	}
+
+	if o.mode == "annotation" {
+		confighash.WriteChecksumAnnotation(documents)
+	} else {
+		confighash.AppendNameSuffix(documents)
+		documents = cat.Normalize(documents)
+	}
+
+	return k8syaml.Encode(out, documents)
+}
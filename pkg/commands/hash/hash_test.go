@@ -0,0 +1,124 @@
+package hash
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+var testManifests = `---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        envFrom:
+        - configMapRef:
+            name: config
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  a: "1"
+`
+
+func Test_hashOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		o       *hashOptions
+		args    []string
+		wantErr bool
+	}{
+		{name: "suffix", o: &hashOptions{mode: "suffix"}, args: []string{}, wantErr: false},
+		{name: "annotation", o: &hashOptions{mode: "annotation"}, args: []string{}, wantErr: false},
+		{name: "error if any args", o: &hashOptions{mode: "suffix"}, args: []string{"foo"}, wantErr: true},
+		{name: "error if mode is invalid", o: &hashOptions{mode: "label"}, args: []string{}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.o.Validate(tt.args); (err != nil) != tt.wantErr {
+				t.Errorf("hashOptions.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_hashOptions_Run(t *testing.T) {
+	tests := []struct {
+		name    string
+		o       *hashOptions
+		wantOut string
+	}{
+		{
+			name: "suffix",
+			o:    &hashOptions{mode: "suffix"},
+			wantOut: `---
+apiVersion: v1
+data:
+  a: "1"
+kind: ConfigMap
+metadata:
+  name: config-627100a68e
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      containers:
+      - envFrom:
+        - configMapRef:
+            name: config-627100a68e
+        name: app
+`,
+		},
+		{
+			name: "annotation",
+			o:    &hashOptions{mode: "annotation"},
+			wantOut: `---
+apiVersion: v1
+data:
+  a: "1"
+kind: ConfigMap
+metadata:
+  name: config
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    metadata:
+      annotations:
+        kyml.io/config-checksum: 7841a41d9b07ab5941f7fd0c8dc27a8be2bdab71cb07de87d2b421ace1a9cd59
+    spec:
+      containers:
+      - envFrom:
+        - configMapRef:
+            name: config
+        name: app
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			if err := tt.o.Run(strings.NewReader(testManifests), out); err != nil {
+				t.Errorf("hashOptions.Run() error = %v", err)
+				return
+			}
+			if gotOut := out.String(); gotOut != tt.wantOut {
+				t.Errorf("hashOptions.Run() = %v, want %v", gotOut, tt.wantOut)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package lint
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/frigus02/kyml/pkg/cat"
+	"github.com/frigus02/kyml/pkg/fs"
+	"github.com/frigus02/kyml/pkg/lint"
+	"github.com/spf13/cobra"
+)
+
+type lintOptions struct {
+	output        string
+	failOn        string
+	disabledRules []string
+	policyFiles   []string
+	listRules     bool
+}
+
+// NewCmdLint creates a new lint command.
+func NewCmdLint(in io.Reader, out io.Writer, fs fs.Filesystem, version string) *cobra.Command {
+	var o lintOptions
+
+	cmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Check Kubernetes YAML files for common mistakes and best practices",
+		Long: `Check Kubernetes YAML documents using a set of best-practice rules. Data is read from stdin. The findings are printed to stdout in the specified format (text, json or sarif).
+
+Every rule has an ID and a severity (info, warning or error). The command exits with a non-zero exit code if any finding has the severity specified by "--fail-on" or higher. Use "--list-rules" to see all rules.
+
+Company-specific rules can be loaded from policy files using "--policy". Every rule in a policy file has a CEL expression, which is evaluated against each matching document, available as the variable "object". The rule reports a finding if the expression evaluates to false.
+
+Rules can be disabled for all documents using "--disable". To disable rules for a single document, list their IDs separated by commas in the annotation "` + lint.IgnoreAnnotation + `".`,
+		Example: `  # Lint manifests before deploying them
+  kyml cat production/* | kyml lint
+
+  # Create a SARIF report for code scanning tools
+  kyml cat production/* | kyml lint --output sarif > kyml.sarif
+
+  # Lint using additional rules from a policy file
+  kyml cat production/* | kyml lint --policy policy.yaml
+
+  # Example policy file
+  rules:
+  - id: cost-center-label
+    severity: error
+    match:
+      resources:
+      - apiVersion: apps/v1
+        kind: Deployment
+      namespaces: [payments]
+      labels:
+        tier: backend
+    expression: has(object.metadata.labels) && 'cost-center' in object.metadata.labels
+    message: Deployments in payments must have a cost-center label
+
+  # Ignore a rule for a single document
+  metadata:
+    annotations:
+      ` + lint.IgnoreAnnotation + `: missing-probes,image-digest`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := o.Validate(args)
+			if err != nil {
+				return err
+			}
+
+			return o.Run(in, out, fs, version)
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.output, "output", "o", "text", "Output format (text, json or sarif)")
+	cmd.Flags().StringVar(&o.failOn, "fail-on", "error", "Minimum severity of findings, which make the command fail (info, warning or error)")
+	cmd.Flags().StringArrayVar(&o.disabledRules, "disable", nil, "Disable the rule with the specified ID")
+	cmd.Flags().StringArrayVar(&o.policyFiles, "policy", nil, "Load additional rules from the specified policy file")
+	cmd.Flags().BoolVar(&o.listRules, "list-rules", false, "List all rules and exit")
+
+	return cmd
+}
+
+// Validate validates lint command.
+func (o *lintOptions) Validate(args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("this command takes no positional arguments")
+	}
+
+	switch o.output {
+	case "text", "json", "sarif":
+	default:
+		return fmt.Errorf("invalid output format \"%s\" (supported are text, json and sarif)", o.output)
+	}
+
+	if _, err := lint.ParseSeverity(o.failOn); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Run runs lint command.
+func (o *lintOptions) Run(in io.Reader, out io.Writer, fs fs.Filesystem, version string) error {
+	rules, err := o.rules(fs)
+	if err != nil {
+		return err
+	}
+
+	if o.listRules {
+		w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		for _, rule := range rules {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", rule.ID, rule.Severity, rule.Description)
+		}
+		return w.Flush()
+	}
+
+	failOn, err := lint.ParseSeverity(o.failOn)
+	if err != nil {
+		return err
+	}
+
+	documents, err := cat.StreamDecodeOnly(in)
+	if err != nil {
+		return err
+	}
+
+	findings := lint.Lint(documents, rules)
+
+	switch o.output {
+	case "json":
+		err = lint.WriteJSON(out, findings)
+	case "sarif":
+		err = lint.WriteSARIF(out, findings, rules, version)
+	default:
+		err = lint.WriteText(out, findings)
+	}
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, finding := range findings {
+		if finding.Rule.Severity >= failOn {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("found %d problems with severity %s or higher", failed, failOn)
+	}
+
+	return nil
+}
+
+func (o *lintOptions) rules(fs fs.Filesystem) ([]*lint.Rule, error) {
+	allRules := lint.BuiltinRules()
+	for _, policyFile := range o.policyFiles {
+		data, err := fs.ReadFile(policyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		policyRules, err := lint.ParsePolicy(data)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing policy file %s: %v", policyFile, err)
+		}
+
+		for _, policyRule := range policyRules {
+			for _, rule := range allRules {
+				if rule.ID == policyRule.ID {
+					return nil, fmt.Errorf("policy file %s: rule \"%s\" is already defined", policyFile, policyRule.ID)
+				}
+			}
+
+			allRules = append(allRules, policyRule)
+		}
+	}
+
+	disabled := make(map[string]bool)
+	for _, id := range o.disabledRules {
+		found := false
+		for _, rule := range allRules {
+			if rule.ID == id {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("cannot disable unknown rule \"%s\"", id)
+		}
+
+		disabled[id] = true
+	}
+
+	var rules []*lint.Rule
+	for _, rule := range allRules {
+		if !disabled[rule.ID] {
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules, nil
+}
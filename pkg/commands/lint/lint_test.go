@@ -0,0 +1,202 @@
+package lint
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/frigus02/kyml/pkg/fs"
+)
+
+var testManifestPod = `---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: the-pod
+spec:
+  hostNetwork: true
+  securityContext:
+    runAsNonRoot: true
+  containers:
+  - name: app
+    image: kyml/hello@sha256:2cbb95c7479634c53bc2be243554a98d6928c189360fa958d2c970974e7f131f
+    resources:
+      requests:
+        cpu: 100m
+      limits:
+        memory: 128Mi
+`
+
+var testManifestPodWithoutResources = `---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: the-pod
+spec:
+  securityContext:
+    runAsNonRoot: true
+  containers:
+  - name: app
+    image: kyml/hello@sha256:2cbb95c7479634c53bc2be243554a98d6928c189360fa958d2c970974e7f131f
+`
+
+var testPolicy = `rules:
+- id: team-label
+  severity: warning
+  expression: has(object.metadata.labels) && 'team' in object.metadata.labels
+  message: resource has no team label
+`
+
+var testPolicyDuplicateRule = `rules:
+- id: host-network
+  expression: 'true'
+`
+
+func Test_lintOptions_Validate(t *testing.T) {
+	type args struct {
+		args []string
+	}
+	tests := []struct {
+		name    string
+		o       *lintOptions
+		args    args
+		wantErr bool
+	}{
+		{
+			name:    "error if any args",
+			o:       &lintOptions{output: "text", failOn: "error"},
+			args:    args{args: []string{"foo"}},
+			wantErr: true,
+		},
+		{
+			name:    "error if output is invalid",
+			o:       &lintOptions{output: "xml", failOn: "error"},
+			args:    args{args: []string{}},
+			wantErr: true,
+		},
+		{
+			name:    "error if fail-on is invalid",
+			o:       &lintOptions{output: "text", failOn: "fatal"},
+			args:    args{args: []string{}},
+			wantErr: true,
+		},
+		{
+			name:    "success",
+			o:       &lintOptions{output: "sarif", failOn: "warning"},
+			args:    args{args: []string{}},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.o.Validate(tt.args.args); (err != nil) != tt.wantErr {
+				t.Errorf("lintOptions.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_lintOptions_Run(t *testing.T) {
+	type args struct {
+		in io.Reader
+	}
+	tests := []struct {
+		name    string
+		o       *lintOptions
+		args    args
+		files   map[string]string
+		wantOut string
+		wantErr bool
+	}{
+		{
+			name:    "error finding fails",
+			o:       &lintOptions{output: "text", failOn: "error"},
+			args:    args{strings.NewReader(testManifestPod)},
+			wantOut: "Pod/the-pod: spec.hostNetwork: pod uses the host network (error: host-network)\n",
+			wantErr: true,
+		},
+		{
+			name:    "disabled rule",
+			o:       &lintOptions{output: "text", failOn: "error", disabledRules: []string{"host-network"}},
+			args:    args{strings.NewReader(testManifestPod)},
+			wantOut: "",
+			wantErr: false,
+		},
+		{
+			name:    "error if disabled rule is unknown",
+			o:       &lintOptions{output: "text", failOn: "error", disabledRules: []string{"foo"}},
+			args:    args{strings.NewReader(testManifestPod)},
+			wantOut: "",
+			wantErr: true,
+		},
+		{
+			name: "warning finding succeeds by default",
+			o:    &lintOptions{output: "text", failOn: "error"},
+			args: args{strings.NewReader(testManifestPodWithoutResources)},
+			wantOut: `Pod/the-pod: spec.containers[0].resources: container "app" has no resource requests (warning: missing-resources)
+Pod/the-pod: spec.containers[0].resources: container "app" has no resource limits (warning: missing-resources)
+`,
+			wantErr: false,
+		},
+		{
+			name: "warning finding fails with fail-on warning",
+			o:    &lintOptions{output: "text", failOn: "warning"},
+			args: args{strings.NewReader(testManifestPodWithoutResources)},
+			wantOut: `Pod/the-pod: spec.containers[0].resources: container "app" has no resource requests (warning: missing-resources)
+Pod/the-pod: spec.containers[0].resources: container "app" has no resource limits (warning: missing-resources)
+`,
+			wantErr: true,
+		},
+		{
+			name:    "policy rule",
+			o:       &lintOptions{output: "text", failOn: "warning", disabledRules: []string{"host-network"}, policyFiles: []string{"policy.yaml"}},
+			args:    args{strings.NewReader(testManifestPod)},
+			files:   map[string]string{"policy.yaml": testPolicy},
+			wantOut: "Pod/the-pod: resource has no team label (warning: team-label)\n",
+			wantErr: true,
+		},
+		{
+			name:    "disabled policy rule",
+			o:       &lintOptions{output: "text", failOn: "warning", disabledRules: []string{"host-network", "team-label"}, policyFiles: []string{"policy.yaml"}},
+			args:    args{strings.NewReader(testManifestPod)},
+			files:   map[string]string{"policy.yaml": testPolicy},
+			wantOut: "",
+			wantErr: false,
+		},
+		{
+			name:    "error if policy file does not exist",
+			o:       &lintOptions{output: "text", failOn: "error", policyFiles: []string{"policy.yaml"}},
+			args:    args{strings.NewReader(testManifestPod)},
+			wantOut: "",
+			wantErr: true,
+		},
+		{
+			name:    "error if policy rule duplicates a rule",
+			o:       &lintOptions{output: "text", failOn: "error", policyFiles: []string{"policy.yaml"}},
+			args:    args{strings.NewReader(testManifestPod)},
+			files:   map[string]string{"policy.yaml": testPolicyDuplicateRule},
+			wantOut: "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := fs.NewFakeFilesystem()
+			for name, data := range tt.files {
+				if err := fs.WriteFile(name, []byte(data), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			out := &bytes.Buffer{}
+			if err := tt.o.Run(tt.args.in, out, fs, "dev"); (err != nil) != tt.wantErr {
+				t.Errorf("lintOptions.Run() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotOut := out.String(); gotOut != tt.wantOut {
+				t.Errorf("lintOptions.Run() = %v, want %v", gotOut, tt.wantOut)
+			}
+		})
+	}
+}
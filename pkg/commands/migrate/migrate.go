@@ -0,0 +1,91 @@
+package migrate
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/frigus02/kyml/pkg/cat"
+	"github.com/frigus02/kyml/pkg/k8syaml"
+	"github.com/frigus02/kyml/pkg/kubeversion"
+	"github.com/frigus02/kyml/pkg/migrate"
+	"github.com/frigus02/kyml/pkg/validate"
+	"github.com/spf13/cobra"
+)
+
+type migrateOptions struct {
+	to                string
+	includeDeprecated bool
+}
+
+// NewCmdMigrate creates a new migrate command.
+func NewCmdMigrate(in io.Reader, out, errOut io.Writer) *cobra.Command {
+	var o migrateOptions
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Migrate Kubernetes YAML files from removed API versions to their successors",
+		Long: `Rewrite Kubernetes YAML documents, which use an API version that is removed in the specified Kubernetes version, to the API version replacing it. Data is read from stdin and printed to stdout.
+
+Fields are converted where the API versions differ, e.g. Ingress backends are restructured when migrating from extensions/v1beta1 to networking.k8s.io/v1. Anything, which can't be converted automatically, is reported as a warning on stderr and should be reviewed manually. This includes APIs without replacement like PodSecurityPolicy, which are printed unchanged.
+
+Use "kyml deprecations" to find documents, which need to be migrated.`,
+		Example: `  # Migrate files in place
+  kyml cat production/ingress.yaml | kyml migrate --to 1.29 > production/ingress.yaml.new
+  mv production/ingress.yaml.new production/ingress.yaml
+
+  # Migrate deprecated APIs as well
+  kyml cat production/* | kyml migrate --to 1.29 --include-deprecated`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := o.Validate(args)
+			if err != nil {
+				return err
+			}
+
+			return o.Run(in, out, errOut)
+		},
+	}
+
+	cmd.Flags().StringVar(&o.to, "to", validate.LatestBundledVersion().String(), "Kubernetes version to migrate to")
+	cmd.Flags().BoolVar(&o.includeDeprecated, "include-deprecated", false, "Migrate API versions, which are deprecated but still served, as well")
+
+	return cmd
+}
+
+// Validate validates migrate command.
+func (o *migrateOptions) Validate(args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("this command takes no positional arguments")
+	}
+
+	if _, err := kubeversion.Parse(o.to); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Run runs migrate command.
+func (o *migrateOptions) Run(in io.Reader, out, errOut io.Writer) error {
+	version, err := kubeversion.Parse(o.to)
+	if err != nil {
+		return err
+	}
+
+	documents, err := cat.StreamDecodeOnly(in)
+	if err != nil {
+		return err
+	}
+
+	for _, doc := range documents {
+		_, notes := migrate.Migrate(doc, version, o.includeDeprecated)
+		for _, note := range notes {
+			fmt.Fprintf(errOut, "Warning: %s: %s\n", k8syaml.ResourceName(note.Document), note.Message)
+		}
+	}
+
+	// Migration changes apiVersions, which are relevant for deduplication and
+	// sorting.
+	documents = cat.Normalize(documents)
+
+	return k8syaml.Encode(out, documents)
+}
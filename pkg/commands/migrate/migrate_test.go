@@ -0,0 +1,128 @@
+package migrate
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+var testManifests = `---
+apiVersion: policy/v1beta1
+kind: PodSecurityPolicy
+metadata:
+  name: restricted
+spec:
+  privileged: false
+---
+apiVersion: batch/v1beta1
+kind: CronJob
+metadata:
+  name: backup
+spec:
+  schedule: '@daily'
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: the-job
+`
+
+var testManifestsMigrated = `---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: the-job
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: backup
+spec:
+  schedule: '@daily'
+---
+apiVersion: policy/v1beta1
+kind: PodSecurityPolicy
+metadata:
+  name: restricted
+spec:
+  privileged: false
+`
+
+func Test_migrateOptions_Validate(t *testing.T) {
+	type args struct {
+		args []string
+	}
+	tests := []struct {
+		name    string
+		o       *migrateOptions
+		args    args
+		wantErr bool
+	}{
+		{
+			name:    "error if any args",
+			o:       &migrateOptions{to: "1.29"},
+			args:    args{args: []string{"foo"}},
+			wantErr: true,
+		},
+		{
+			name:    "error if version is invalid",
+			o:       &migrateOptions{to: "next"},
+			args:    args{args: []string{}},
+			wantErr: true,
+		},
+		{
+			name:    "success",
+			o:       &migrateOptions{to: "1.29"},
+			args:    args{args: []string{}},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.o.Validate(tt.args.args); (err != nil) != tt.wantErr {
+				t.Errorf("migrateOptions.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_migrateOptions_Run(t *testing.T) {
+	type args struct {
+		in io.Reader
+	}
+	tests := []struct {
+		name       string
+		o          *migrateOptions
+		args       args
+		wantOut    string
+		wantErrOut string
+		wantErr    bool
+	}{
+		{
+			name:       "migrates and reports problems",
+			o:          &migrateOptions{to: "1.29"},
+			args:       args{strings.NewReader(testManifests)},
+			wantOut:    testManifestsMigrated,
+			wantErrOut: "Warning: PodSecurityPolicy/restricted: policy/v1beta1 PodSecurityPolicy has no replacement and cannot be migrated automatically\n",
+			wantErr:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			errOut := &bytes.Buffer{}
+			if err := tt.o.Run(tt.args.in, out, errOut); (err != nil) != tt.wantErr {
+				t.Errorf("migrateOptions.Run() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotOut := out.String(); gotOut != tt.wantOut {
+				t.Errorf("migrateOptions.Run() = %v, want %v", gotOut, tt.wantOut)
+				return
+			}
+			if gotErrOut := errOut.String(); gotErrOut != tt.wantErrOut {
+				t.Errorf("migrateOptions.Run() errOut = %v, want %v", gotErrOut, tt.wantErrOut)
+			}
+		})
+	}
+}
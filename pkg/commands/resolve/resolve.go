@@ -59,7 +59,9 @@ func (o *resolveOptions) Run(in io.Reader, out io.Writer, resolveImage imageReso
 
 	resolvedImageMap := make(map[string]string)
 	for _, doc := range documents {
-		if pathToPodSpec := getPathToPodSpec(doc.GroupVersionKind()); pathToPodSpec != nil {
+		// We only want to resolve images mentioned in the `image` property of
+		// containers. These currently only appear in PodSpec.
+		if pathToPodSpec := k8syaml.PathToPodSpec(doc.GroupVersionKind()); pathToPodSpec != nil {
 			obj := doc.UnstructuredContent()
 
 			pathToInitContainers := append(pathToPodSpec, "initContainers")
@@ -0,0 +1,212 @@
+package test
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/frigus02/kyml/pkg/cat"
+	"github.com/frigus02/kyml/pkg/fs"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// testConfig describes environments and the comparisons between them, which
+// should be tested. Relative paths are relative to the config file.
+type testConfig struct {
+	Environments map[string]testEnvironment `json:"environments"`
+	Comparisons  []testComparison           `json:"comparisons"`
+}
+
+type testEnvironment struct {
+	Files []string `json:"files"`
+}
+
+type testComparison struct {
+	Main         string `json:"main"`
+	Comparison   string `json:"comparison"`
+	SnapshotFile string `json:"snapshotFile"`
+	SnapshotDir  string `json:"snapshotDir"`
+	DiffMode     string `json:"diffMode"`
+	IgnoreFile   string `json:"ignoreFile"`
+}
+
+func loadConfig(filename string, fs fs.Filesystem) (*testConfig, error) {
+	data, err := fs.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open config file: %v", err)
+	}
+
+	var config testConfig
+	if err := yaml.UnmarshalStrict(data, &config); err != nil {
+		return nil, fmt.Errorf("error parsing config file: %v", err)
+	}
+
+	if len(config.Comparisons) == 0 {
+		return nil, fmt.Errorf("config file contains no comparisons")
+	}
+
+	dir := filepath.Dir(filename)
+	for name, env := range config.Environments {
+		if len(env.Files) == 0 {
+			return nil, fmt.Errorf("environment %s has no files", name)
+		}
+
+		for i, pattern := range env.Files {
+			env.Files[i] = resolvePath(dir, pattern)
+		}
+	}
+
+	for i := range config.Comparisons {
+		c := &config.Comparisons[i]
+		for _, name := range []string{c.Main, c.Comparison} {
+			if _, ok := config.Environments[name]; !ok {
+				return nil, fmt.Errorf("comparison %d: environment \"%s\" does not exist", i, name)
+			}
+		}
+
+		if err := validateDiffMode(c.DiffMode); err != nil {
+			return nil, fmt.Errorf("comparison %s vs %s: %v", c.Main, c.Comparison, err)
+		}
+
+		if c.SnapshotDir != "" {
+			if c.SnapshotFile != "" {
+				return nil, fmt.Errorf("comparison %s vs %s: snapshotFile and snapshotDir cannot be specified together", c.Main, c.Comparison)
+			}
+
+			c.SnapshotDir = resolvePath(dir, c.SnapshotDir)
+		} else {
+			if c.SnapshotFile == "" {
+				c.SnapshotFile = fmt.Sprintf("%s-vs-%s.diff", c.Main, c.Comparison)
+			}
+
+			c.SnapshotFile = resolvePath(dir, c.SnapshotFile)
+		}
+
+		if c.IgnoreFile != "" {
+			c.IgnoreFile = resolvePath(dir, c.IgnoreFile)
+		}
+	}
+
+	return &config, nil
+}
+
+func resolvePath(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(dir, path)
+}
+
+// loadEnvironment concatenates the files of the environment in the same way
+// as "kyml cat". Each entry may be a glob pattern, which has to match at
+// least one file.
+func loadEnvironment(env testEnvironment, fs fs.Filesystem) ([]*unstructured.Unstructured, error) {
+	var files []string
+	for _, pattern := range env.Files {
+		matches, err := fs.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("pattern %s matches no files", pattern)
+		}
+
+		files = append(files, matches...)
+	}
+
+	return cat.CatDecodeOnly(files, fs)
+}
+
+// runConfig runs all comparisons in the config file and prints a summary.
+func (o *testOptions) runConfig(out io.Writer, fs fs.Filesystem) error {
+	config, err := loadConfig(o.configFile, fs)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(config.Environments))
+	for name := range config.Environments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	environments := make(map[string][]*unstructured.Unstructured)
+	for _, name := range names {
+		if environments[name], err = loadEnvironment(config.Environments[name], fs); err != nil {
+			return fmt.Errorf("environment %s: %v", name, err)
+		}
+	}
+
+	update := o.update()
+
+	var entries []reportEntry
+	passed, failed, updated := 0, 0, 0
+	for _, c := range config.Comparisons {
+		t := snapshotTest{
+			nameMain:       c.Main,
+			docsMain:       environments[c.Main],
+			nameComparison: c.Comparison,
+			docsComparison: environments[c.Comparison],
+			snapshotFile:   c.SnapshotFile,
+			snapshotDir:    c.SnapshotDir,
+			diffMode:       c.DiffMode,
+			ignoreFile:     c.IgnoreFile,
+			migrate:        o.migrate,
+		}
+		if t.diffMode == "" {
+			t.diffMode = o.diffMode
+		}
+		if t.ignoreFile == "" {
+			t.ignoreFile = o.ignoreFile
+		}
+
+		entry := reportEntry{test: t}
+		entry.result, entry.err = t.run(update, fs)
+		entries = append(entries, entry)
+
+		name := fmt.Sprintf("%s (%s)", t, t.location())
+		switch entry.status() {
+		case "error":
+			failed++
+			fmt.Fprintf(out, "FAIL    %s\n%s\n", name, indent(entry.err.Error()))
+		case "passed":
+			passed++
+			fmt.Fprintf(out, "ok      %s\n", name)
+		case "updated":
+			updated++
+			fmt.Fprintf(out, "updated %s\n", name)
+		default:
+			failed++
+			fmt.Fprintf(out, "FAIL    %s\n%s", name, indent(entry.result.render(o.context, o.useColor)))
+		}
+	}
+
+	fmt.Fprintf(out, "\n%d passed, %d failed, %d updated\n", passed, failed, updated)
+
+	if err := writeReports(o.reports, entries, fs); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d snapshots do not match\nRun the command with --update to update them", failed, len(config.Comparisons))
+	}
+
+	return nil
+}
+
+func indent(s string) string {
+	lines := strings.SplitAfter(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = "    " + line
+		}
+	}
+
+	return strings.Join(lines, "")
+}
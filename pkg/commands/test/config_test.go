@@ -0,0 +1,168 @@
+package test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+var testConfigFile = `environments:
+  production:
+    files: [testdata/production/*.yaml]
+  staging:
+    files: [testdata/staging/*.yaml]
+comparisons:
+- main: staging
+  comparison: production
+  snapshotFile: snapshots/staging-vs-production.diff
+  diffMode: structural
+- main: staging
+  comparison: staging
+`
+
+func Test_testOptions_runConfig(t *testing.T) {
+	tests := []struct {
+		name             string
+		o                *testOptions
+		files            map[string]string
+		wantOut          string
+		wantSnapshots    map[string]string
+		wantErr          bool
+		wantErrToContain string
+	}{
+		{
+			name: "all snapshots match",
+			o:    &testOptions{configFile: "kyml-test.yaml", diffMode: "unified"},
+			files: map[string]string{
+				"kyml-test.yaml":                       testConfigFile,
+				"snapshots/staging-vs-production.diff": "--- staging\n+++ production\nDeployment.apps/the-deployment spec.replicas: 1 -> 3\n",
+				"staging-vs-staging.diff":              "",
+			},
+			wantOut: `ok      staging vs production (snapshots/staging-vs-production.diff)
+ok      staging vs staging (staging-vs-staging.diff)
+
+2 passed, 0 failed, 0 updated
+`,
+			wantErr: false,
+		},
+		{
+			name: "snapshot missing and snapshot does not match",
+			o:    &testOptions{configFile: "kyml-test.yaml", diffMode: "unified"},
+			files: map[string]string{
+				"kyml-test.yaml":                       testConfigFile,
+				"snapshots/staging-vs-production.diff": "--- staging\n+++ production\nDeployment.apps/the-deployment spec.replicas: 1 -> 2\n",
+			},
+			wantOut: `FAIL    staging vs production (snapshots/staging-vs-production.diff)
+    --- snapshot diff
+    +++ this diff
+    @@ -3 +3 @@ Deployment.apps/the-deployment
+    -Deployment.apps/the-deployment spec.replicas: 1 -> 2
+    +Deployment.apps/the-deployment spec.replicas: 1 -> 3
+FAIL    staging vs staging (staging-vs-staging.diff)
+    snapshot file does not exist
+    Run the command with --update to create it
+
+0 passed, 2 failed, 0 updated
+`,
+			wantErr:          true,
+			wantErrToContain: "2 of 2 snapshots do not match",
+		},
+		{
+			name: "update all snapshots",
+			o:    &testOptions{configFile: "kyml-test.yaml", diffMode: "unified", updateSnapshot: true},
+			files: map[string]string{
+				"kyml-test.yaml":                       testConfigFile,
+				"snapshots/staging-vs-production.diff": "--- staging\n+++ production\nDeployment.apps/the-deployment spec.replicas: 1 -> 2\n",
+			},
+			wantOut: `updated staging vs production (snapshots/staging-vs-production.diff)
+updated staging vs staging (staging-vs-staging.diff)
+
+0 passed, 0 failed, 2 updated
+`,
+			wantSnapshots: map[string]string{
+				"snapshots/staging-vs-production.diff": "# kyml snapshot\n# format-version: 1\n# diff-mode: structural\n--- staging\n+++ production\nDeployment.apps/the-deployment spec.replicas: 1 -> 3\n",
+				"staging-vs-staging.diff":              "# kyml snapshot\n# format-version: 1\n# diff-mode: unified\n",
+			},
+			wantErr: false,
+		},
+		{
+			name: "paths relative to config file",
+			o:    &testOptions{configFile: "tests/kyml-test.yaml", diffMode: "unified"},
+			files: map[string]string{
+				"tests/kyml-test.yaml":                       strings.ReplaceAll(testConfigFile, "testdata/", "../testdata/"),
+				"tests/snapshots/staging-vs-production.diff": "--- staging\n+++ production\nDeployment.apps/the-deployment spec.replicas: 1 -> 3\n",
+				"tests/staging-vs-staging.diff":              "",
+			},
+			wantOut: `ok      staging vs production (tests/snapshots/staging-vs-production.diff)
+ok      staging vs staging (tests/staging-vs-staging.diff)
+
+2 passed, 0 failed, 0 updated
+`,
+			wantErr: false,
+		},
+		{
+			name: "pattern matches no files",
+			o:    &testOptions{configFile: "kyml-test.yaml", diffMode: "unified"},
+			files: map[string]string{
+				"kyml-test.yaml": "environments:\n  a:\n    files: [a/*.yaml]\ncomparisons:\n- main: a\n  comparison: a\n",
+			},
+			wantErr:          true,
+			wantErrToContain: "pattern a/*.yaml matches no files",
+		},
+		{
+			name: "unknown environment",
+			o:    &testOptions{configFile: "kyml-test.yaml", diffMode: "unified"},
+			files: map[string]string{
+				"kyml-test.yaml": "environments:\n  a:\n    files: [a.yaml]\ncomparisons:\n- main: a\n  comparison: b\n",
+			},
+			wantErr:          true,
+			wantErrToContain: "environment \"b\" does not exist",
+		},
+		{
+			name: "snapshot directory",
+			o:    &testOptions{configFile: "kyml-test.yaml", diffMode: "structural"},
+			files: map[string]string{
+				"kyml-test.yaml": "environments:\n  production:\n    files: [testdata/production/*.yaml]\n  staging:\n    files: [testdata/staging/*.yaml]\ncomparisons:\n- main: staging\n  comparison: production\n  snapshotDir: snapshots\n",
+				"snapshots/Deployment.apps_the-deployment.diff": "--- staging\n+++ production\nDeployment.apps/the-deployment spec.replicas: 1 -> 3\n",
+			},
+			wantOut: `ok      staging vs production (snapshots)
+
+1 passed, 0 failed, 0 updated
+`,
+			wantErr: false,
+		},
+		{
+			name: "snapshot file and directory",
+			o:    &testOptions{configFile: "kyml-test.yaml", diffMode: "unified"},
+			files: map[string]string{
+				"kyml-test.yaml": "environments:\n  a:\n    files: [a.yaml]\ncomparisons:\n- main: a\n  comparison: a\n  snapshotFile: a.diff\n  snapshotDir: snapshots\n",
+			},
+			wantErr:          true,
+			wantErrToContain: "snapshotFile and snapshotDir cannot be specified together",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := mustCreateFsWithFiles(t, tt.files)
+			out := &bytes.Buffer{}
+			err := tt.o.Run(nil, out, fs)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("testOptions.Run() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil && !strings.Contains(err.Error(), tt.wantErrToContain) {
+				t.Errorf("testOptions.Run() error = %v, wantErrToContain %v", err, tt.wantErrToContain)
+				return
+			}
+			if gotOut := out.String(); gotOut != tt.wantOut {
+				t.Errorf("testOptions.Run() = %v, want %v", gotOut, tt.wantOut)
+				return
+			}
+			for name, want := range tt.wantSnapshots {
+				if got := readFileOrEmpty(name, fs); got != want {
+					t.Errorf("testOptions.Run() snapshot %s = %v, want %v", name, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package test
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// snapshotFormatVersion is the version of the snapshot format. Increase it
+// whenever the diff of unchanged environments changes, e.g. because of a
+// different YAML encoding or sort order, and add a migration for the previous
+// version.
+const snapshotFormatVersion = 1
+
+// snapshotMigrations convert the body of a snapshot from the format version
+// used as key to the next version.
+var snapshotMigrations = map[int]func(body string) string{
+	// Version 0 snapshots have no header. The body is the same.
+	0: func(body string) string { return body },
+}
+
+const snapshotHeaderStart = "# kyml snapshot\n"
+
+// snapshotHeader describes how a snapshot was created. It's written at the
+// start of every snapshot file as comments.
+type snapshotHeader struct {
+	version  int
+	diffMode string
+	// ignoreFile is the path of the ignore file relative to the directory of
+	// the snapshot file, so it doesn't depend on the working directory.
+	ignoreFile string
+}
+
+func (h snapshotHeader) String() string {
+	var sb strings.Builder
+	sb.WriteString(snapshotHeaderStart)
+	fmt.Fprintf(&sb, "# format-version: %d\n", h.version)
+	fmt.Fprintf(&sb, "# diff-mode: %s\n", h.diffMode)
+	if h.ignoreFile != "" {
+		fmt.Fprintf(&sb, "# ignore-file: %s\n", h.ignoreFile)
+	}
+
+	return sb.String()
+}
+
+// header returns the header for snapshots created by the test.
+func (t snapshotTest) header() snapshotHeader {
+	diffMode := t.diffMode
+	if diffMode == "" {
+		diffMode = "unified"
+	}
+
+	return snapshotHeader{
+		version:    snapshotFormatVersion,
+		diffMode:   diffMode,
+		ignoreFile: relativePath(t.ignoreFile, filepath.Dir(t.snapshotFile)),
+	}
+}
+
+// relativePath returns the path relative to the directory using forward
+// slashes, so it's the same on all platforms. If this is not possible, e.g.
+// because the path is on another drive, it returns the absolute path.
+func relativePath(path, dir string) string {
+	if path == "" {
+		return ""
+	}
+
+	if filepath.IsAbs(path) != filepath.IsAbs(dir) {
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return filepath.ToSlash(path)
+		}
+
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			return filepath.ToSlash(absPath)
+		}
+
+		path, dir = absPath, absDir
+	}
+
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+
+	return filepath.ToSlash(rel)
+}
+
+// parseSnapshot splits the content of a snapshot file into header and body.
+// Snapshots without header have format version 0.
+func parseSnapshot(content string) (snapshotHeader, string, error) {
+	if !strings.HasPrefix(content, snapshotHeaderStart) {
+		return snapshotHeader{}, content, nil
+	}
+
+	var h snapshotHeader
+	body := content[len(snapshotHeaderStart):]
+	for strings.HasPrefix(body, "# ") {
+		end := strings.Index(body, "\n") + 1
+		if end == 0 {
+			end = len(body)
+		}
+
+		line := strings.TrimSpace(body[2:end])
+		body = body[end:]
+
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			return snapshotHeader{}, "", fmt.Errorf("invalid snapshot header line \"%s\"", line)
+		}
+
+		value := strings.TrimSpace(parts[1])
+		switch parts[0] {
+		case "format-version":
+			version, err := strconv.Atoi(value)
+			if err != nil || version < 1 {
+				return snapshotHeader{}, "", fmt.Errorf("invalid snapshot format version \"%s\"", value)
+			}
+			h.version = version
+		case "diff-mode":
+			h.diffMode = value
+		case "ignore-file":
+			h.ignoreFile = value
+		}
+	}
+
+	if h.version == 0 {
+		return snapshotHeader{}, "", fmt.Errorf("snapshot header has no format version")
+	}
+
+	return h, body, nil
+}
+
+// migrateSnapshot converts the body of a snapshot with the specified header to
+// the current format version.
+func migrateSnapshot(h snapshotHeader, body string) (string, error) {
+	if h.version > snapshotFormatVersion {
+		return "", fmt.Errorf("snapshot file was created by a newer version of kyml with format version %d (supported is %d)\nUpdate kyml to run the test", h.version, snapshotFormatVersion)
+	}
+
+	for version := h.version; version < snapshotFormatVersion; version++ {
+		migration, ok := snapshotMigrations[version]
+		if !ok {
+			return "", fmt.Errorf("cannot migrate snapshot file from format version %d", version)
+		}
+
+		body = migration(body)
+	}
+
+	return body, nil
+}
+
+// checkOptions returns an error if the snapshot with the specified header was
+// created with different options than the test uses. Options of snapshots
+// without header are unknown.
+func (t snapshotTest) checkOptions(h snapshotHeader) error {
+	current := t.header()
+	switch {
+	case h.diffMode == "":
+		return nil
+	case h.diffMode != current.diffMode:
+		return fmt.Errorf("snapshot file was created with diff mode %s, but the test uses diff mode %s\nRun the command with --update to recreate it", h.diffMode, current.diffMode)
+	case h.ignoreFile != current.ignoreFile:
+		return fmt.Errorf("snapshot file was created with ignore file \"%s\", but the test uses ignore file \"%s\"\nRun the command with --update to recreate it", h.ignoreFile, current.ignoreFile)
+	default:
+		return nil
+	}
+}
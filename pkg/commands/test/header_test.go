@@ -0,0 +1,187 @@
+package test
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_parseSnapshot(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		wantHeader snapshotHeader
+		wantBody   string
+		wantErr    bool
+	}{
+		{
+			name:       "no header",
+			content:    "--- a\n+++ b\n",
+			wantHeader: snapshotHeader{},
+			wantBody:   "--- a\n+++ b\n",
+		},
+		{
+			name:       "header",
+			content:    "# kyml snapshot\n# format-version: 1\n# diff-mode: structural\n# ignore-file: ignore.yaml\n--- a\n+++ b\n",
+			wantHeader: snapshotHeader{version: 1, diffMode: "structural", ignoreFile: "ignore.yaml"},
+			wantBody:   "--- a\n+++ b\n",
+		},
+		{
+			name:       "header only",
+			content:    "# kyml snapshot\n# format-version: 1\n# diff-mode: unified\n",
+			wantHeader: snapshotHeader{version: 1, diffMode: "unified"},
+			wantBody:   "",
+		},
+		{
+			name:    "invalid format version",
+			content: "# kyml snapshot\n# format-version: one\n",
+			wantErr: true,
+		},
+		{
+			name:    "missing format version",
+			content: "# kyml snapshot\n# diff-mode: unified\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotHeader, gotBody, err := parseSnapshot(tt.content)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseSnapshot() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotHeader, tt.wantHeader) {
+				t.Errorf("parseSnapshot() header = %v, want %v", gotHeader, tt.wantHeader)
+			}
+			if gotBody != tt.wantBody {
+				t.Errorf("parseSnapshot() body = %q, want %q", gotBody, tt.wantBody)
+			}
+		})
+	}
+}
+
+func Test_snapshotHeader_String(t *testing.T) {
+	h := snapshotHeader{version: 1, diffMode: "unified", ignoreFile: "ignore.yaml"}
+	want := "# kyml snapshot\n# format-version: 1\n# diff-mode: unified\n# ignore-file: ignore.yaml\n"
+	if got := h.String(); got != want {
+		t.Errorf("snapshotHeader.String() = %q, want %q", got, want)
+	}
+
+	gotHeader, gotBody, err := parseSnapshot(want + "body\n")
+	if err != nil || gotHeader != h || gotBody != "body\n" {
+		t.Errorf("parseSnapshot(snapshotHeader.String()) = %v, %q, %v", gotHeader, gotBody, err)
+	}
+}
+
+func Test_testOptions_Run_snapshotFormat(t *testing.T) {
+	// Pretend the previous format used a different indentation.
+	originalMigrations := snapshotMigrations
+	snapshotMigrations = map[int]func(string) string{
+		0: func(body string) string { return strings.ReplaceAll(body, "replicas:  ", "replicas: ") },
+	}
+	defer func() { snapshotMigrations = originalMigrations }()
+	oldReplicas := func(n string) string {
+		return "--- staging\n+++ production\n@@ -19 +19 @@\n-  replicas:  1\n+  replicas:  " + n + "\n"
+	}
+	replicas := func(n string) string {
+		return "--- staging\n+++ production\n@@ -19 +19 @@\n-  replicas: 1\n+  replicas: " + n + "\n"
+	}
+	header := "# kyml snapshot\n# format-version: 1\n# diff-mode: unified\n"
+	diffStr := replicas("3")
+
+	tests := []struct {
+		name             string
+		o                *testOptions
+		snapshot         string
+		wantSnapshot     string
+		wantErr          bool
+		wantErrToContain string
+	}{
+		{
+			name:             "old format version",
+			o:                &testOptions{},
+			snapshot:         oldReplicas("3"),
+			wantSnapshot:     oldReplicas("3"),
+			wantErr:          true,
+			wantErrToContain: "snapshot file uses format version 0, but this version of kyml uses format version 1\nRun the command with --migrate to migrate it",
+		},
+		{
+			name:         "old format version and migration requested",
+			o:            &testOptions{migrate: true},
+			snapshot:     oldReplicas("3"),
+			wantSnapshot: header + replicas("3"),
+		},
+		{
+			name:             "old format version doesn't match after migration",
+			o:                &testOptions{migrate: true},
+			snapshot:         oldReplicas("2"),
+			wantSnapshot:     header + replicas("2"),
+			wantErr:          true,
+			wantErrToContain: "-+  replicas: 2\n++  replicas: 3\n",
+		},
+		{
+			name:             "newer format version",
+			o:                &testOptions{},
+			snapshot:         "# kyml snapshot\n# format-version: 2\n# diff-mode: unified\n" + diffStr,
+			wantSnapshot:     "# kyml snapshot\n# format-version: 2\n# diff-mode: unified\n" + diffStr,
+			wantErr:          true,
+			wantErrToContain: "snapshot file was created by a newer version of kyml with format version 2 (supported is 1)",
+		},
+		{
+			name:             "different diff mode",
+			o:                &testOptions{},
+			snapshot:         "# kyml snapshot\n# format-version: 1\n# diff-mode: structural\nDeployment.apps/the-deployment spec.replicas: 1 -> 3\n",
+			wantSnapshot:     "# kyml snapshot\n# format-version: 1\n# diff-mode: structural\nDeployment.apps/the-deployment spec.replicas: 1 -> 3\n",
+			wantErr:          true,
+			wantErrToContain: "snapshot file was created with diff mode structural, but the test uses diff mode unified\nRun the command with --update to recreate it",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.o.nameMain = "staging"
+			tt.o.nameComparison = "production"
+			tt.o.files = []string{"testdata/production/deployment.yaml", "testdata/production/service.yaml"}
+			tt.o.snapshotFile = "kyml-snapshot.diff"
+			in := mustCreateStream(t, "testdata/staging/deployment.yaml", "testdata/staging/service.yaml")
+			fs := mustCreateFsWithSnapshot(t, tt.snapshot)
+
+			err := tt.o.Run(in, &bytes.Buffer{}, fs)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("testOptions.Run() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil && !strings.Contains(err.Error(), tt.wantErrToContain) {
+				t.Errorf("testOptions.Run() error = %v, wantErrToContain %v", err, tt.wantErrToContain)
+				return
+			}
+			if gotSnapshot := readFileOrEmpty(tt.o.snapshotFile, fs); gotSnapshot != tt.wantSnapshot {
+				t.Errorf("testOptions.Run() snapshot = %q, wantSnapshot %q", gotSnapshot, tt.wantSnapshot)
+			}
+		})
+	}
+}
+
+func Test_snapshotTest_header_ignoreFile(t *testing.T) {
+	tests := []struct {
+		name         string
+		snapshotFile string
+		ignoreFile   string
+		want         string
+	}{
+		{name: "no ignore file", snapshotFile: "tests/snapshot.diff", ignoreFile: "", want: ""},
+		{name: "same directory", snapshotFile: "tests/snapshot.diff", ignoreFile: "tests/ignore.yaml", want: "ignore.yaml"},
+		{name: "other working directory", snapshotFile: "repo/tests/snapshot.diff", ignoreFile: "repo/tests/ignore.yaml", want: "ignore.yaml"},
+		{name: "other directory", snapshotFile: "tests/snapshots/app.diff", ignoreFile: "tests/ignore.yaml", want: "../ignore.yaml"},
+		{name: "absolute paths", snapshotFile: "/repo/tests/snapshot.diff", ignoreFile: "/repo/ignore.yaml", want: "../ignore.yaml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test := snapshotTest{snapshotFile: filepath.FromSlash(tt.snapshotFile), ignoreFile: filepath.FromSlash(tt.ignoreFile)}
+			if got := test.header().ignoreFile; got != tt.want {
+				t.Errorf("snapshotTest.header().ignoreFile = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package test
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/frigus02/kyml/pkg/diff"
+)
+
+// reviewer asks the user for every change between a snapshot and this diff
+// whether it should be accepted.
+type reviewer struct {
+	in      *bufio.Reader
+	out     io.Writer
+	context int
+	color   bool
+	quit    bool
+}
+
+func newReviewer(in io.Reader, out io.Writer, context int, color bool) *reviewer {
+	return &reviewer{in: bufio.NewReader(in), out: out, context: context, color: color}
+}
+
+// review is an updateFunc, which returns the snapshot with only the accepted
+// changes applied.
+func (r *reviewer) review(t snapshotTest, result snapshotResult) (string, error) {
+	hunks := diff.Hunks(result.snapshot, result.diff)
+	accepted := make([]bool, len(hunks))
+
+	var rest byte
+	for i, h := range hunks {
+		if r.quit || rest == 'r' {
+			break
+		}
+
+		if rest == 'a' {
+			accepted[i] = true
+			continue
+		}
+
+		fmt.Fprintf(r.out, "\n%s (%s): change %d of %d\n", t, t.snapshotFile, i+1, len(hunks))
+		fmt.Fprint(r.out, diff.RenderHunk(result.snapshot, result.diff, h, diff.RenderOptions{
+			Context: r.context,
+			Color:   r.color,
+			Header:  result.header,
+		}))
+
+		answer, err := r.ask()
+		if err != nil {
+			return "", err
+		}
+
+		switch answer {
+		case 'y':
+			accepted[i] = true
+		case 'a':
+			accepted[i] = true
+			rest = 'a'
+		case 'r':
+			rest = 'r'
+		case 'q':
+			r.quit = true
+		}
+	}
+
+	return diff.Apply(result.snapshot, result.diff, hunks, accepted), nil
+}
+
+// ask reads the answer to the question whether to accept a change. The end of
+// the input is treated like quitting.
+func (r *reviewer) ask() (byte, error) {
+	for {
+		fmt.Fprint(r.out, "Accept this change? [y]es, [n]o, [a]ccept all in this snapshot, [r]eject all in this snapshot, [q]uit: ")
+
+		line, err := r.in.ReadString('\n')
+		if err == io.EOF && line == "" {
+			fmt.Fprintln(r.out)
+			return 'q', nil
+		} else if err != nil && err != io.EOF {
+			return 0, err
+		}
+
+		answer := strings.ToLower(strings.TrimSpace(line))
+		if len(answer) == 1 && strings.Contains("ynarq", answer) {
+			return answer[0], nil
+		}
+	}
+}
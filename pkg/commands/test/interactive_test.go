@@ -0,0 +1,100 @@
+package test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func Test_reviewer_review(t *testing.T) {
+	snapshot := "--- a\n+++ b\nX spec.replicas: 1 -> 2\nY: added\nZ spec.image: \"a\" -> \"b\"\n"
+	diff := "--- a\n+++ b\nX spec.replicas: 1 -> 3\nY: added\nZ spec.image: \"a\" -> \"c\"\n"
+
+	tests := []struct {
+		name         string
+		answers      string
+		wantSnapshot string
+		wantQuit     bool
+		wantPrompts  int
+	}{
+		{
+			name:         "accept",
+			answers:      "y\ny\n",
+			wantSnapshot: diff,
+			wantPrompts:  2,
+		},
+		{
+			name:         "reject",
+			answers:      "n\nn\n",
+			wantSnapshot: snapshot,
+			wantPrompts:  2,
+		},
+		{
+			name:         "accept only some",
+			answers:      "n\ny\n",
+			wantSnapshot: "--- a\n+++ b\nX spec.replicas: 1 -> 2\nY: added\nZ spec.image: \"a\" -> \"c\"\n",
+			wantPrompts:  2,
+		},
+		{
+			name:         "accept all remaining",
+			answers:      "a\n",
+			wantSnapshot: diff,
+			wantPrompts:  1,
+		},
+		{
+			name:         "reject all remaining",
+			answers:      "y\nr\n",
+			wantSnapshot: "--- a\n+++ b\nX spec.replicas: 1 -> 3\nY: added\nZ spec.image: \"a\" -> \"b\"\n",
+			wantPrompts:  2,
+		},
+		{
+			name:         "invalid answer is asked again",
+			answers:      "maybe\nY\nn\n",
+			wantSnapshot: "--- a\n+++ b\nX spec.replicas: 1 -> 3\nY: added\nZ spec.image: \"a\" -> \"b\"\n",
+			wantPrompts:  3,
+		},
+		{
+			name:         "quit",
+			answers:      "y\nq\n",
+			wantSnapshot: "--- a\n+++ b\nX spec.replicas: 1 -> 3\nY: added\nZ spec.image: \"a\" -> \"b\"\n",
+			wantQuit:     true,
+			wantPrompts:  2,
+		},
+		{
+			name:         "end of input quits",
+			answers:      "",
+			wantSnapshot: snapshot,
+			wantQuit:     true,
+			wantPrompts:  1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			r := newReviewer(strings.NewReader(tt.answers), out, 0, false)
+			test := snapshotTest{nameMain: "a", nameComparison: "b", snapshotFile: "a-vs-b.diff"}
+			result := snapshotResult{
+				snapshot:      snapshot,
+				diff:          diff,
+				lineResources: []string{"", "", "X", "Y", "Z"},
+			}
+
+			got, err := r.review(test, result)
+			if err != nil {
+				t.Fatalf("reviewer.review() error = %v", err)
+			}
+			if got != tt.wantSnapshot {
+				t.Errorf("reviewer.review() = %q, want %q", got, tt.wantSnapshot)
+			}
+			if r.quit != tt.wantQuit {
+				t.Errorf("reviewer.quit = %v, want %v", r.quit, tt.wantQuit)
+			}
+			if prompts := strings.Count(out.String(), "Accept this change?"); prompts != tt.wantPrompts {
+				t.Errorf("reviewer asked %d times, want %d\n%s", prompts, tt.wantPrompts, out.String())
+			}
+			if !strings.Contains(out.String(), "a vs b (a-vs-b.diff): change 1 of 2\n@@ -3 +3 @@ X\n-X spec.replicas: 1 -> 2\n+X spec.replicas: 1 -> 3\n") {
+				t.Errorf("reviewer output doesn't contain first change:\n%s", out.String())
+			}
+		})
+	}
+}
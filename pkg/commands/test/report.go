@@ -0,0 +1,234 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/frigus02/kyml/pkg/fs"
+)
+
+// reportEntry is the outcome of a single snapshot test.
+type reportEntry struct {
+	test   snapshotTest
+	result snapshotResult
+	err    error
+}
+
+func (e reportEntry) status() string {
+	switch {
+	case e.err != nil:
+		return "error"
+	case e.result.snapshotDiff != "":
+		return "failed"
+	case e.result.updated:
+		return "updated"
+	default:
+		return "passed"
+	}
+}
+
+func validateReport(report string) error {
+	parts := strings.SplitN(report, "=", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return fmt.Errorf("invalid report \"%s\" (expected format=path)", report)
+	}
+
+	switch parts[0] {
+	case "junit", "json":
+		return nil
+	default:
+		return fmt.Errorf("invalid report format \"%s\" (supported are junit and json)", parts[0])
+	}
+}
+
+// writeReports writes the entries to all reports, which have the format
+// "format=path".
+func writeReports(reports []string, entries []reportEntry, fs fs.Filesystem) error {
+	for _, report := range reports {
+		parts := strings.SplitN(report, "=", 2)
+
+		var buf bytes.Buffer
+		var err error
+		switch parts[0] {
+		case "junit":
+			err = writeJUnitReport(&buf, entries)
+		case "json":
+			err = writeJSONReport(&buf, entries)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := fs.WriteFile(parts[1], buf.Bytes(), 0644); err != nil {
+			return fmt.Errorf("cannot write report: %v", err)
+		}
+	}
+
+	return nil
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// writeJUnitReport writes a JUnit XML report. Every snapshot test is a test
+// suite. It contains a test case for the whole snapshot and, for structural
+// diffs, one for every resource.
+func writeJUnitReport(w io.Writer, entries []reportEntry) error {
+	suites := junitTestSuites{Name: "kyml test"}
+	for _, entry := range entries {
+		suite := junitTestSuite{Name: entry.test.String()}
+
+		snapshotCase := junitTestCase{Name: entry.test.location(), ClassName: suite.Name}
+		switch entry.status() {
+		case "error":
+			snapshotCase.Error = &junitMessage{Message: entry.err.Error()}
+		case "failed":
+			snapshotCase.Failure = &junitMessage{Message: "snapshot diff does not match this diff", Text: entry.result.snapshotDiff}
+		case "updated":
+			snapshotCase.SystemOut = "snapshot updated"
+		}
+		suite.Cases = append(suite.Cases, snapshotCase)
+
+		for _, resource := range entry.result.resources {
+			resourceCase := junitTestCase{Name: resource.name, ClassName: suite.Name}
+			if resource.snapshotDiff != "" {
+				resourceCase.Failure = &junitMessage{Message: "snapshot diff does not match this diff", Text: resource.snapshotDiff}
+			}
+			suite.Cases = append(suite.Cases, resourceCase)
+		}
+
+		for _, c := range suite.Cases {
+			suite.Tests++
+			if c.Failure != nil {
+				suite.Failures++
+			}
+			if c.Error != nil {
+				suite.Errors++
+			}
+		}
+
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Errors += suite.Errors
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+type jsonReport struct {
+	Passed      int              `json:"passed"`
+	Failed      int              `json:"failed"`
+	Updated     int              `json:"updated"`
+	Errors      int              `json:"errors"`
+	Comparisons []jsonComparison `json:"comparisons"`
+}
+
+type jsonComparison struct {
+	Main         string         `json:"main"`
+	Comparison   string         `json:"comparison"`
+	SnapshotFile string         `json:"snapshotFile,omitempty"`
+	SnapshotDir  string         `json:"snapshotDir,omitempty"`
+	Status       string         `json:"status"`
+	Error        string         `json:"error,omitempty"`
+	SnapshotDiff string         `json:"snapshotDiff,omitempty"`
+	Resources    []jsonResource `json:"resources,omitempty"`
+}
+
+type jsonResource struct {
+	Name         string `json:"name"`
+	Status       string `json:"status"`
+	SnapshotDiff string `json:"snapshotDiff,omitempty"`
+}
+
+// writeJSONReport writes a JSON report with the status of every snapshot test
+// and, for structural diffs, every resource.
+func writeJSONReport(w io.Writer, entries []reportEntry) error {
+	report := jsonReport{Comparisons: make([]jsonComparison, 0, len(entries))}
+	for _, entry := range entries {
+		comparison := jsonComparison{
+			Main:         entry.test.nameMain,
+			Comparison:   entry.test.nameComparison,
+			SnapshotFile: entry.test.snapshotFile,
+			SnapshotDir:  entry.test.snapshotDir,
+			Status:       entry.status(),
+			SnapshotDiff: entry.result.snapshotDiff,
+		}
+		if entry.err != nil {
+			comparison.Error = entry.err.Error()
+		}
+
+		for _, resource := range entry.result.resources {
+			status := "passed"
+			if resource.snapshotDiff != "" {
+				status = "failed"
+			}
+
+			comparison.Resources = append(comparison.Resources, jsonResource{
+				Name:         resource.name,
+				Status:       status,
+				SnapshotDiff: resource.snapshotDiff,
+			})
+		}
+
+		switch comparison.Status {
+		case "passed":
+			report.Passed++
+		case "failed":
+			report.Failed++
+		case "updated":
+			report.Updated++
+		case "error":
+			report.Errors++
+		}
+
+		report.Comparisons = append(report.Comparisons, comparison)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(report)
+}
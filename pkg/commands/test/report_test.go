@@ -0,0 +1,94 @@
+package test
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_testOptions_Run_reports(t *testing.T) {
+	fs := mustCreateFsWithFiles(t, map[string]string{
+		"kyml-snapshot.diff": "--- staging\n+++ production\nDeployment.apps/the-deployment spec.replicas: 1 -> 2\n",
+	})
+	o := &testOptions{
+		nameMain:       "staging",
+		nameComparison: "production",
+		files:          []string{"testdata/production/deployment.yaml", "testdata/production/service.yaml"},
+		diffMode:       "structural",
+		snapshotFile:   "kyml-snapshot.diff",
+		reports:        []string{"junit=report.xml", "json=report.json"},
+	}
+
+	err := o.Run(mustCreateStream(t, "testdata/staging/deployment.yaml", "testdata/staging/service.yaml"), &bytes.Buffer{}, fs)
+	if err == nil {
+		t.Fatalf("testOptions.Run() expected error")
+	}
+
+	wantJUnit := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="kyml test" tests="3" failures="2" errors="0">
+  <testsuite name="staging vs production" tests="3" failures="2" errors="0">
+    <testcase name="kyml-snapshot.diff" classname="staging vs production">
+      <failure message="snapshot diff does not match this diff">--- snapshot diff&#xA;+++ this diff&#xA;@@ -3 +3 @@&#xA;-Deployment.apps/the-deployment spec.replicas: 1 -&gt; 2&#xA;+Deployment.apps/the-deployment spec.replicas: 1 -&gt; 3&#xA;</failure>
+    </testcase>
+    <testcase name="Service/the-service" classname="staging vs production"></testcase>
+    <testcase name="Deployment.apps/the-deployment" classname="staging vs production">
+      <failure message="snapshot diff does not match this diff">--- snapshot diff&#xA;+++ this diff&#xA;@@ -1 +1 @@&#xA;-Deployment.apps/the-deployment spec.replicas: 1 -&gt; 2&#xA;+Deployment.apps/the-deployment spec.replicas: 1 -&gt; 3&#xA;</failure>
+    </testcase>
+  </testsuite>
+</testsuites>
+`
+	if got := readFileOrEmpty("report.xml", fs); got != wantJUnit {
+		t.Errorf("testOptions.Run() junit report = %v, want %v", got, wantJUnit)
+	}
+
+	wantJSON := `{
+  "passed": 0,
+  "failed": 1,
+  "updated": 0,
+  "errors": 0,
+  "comparisons": [
+    {
+      "main": "staging",
+      "comparison": "production",
+      "snapshotFile": "kyml-snapshot.diff",
+      "status": "failed",
+      "snapshotDiff": "--- snapshot diff\n+++ this diff\n@@ -3 +3 @@\n-Deployment.apps/the-deployment spec.replicas: 1 -> 2\n+Deployment.apps/the-deployment spec.replicas: 1 -> 3\n",
+      "resources": [
+        {
+          "name": "Service/the-service",
+          "status": "passed"
+        },
+        {
+          "name": "Deployment.apps/the-deployment",
+          "status": "failed",
+          "snapshotDiff": "--- snapshot diff\n+++ this diff\n@@ -1 +1 @@\n-Deployment.apps/the-deployment spec.replicas: 1 -> 2\n+Deployment.apps/the-deployment spec.replicas: 1 -> 3\n"
+        }
+      ]
+    }
+  ]
+}
+`
+	if got := readFileOrEmpty("report.json", fs); got != wantJSON {
+		t.Errorf("testOptions.Run() json report = %v, want %v", got, wantJSON)
+	}
+}
+
+func Test_validateReport(t *testing.T) {
+	tests := []struct {
+		name    string
+		report  string
+		wantErr bool
+	}{
+		{name: "junit", report: "junit=report.xml", wantErr: false},
+		{name: "json", report: "json=report.json", wantErr: false},
+		{name: "missing path", report: "junit=", wantErr: true},
+		{name: "missing format", report: "report.xml", wantErr: true},
+		{name: "unknown format", report: "tap=report.tap", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateReport(tt.report); (err != nil) != tt.wantErr {
+				t.Errorf("validateReport() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
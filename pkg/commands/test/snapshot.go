@@ -0,0 +1,522 @@
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/frigus02/kyml/pkg/diff"
+	"github.com/frigus02/kyml/pkg/fs"
+	"github.com/frigus02/kyml/pkg/k8syaml"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// snapshotTest compares the diff between two environments to a snapshot.
+type snapshotTest struct {
+	nameMain       string
+	docsMain       []*unstructured.Unstructured
+	nameComparison string
+	docsComparison []*unstructured.Unstructured
+	snapshotFile   string
+	snapshotDir    string
+	diffMode       string
+	ignoreFile     string
+	// migrate migrates snapshot files to the current format version.
+	migrate bool
+}
+
+func (t snapshotTest) String() string {
+	return fmt.Sprintf("%s vs %s", t.nameMain, t.nameComparison)
+}
+
+// location returns the snapshot file or directory of the test.
+func (t snapshotTest) location() string {
+	if t.snapshotDir != "" {
+		return t.snapshotDir
+	}
+
+	return t.snapshotFile
+}
+
+// snapshotResult is the result of a snapshot test.
+type snapshotResult struct {
+	// snapshot is the content of the snapshot file after a potential update.
+	snapshot string
+	// diff is the diff between the environments.
+	diff string
+	// lineResources contains the name of the resource every line of diff
+	// belongs to.
+	lineResources []string
+	// snapshotDiff is the diff between the snapshot and this diff. It is
+	// empty if they match, which includes snapshots updated to this diff.
+	snapshotDiff string
+	// updated is true if the snapshot file was written.
+	updated bool
+	// resources contains the result for every resource. It is only available
+	// for structural diffs, where every line belongs to a resource, and for
+	// snapshot directories.
+	resources []resourceResult
+	// files contains the result for every file in a snapshot directory.
+	files []fileResult
+}
+
+type fileResult struct {
+	file string
+	// stale is true if the resource of the snapshot file no longer exists.
+	stale  bool
+	result snapshotResult
+}
+
+type resourceResult struct {
+	name         string
+	snapshotDiff string
+}
+
+// updateFunc returns the new content of a snapshot, which doesn't match this
+// diff. The result contains the current snapshot and this diff.
+type updateFunc func(t snapshotTest, result snapshotResult) (string, error)
+
+// acceptAll is an updateFunc, which replaces the snapshot with this diff.
+func acceptAll(_ snapshotTest, result snapshotResult) (string, error) {
+	return result.diff, nil
+}
+
+// run creates the diff between the environments and compares it to the
+// snapshot. If update is not nil, it is called for a non-matching snapshot and
+// the snapshot is overwritten with the returned content.
+func (t snapshotTest) run(update updateFunc, fs fs.Filesystem) (snapshotResult, error) {
+	var ignoreRules []diff.IgnoreRule
+	if t.ignoreFile != "" {
+		data, err := fs.ReadFile(t.ignoreFile)
+		if err != nil {
+			return snapshotResult{}, fmt.Errorf("cannot open ignore file: %v", err)
+		}
+
+		if ignoreRules, err = diff.ParseIgnoreRules(data); err != nil {
+			return snapshotResult{}, fmt.Errorf("error parsing ignore file: %v", err)
+		}
+	}
+
+	docsMain := diff.Ignore(t.docsMain, ignoreRules)
+	docsComparison := diff.Ignore(t.docsComparison, ignoreRules)
+	if t.snapshotDir != "" {
+		return t.runDir(docsMain, docsComparison, update, fs)
+	}
+
+	diffStr, err := t.diff(docsMain, docsComparison)
+	if err != nil {
+		return snapshotResult{}, err
+	}
+
+	lineResources, err := t.lineResources(diffStr, docsMain, docsComparison)
+	if err != nil {
+		return snapshotResult{}, err
+	}
+
+	result, err := t.compare(diffStr, lineResources, update, fs)
+	if err != nil {
+		return snapshotResult{}, err
+	}
+
+	if t.diffMode == "structural" {
+		result.resources, err = t.resourceResults(result.snapshot, diffStr)
+		if err != nil {
+			return snapshotResult{}, err
+		}
+	}
+
+	return result, nil
+}
+
+// compare compares the diff to the body of the snapshot file and updates it
+// if requested. Updates and "--migrate" write the snapshot with the current
+// header. In a snapshot directory a missing file is an empty snapshot and an
+// empty snapshot is written by removing the file.
+func (t snapshotTest) compare(diffStr string, lineResources []string, update updateFunc, fs fs.Filesystem) (snapshotResult, error) {
+	snapshotBytes, err := fs.ReadFile(t.snapshotFile)
+	exists := err == nil
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return snapshotResult{}, fmt.Errorf("cannot open snapshot file: %v", err)
+		}
+
+		if update == nil && t.snapshotDir == "" {
+			return snapshotResult{}, fmt.Errorf("snapshot file does not exist\nRun the command with --update to create it")
+		}
+	}
+
+	content := string(snapshotBytes)
+	header, body, err := parseSnapshot(content)
+	if err != nil {
+		return snapshotResult{}, fmt.Errorf("cannot parse snapshot file: %v", err)
+	}
+
+	// Snapshots are compared after migrating them to the current format
+	// version. If only the migration makes them match, the mismatch is caused
+	// by a change in kyml and not in the environments.
+	migrated, err := migrateSnapshot(header, body)
+	if err != nil {
+		return snapshotResult{}, err
+	}
+
+	write := update != nil || (t.migrate && exists)
+	if exists && !write {
+		if migrated != body && migrated == diffStr {
+			return snapshotResult{}, fmt.Errorf("snapshot file uses format version %d, but this version of kyml uses format version %d\nRun the command with --migrate to migrate it", header.version, snapshotFormatVersion)
+		}
+
+		if migrated != diffStr {
+			if err := t.checkOptions(header); err != nil {
+				return snapshotResult{}, err
+			}
+		}
+	}
+
+	body = migrated
+	result := snapshotResult{snapshot: body, diff: diffStr, lineResources: lineResources}
+	if body != diffStr && update != nil {
+		if body, err = update(t, result); err != nil {
+			return snapshotResult{}, err
+		}
+	}
+
+	if write {
+		if result.updated, err = t.writeSnapshot(content, exists, body, fs); err != nil {
+			return snapshotResult{}, err
+		}
+
+		result.snapshot = body
+	}
+
+	result.snapshotDiff, err = diff.Diff(
+		"snapshot diff", result.snapshot,
+		"this diff", diffStr, 0)
+	if err != nil {
+		return snapshotResult{}, err
+	}
+
+	return result, nil
+}
+
+// writeSnapshot writes the body with the current header to the snapshot file,
+// unless it already has this content. It returns true if the file changed.
+func (t snapshotTest) writeSnapshot(content string, exists bool, body string, fs fs.Filesystem) (bool, error) {
+	if t.snapshotDir != "" && body == "" {
+		if !exists {
+			return false, nil
+		}
+
+		if err := fs.Remove(t.snapshotFile); err != nil {
+			return false, fmt.Errorf("cannot remove snapshot file: %v", err)
+		}
+
+		return true, nil
+	}
+
+	newContent := t.header().String() + body
+	if exists && newContent == content {
+		return false, nil
+	}
+
+	if t.snapshotDir != "" {
+		if err := fs.MkdirAll(t.snapshotDir, 0755); err != nil {
+			return false, fmt.Errorf("cannot create snapshot directory: %v", err)
+		}
+	}
+
+	return true, fs.WriteFile(t.snapshotFile, []byte(newContent), 0644)
+}
+
+// runDir compares the diff of every resource to a separate snapshot file in
+// the snapshot directory. Only resources, which differ between the
+// environments, have a snapshot file. Files of resources, which no longer
+// exist in either environment, are stale. Updates remove them.
+func (t snapshotTest) runDir(docsMain, docsComparison []*unstructured.Unstructured, update updateFunc, fs fs.Filesystem) (snapshotResult, error) {
+	var files []string
+	resources := make(map[string]string)
+	byFile := make(map[string][2][]*unstructured.Unstructured)
+	for i, docs := range [][]*unstructured.Unstructured{docsMain, docsComparison} {
+		for _, doc := range docs {
+			file := filepath.Join(t.snapshotDir, snapshotFileName(doc))
+			if _, ok := byFile[file]; !ok {
+				files = append(files, file)
+				resources[file] = diff.StructuralResource(doc)
+			}
+
+			docsByEnv := byFile[file]
+			docsByEnv[i] = append(docsByEnv[i], doc)
+			byFile[file] = docsByEnv
+		}
+	}
+
+	existing, err := fs.Glob(filepath.Join(t.snapshotDir, "*.diff"))
+	if err != nil {
+		return snapshotResult{}, err
+	}
+
+	var result snapshotResult
+	seen := make(map[string]bool)
+	for _, file := range append(files, existing...) {
+		if seen[file] {
+			continue
+		}
+		seen[file] = true
+
+		sub := t
+		sub.snapshotFile = file
+
+		docsByEnv, ok := byFile[file]
+		diffStr, err := sub.diff(docsByEnv[0], docsByEnv[1])
+		if err != nil {
+			return snapshotResult{}, err
+		}
+
+		lineResources, err := sub.lineResources(diffStr, docsByEnv[0], docsByEnv[1])
+		if err != nil {
+			return snapshotResult{}, err
+		}
+
+		fileResult := fileResult{file: file, stale: !ok}
+		fileResult.result, err = sub.compare(diffStr, lineResources, update, fs)
+		if err != nil {
+			return snapshotResult{}, fmt.Errorf("%s: %v", file, err)
+		}
+
+		name := resources[file]
+		if fileResult.stale {
+			name = filepath.Base(file)
+		}
+
+		result.files = append(result.files, fileResult)
+		result.resources = append(result.resources, resourceResult{name: name, snapshotDiff: fileResult.result.snapshotDiff})
+		snapshotDiff, err := diff.Diff(file, fileResult.result.snapshot, fileResult.nameB(), diffStr, 0)
+		if err != nil {
+			return snapshotResult{}, err
+		}
+
+		result.snapshotDiff += snapshotDiff
+		result.updated = result.updated || fileResult.result.updated
+	}
+
+	return result, nil
+}
+
+func (f fileResult) nameB() string {
+	if f.stale {
+		return "this diff (resource no longer exists)"
+	}
+
+	return "this diff"
+}
+
+// snapshotFileName returns the name of the snapshot file for the document in
+// a snapshot directory, e.g. "Deployment.apps_default_app.diff". Kinds,
+// groups and namespaces cannot contain underscores, so they are used as
+// separator. Names are escaped, because names of some resources like RBAC
+// roles can contain any character except "/" and "%".
+func snapshotFileName(doc *unstructured.Unstructured) string {
+	gvk := doc.GroupVersionKind()
+	kind := gvk.Kind
+	if gvk.Group != "" {
+		kind += "." + gvk.Group
+	}
+
+	if namespace := doc.GetNamespace(); namespace != "" {
+		return kind + "_" + namespace + "_" + escapeFileName(doc.GetName()) + ".diff"
+	}
+
+	return kind + "_" + escapeFileName(doc.GetName()) + ".diff"
+}
+
+// escapeFileName percent-encodes all characters except lowercase letters,
+// digits, "-" and ".". The result is a valid file name on all platforms and
+// unique on case-insensitive file systems. Names cannot contain "%", so the
+// escaping is unambiguous.
+func escapeFileName(name string) string {
+	var sb strings.Builder
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-' || c == '.' {
+			sb.WriteByte(c)
+		} else {
+			fmt.Fprintf(&sb, "%%%02X", c)
+		}
+	}
+
+	return sb.String()
+}
+
+func (t snapshotTest) diff(docsMain, docsComparison []*unstructured.Unstructured) (string, error) {
+	if t.diffMode == "structural" {
+		return diff.Structural(t.nameMain, docsMain, t.nameComparison, docsComparison), nil
+	}
+
+	var bufferMain bytes.Buffer
+	if err := k8syaml.Encode(&bufferMain, docsMain); err != nil {
+		return "", err
+	}
+
+	var bufferComparison bytes.Buffer
+	if err := k8syaml.Encode(&bufferComparison, docsComparison); err != nil {
+		return "", err
+	}
+
+	return diff.Diff(t.nameMain, bufferMain.String(), t.nameComparison, bufferComparison.String(), 0)
+}
+
+// resourceResults compares the lines of the structural snapshot and this
+// structural diff separately for every resource in both environments.
+func (t snapshotTest) resourceResults(snapshot, diffStr string) ([]resourceResult, error) {
+	var names []string
+	seen := make(map[string]bool)
+	addName := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	for _, docs := range [][]*unstructured.Unstructured{t.docsMain, t.docsComparison} {
+		for _, doc := range docs {
+			addName(diff.StructuralResource(doc))
+		}
+	}
+
+	snapshotLines := structuralLinesByResource(snapshot, addName)
+	diffLines := structuralLinesByResource(diffStr, addName)
+
+	results := make([]resourceResult, 0, len(names))
+	for _, name := range names {
+		snapshotDiff, err := diff.Diff(
+			"snapshot diff", strings.Join(snapshotLines[name], ""),
+			"this diff", strings.Join(diffLines[name], ""), 0)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, resourceResult{name: name, snapshotDiff: snapshotDiff})
+	}
+
+	return results, nil
+}
+
+// structuralLinesByResource groups the lines of a structural diff by the
+// resource they belong to.
+func structuralLinesByResource(diffStr string, addName func(string)) map[string][]string {
+	lines := make(map[string][]string)
+	for _, line := range strings.SplitAfter(diffStr, "\n") {
+		name := diff.StructuralLineResource(line)
+		if name == "" {
+			continue
+		}
+
+		addName(name)
+		lines[name] = append(lines[name], line)
+	}
+
+	return lines
+}
+
+// render returns the difference between the snapshot and this diff for
+// humans. Hunks are annotated with the resource they belong to.
+func (r snapshotResult) render(context int, color bool) string {
+	if r.files != nil {
+		var sb strings.Builder
+		for _, f := range r.files {
+			sb.WriteString(f.result.renderNamed(f.file, f.nameB(), context, color))
+		}
+
+		return sb.String()
+	}
+
+	return r.renderNamed("snapshot diff", "this diff", context, color)
+}
+
+func (r snapshotResult) renderNamed(nameA, nameB string, context int, color bool) string {
+	return diff.Render(nameA, r.snapshot, nameB, r.diff, diff.RenderOptions{
+		Context: context,
+		Color:   color,
+		Header:  r.header,
+	})
+}
+
+// header returns the resource the specified line of this diff belongs to.
+func (r snapshotResult) header(line int) string {
+	if line < len(r.lineResources) {
+		return r.lineResources[line]
+	}
+
+	return ""
+}
+
+var hunkHeaderRegexp = regexp.MustCompile(`^@@ -(\d+)(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// lineResources returns the name of the resource every line of the diff
+// between the environments belongs to. For unified diffs the line numbers in
+// hunk headers are mapped to the documents in the encoded environments.
+func (t snapshotTest) lineResources(diffStr string, docsMain, docsComparison []*unstructured.Unstructured) ([]string, error) {
+	lines := strings.SplitAfter(diffStr, "\n")
+	result := make([]string, len(lines))
+
+	if t.diffMode == "structural" {
+		for i, line := range lines {
+			result[i] = diff.StructuralLineResource(line)
+		}
+
+		return result, nil
+	}
+
+	resourcesMain, err := k8syaml.LineResources(docsMain)
+	if err != nil {
+		return nil, err
+	}
+
+	resourcesComparison, err := k8syaml.LineResources(docsComparison)
+	if err != nil {
+		return nil, err
+	}
+
+	resourceAt := func(resources []string, line int) string {
+		if line >= 1 && line <= len(resources) {
+			return resources[line-1]
+		}
+
+		return ""
+	}
+
+	lineMain, lineComparison := 0, 0
+	for i, line := range lines {
+		if i < 2 {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			match := hunkHeaderRegexp.FindStringSubmatch(line)
+			if match == nil {
+				continue
+			}
+
+			lineMain, _ = strconv.Atoi(match[1])
+			lineComparison, _ = strconv.Atoi(match[2])
+			result[i] = resourceAt(resourcesMain, lineMain)
+		case strings.HasPrefix(line, "-"):
+			result[i] = resourceAt(resourcesMain, lineMain)
+			lineMain++
+		case strings.HasPrefix(line, "+"):
+			result[i] = resourceAt(resourcesComparison, lineComparison)
+			lineComparison++
+		case strings.HasPrefix(line, " "):
+			result[i] = resourceAt(resourcesMain, lineMain)
+			lineMain++
+			lineComparison++
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,34 @@
+package test
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func Test_snapshotFileName(t *testing.T) {
+	tests := []struct {
+		apiVersion string
+		kind       string
+		namespace  string
+		name       string
+		want       string
+	}{
+		{apiVersion: "v1", kind: "Service", name: "app", want: "Service_app.diff"},
+		{apiVersion: "apps/v1", kind: "Deployment", namespace: "default", name: "app", want: "Deployment.apps_default_app.diff"},
+		{apiVersion: "example.com/v1", kind: "Deployment", namespace: "default", name: "app", want: "Deployment.example.com_default_app.diff"},
+		{apiVersion: "rbac.authorization.k8s.io/v1", kind: "ClusterRole", name: "system:controller:foo", want: "ClusterRole.rbac.authorization.k8s.io_system%3Acontroller%3Afoo.diff"},
+		{apiVersion: "rbac.authorization.k8s.io/v1", kind: "Role", namespace: "a", name: "b_c", want: "Role.rbac.authorization.k8s.io_a_b%5Fc.diff"},
+		{apiVersion: "rbac.authorization.k8s.io/v1", kind: "Role", name: "Admin", want: "Role.rbac.authorization.k8s.io_%41dmin.diff"},
+	}
+	for _, tt := range tests {
+		doc := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		doc.SetAPIVersion(tt.apiVersion)
+		doc.SetKind(tt.kind)
+		doc.SetNamespace(tt.namespace)
+		doc.SetName(tt.name)
+		if got := snapshotFileName(doc); got != tt.want {
+			t.Errorf("snapshotFileName() = %v, want %v", got, tt.want)
+		}
+	}
+}
@@ -1,7 +1,6 @@
 package test
 
 import (
-	"bytes"
 	"fmt"
 	"io"
 	"os"
@@ -9,15 +8,28 @@ import (
 	"github.com/frigus02/kyml/pkg/cat"
 	"github.com/frigus02/kyml/pkg/diff"
 	"github.com/frigus02/kyml/pkg/fs"
+	"github.com/frigus02/kyml/pkg/k8syaml"
 	"github.com/spf13/cobra"
 )
 
 type testOptions struct {
+	color          string
+	configFile     string
+	context        int
 	files          []string
+	diffMode       string
+	ignoreFile     string
+	interactive    bool
+	migrate        bool
 	nameComparison string
 	nameMain       string
+	promptIn       io.Reader
+	promptOut      io.Writer
+	reports        []string
+	snapshotDir    string
 	snapshotFile   string
 	updateSnapshot bool
+	useColor       bool
 }
 
 // NewCmdTest creates a new test command.
@@ -25,7 +37,7 @@ func NewCmdTest(in io.Reader, out io.Writer, fs fs.Filesystem) *cobra.Command {
 	var o testOptions
 
 	cmd := &cobra.Command{
-		Use:   "test <file>...",
+		Use:   "test (<file>... | --config <file>)",
 		Short: "Run a snapshot test on the diff between Kubernetes YAML files of two environments",
 		Long: `Run a snapshot test on the diff between Kubernetes YAML files of two environments.
 
@@ -35,7 +47,21 @@ The main environment is specified on stdin. Use "kyml cat" to concatenate multip
 
 The comparison environment is specified using filenames. Files are concatenated using the same rules as in "kyml cat".
 
-The command compares the diff between these environments to a previous diff stored in the specified snapshot file. If it matches, it prints the main environment to stdout, so it can be piped into followup commands like "kyml tmpl" or "kubectl apply". If it doesn't match, it prints the diff to stderr and exits with a non-zero exit code.`,
+The command compares the diff between these environments to a previous diff stored in the specified snapshot file. If it matches, it prints the main environment to stdout, so it can be piped into followup commands like "kyml tmpl" or "kubectl apply". If it doesn't match, it prints the difference between the snapshot and this diff to stderr and exits with a non-zero exit code. Every hunk is annotated with the resource it belongs to. The number of context lines can be changed using "--context". Colors are used if stderr is a terminal, which can be changed using "--color".
+
+To avoid merge conflicts when several people change different resources, use "--snapshot-dir" instead of "--snapshot-file". It stores the diff of every resource in a separate file named after its kind, API group, namespace and name, e.g. "Deployment.apps_default_app.diff". Characters in names other than lowercase letters, digits, "-" and "." are percent-encoded. Only resources, which differ between the environments, have a snapshot file. Snapshot files of resources, which no longer exist, are stale and fail the test.
+
+Snapshot files start with a header, which records the snapshot format version and the options used to create the diff. If a snapshot only doesn't match because it was created by an older version of kyml, which encoded or sorted documents differently, the command reports this and "--migrate" converts the snapshot to the current format. If a non-matching snapshot was created with different options, e.g. another diff mode, the command reports this instead of the difference.
+
+Use "--update" to overwrite non-matching snapshots with this diff and remove stale snapshot files. Use "--interactive" instead to review every change one by one. Only accepted changes are written to the snapshot. Rejected changes keep failing the test.
+
+By default the diff is a line based unified diff of the YAML files. With "--diff-mode structural" resources are matched by group, kind, namespace and name and every change is reported with the resource and field path, e.g. "Deployment.apps/app spec.replicas: 3 -> 1". Spaces in resource names are written as "%20". This diff doesn't change when unrelated fields or named list items like containers move.
+
+Fields, which are expected to differ between the environments, can be excluded from the diff using an ignore file. Ignore rules select documents by apiVersion, kind, namespace and name using wildcard patterns and list field paths, which are removed from both environments before diffing.
+
+Results can additionally be written as machine readable reports using "--report format=path". Supported formats are junit and json. Each comparison is reported with the snapshot diff and, for structural diffs, the result of every resource.
+
+To test many environments at once, declare them in a config file and specify it using "--config". Every environment is a list of files or glob patterns. Every comparison names two environments and the snapshot file or directory, and can override the diff mode and ignore file. Relative paths are relative to the config file. All comparisons run in one process and a summary is printed to stdout. The command fails if any snapshot doesn't match. With "--update" all non-matching snapshots are updated.`,
 		Example: `  # Make sure production and staging don't drift apart unknowingly
   kyml cat production/* | kyml test staging/* \
     --name-main production \
@@ -47,23 +73,113 @@ The command compares the diff between these environments to a previous diff stor
     --name-main production \
     --name-staging staging \
     --snapshot-file tests/prod-vs-staging.diff \
-    --update`,
+    --update
+
+  # Review every change and update the snapshot with accepted changes only
+  kyml cat production/* | kyml test staging/* \
+    --snapshot-file tests/prod-vs-staging.diff \
+    --interactive
+
+  # Store one snapshot file per resource
+  kyml cat production/* | kyml test staging/* \
+    --snapshot-dir tests/prod-vs-staging
+
+  # Use a structural diff, which reports changes by resource and field path
+  kyml cat production/* | kyml test staging/* \
+    --diff-mode structural \
+    --snapshot-file tests/prod-vs-staging.diff
+
+  # Ignore expected differences
+  kyml cat production/* | kyml test staging/* \
+    --ignore-file tests/prod-vs-staging-ignore.yaml \
+    --snapshot-file tests/prod-vs-staging.diff
+
+  # Write a JUnit report for the CI system
+  kyml cat production/* | kyml test staging/* \
+    --snapshot-file tests/prod-vs-staging.diff \
+    --report junit=reports/kyml-test.xml
+
+  # Test all comparisons declared in a config file
+  kyml test --config kyml-test.yaml
+
+  # Example config file
+  environments:
+    production:
+      files: [production/*.yaml]
+    staging:
+      files: [staging/*.yaml]
+    dev:
+      files: [dev/*.yaml]
+  comparisons:
+  - main: production
+    comparison: staging
+    snapshotFile: tests/prod-vs-staging.diff
+  - main: staging
+    comparison: dev
+    snapshotFile: tests/staging-vs-dev.diff
+    diffMode: structural
+    ignoreFile: tests/staging-vs-dev-ignore.yaml
+
+  # Example ignore file
+  ignore:
+  - match:
+      kind: Deployment
+      name: web-*
+    paths:
+    - spec.replicas
+    - spec.template.spec.containers[*].resources
+  - match:
+      kind: Ingress
+    paths:
+    - spec.rules[*].host`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			err := o.Validate(args)
 			if err != nil {
 				return err
 			}
 
+			output := os.Stderr
+			if o.configFile != "" {
+				output = os.Stdout
+			}
+
+			o.useColor = o.color == "always" || (o.color == "auto" && diff.IsTerminal(output))
+
+			if o.interactive {
+				// Stdin contains the main environment, so questions are
+				// asked using the terminal directly.
+				tty, err := os.Open("/dev/tty")
+				if err != nil {
+					return fmt.Errorf("interactive mode requires a terminal: %v", err)
+				}
+				defer tty.Close()
+
+				o.promptIn = tty
+				o.promptOut = os.Stderr
+			}
+
 			return o.Run(in, out, fs)
 		},
 	}
 
+	cmd.Flags().StringVarP(&o.configFile, "config", "c", "", "Config file declaring environments and comparisons to test")
 	cmd.Flags().StringVar(&o.nameMain, "name-main", "main", "Name of the main environment read from stdin")
 	cmd.Flags().StringVar(&o.nameComparison, "name-comparison", "comparison", "Name of the comparison environment read from files")
 	cmd.Flags().StringVarP(&o.snapshotFile, "snapshot-file", "s", "kyml-snapshot.diff", "Snapshot file")
-	cmd.Flags().BoolVarP(&o.updateSnapshot, "update", "u", false, "If specified, update snapshot file and exit successfully in case of non-match")
+	cmd.Flags().StringVar(&o.snapshotDir, "snapshot-dir", "", "Snapshot directory with one snapshot file per resource. Takes precedence over --snapshot-file")
+	cmd.Flags().StringVar(&o.diffMode, "diff-mode", "unified", "Diff mode (unified or structural)")
+	cmd.Flags().StringVar(&o.ignoreFile, "ignore-file", "", "File with rules for fields, which should be ignored in the diff")
+	cmd.Flags().StringArrayVar(&o.reports, "report", nil, "Write a report in the format format=path, where format is junit or json")
+	cmd.Flags().StringVar(&o.color, "color", "auto", "Color snapshot mismatches (auto, always or never)")
+	cmd.Flags().IntVar(&o.context, "context", 3, "Number of context lines shown around snapshot mismatches")
+	cmd.Flags().BoolVarP(&o.updateSnapshot, "update", "u", false, "If specified, update snapshot files and exit successfully in case of non-match")
+	cmd.Flags().BoolVar(&o.migrate, "migrate", false, "If specified, migrate snapshot files to the current snapshot format version")
+	cmd.Flags().BoolVarP(&o.interactive, "interactive", "i", false, "If specified, review every change of non-matching snapshots and update them with the accepted changes")
 
+	_ = cmd.MarkFlagFilename("config")
 	_ = cmd.MarkFlagFilename("snapshot-file")
+	_ = cmd.MarkFlagDirname("snapshot-dir")
+	_ = cmd.MarkFlagFilename("ignore-file")
 
 	// Test supports infinite positional file arguments, however zsh completions
 	// require each positional argument to be marked individually. We just mark
@@ -79,61 +195,104 @@ The command compares the diff between these environments to a previous diff stor
 
 // Validate validates test command.
 func (o *testOptions) Validate(args []string) error {
-	if len(args) == 0 {
+	if o.configFile != "" {
+		if len(args) != 0 {
+			return fmt.Errorf("files cannot be specified together with --config")
+		}
+	} else if len(args) == 0 {
 		return fmt.Errorf("specify at least one file for the comparison environment")
 	}
 
+	if err := validateDiffMode(o.diffMode); err != nil {
+		return err
+	}
+
+	switch o.color {
+	case "", "auto", "always", "never":
+	default:
+		return fmt.Errorf("invalid color \"%s\" (supported are auto, always and never)", o.color)
+	}
+
+	if o.context < 0 {
+		return fmt.Errorf("context must not be negative")
+	}
+
+	for _, report := range o.reports {
+		if err := validateReport(report); err != nil {
+			return err
+		}
+	}
+
 	o.files = args
 	return nil
 }
 
+func validateDiffMode(diffMode string) error {
+	switch diffMode {
+	case "", "unified", "structural":
+		return nil
+	default:
+		return fmt.Errorf("invalid diff mode \"%s\" (supported are unified and structural)", diffMode)
+	}
+}
+
 // Run runs test command.
 func (o *testOptions) Run(in io.Reader, out io.Writer, fs fs.Filesystem) error {
-	var bufferMain bytes.Buffer
-	if err := cat.Stream(&bufferMain, in); err != nil {
-		return err
+	if o.configFile != "" {
+		return o.runConfig(out, fs)
 	}
 
-	var bufferComparison bytes.Buffer
-	if err := cat.Cat(&bufferComparison, o.files, fs); err != nil {
+	docsMain, err := cat.StreamDecodeOnly(in)
+	if err != nil {
 		return err
 	}
 
-	diffStr, err := diff.Diff(
-		o.nameMain, bufferMain.String(),
-		o.nameComparison, bufferComparison.String())
+	docsComparison, err := cat.CatDecodeOnly(o.files, fs)
 	if err != nil {
 		return err
 	}
 
-	snapshotBytes, err := fs.ReadFile(o.snapshotFile)
-	if err != nil {
-		if !os.IsNotExist(err) {
-			return fmt.Errorf("cannot open snapshot file: %v", err)
-		}
+	t := snapshotTest{
+		nameMain:       o.nameMain,
+		docsMain:       docsMain,
+		nameComparison: o.nameComparison,
+		docsComparison: docsComparison,
+		snapshotFile:   o.snapshotFile,
+		snapshotDir:    o.snapshotDir,
+		diffMode:       o.diffMode,
+		ignoreFile:     o.ignoreFile,
+		migrate:        o.migrate,
+	}
+	if t.snapshotDir != "" {
+		t.snapshotFile = ""
+	}
 
-		if !o.updateSnapshot {
-			return fmt.Errorf("snapshot file does not exist\nRun the command with --update to create it")
-		}
+	result, err := t.run(o.update(), fs)
+	if reportErr := writeReports(o.reports, []reportEntry{{test: t, result: result, err: err}}, fs); reportErr != nil {
+		return reportErr
 	}
 
-	snapshotDiffStr, err := diff.Diff(
-		"snapshot diff", string(snapshotBytes),
-		"this diff", diffStr)
 	if err != nil {
 		return err
 	}
 
-	if snapshotDiffStr != "" {
-		if o.updateSnapshot {
-			if err := fs.WriteFile(o.snapshotFile, []byte(diffStr), 0644); err != nil {
-				return err
-			}
-		} else {
-			return fmt.Errorf("snapshot diff does not match this diff\n\nRun the command with --update to update it\n\n%s", snapshotDiffStr)
-		}
+	if result.snapshotDiff != "" {
+		return fmt.Errorf("snapshot diff does not match this diff\n\nRun the command with --update to update it\n\n%s", result.render(o.context, o.useColor))
+	}
+
+	return k8syaml.Encode(out, docsMain)
+}
+
+// update returns how non-matching snapshots are updated based on the options.
+// It returns nil if they should not be updated.
+func (o *testOptions) update() updateFunc {
+	if o.interactive {
+		return newReviewer(o.promptIn, o.promptOut, o.context, o.useColor).review
+	}
+
+	if o.updateSnapshot {
+		return acceptAll
 	}
 
-	fmt.Fprint(out, bufferMain.String())
 	return nil
 }
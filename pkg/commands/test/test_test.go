@@ -35,6 +35,17 @@ func mustCreateFsWithSnapshot(t *testing.T, snapshot string) fs.Filesystem {
 	return fsWithTestdata
 }
 
+func mustCreateFsWithFiles(t *testing.T, files map[string]string) fs.Filesystem {
+	fsWithTestdata := mustCreateFs(t)
+	for name, data := range files {
+		if err := fsWithTestdata.WriteFile(name, []byte(data), 0644); err != nil {
+			t.Fatalf("error writing %s: %v", name, err)
+		}
+	}
+
+	return fsWithTestdata
+}
+
 func mustCreateStream(t *testing.T, files ...string) io.Reader {
 	var content []byte
 	for _, file := range files {
@@ -64,10 +75,12 @@ func Test_testOptions_Validate(t *testing.T) {
 		args []string
 	}
 	tests := []struct {
-		name      string
-		args      args
-		wantErr   bool
-		wantFiles []string
+		name       string
+		configFile string
+		diffMode   string
+		args       args
+		wantErr    bool
+		wantFiles  []string
 	}{
 		{
 			name: "error if no args",
@@ -85,9 +98,45 @@ func Test_testOptions_Validate(t *testing.T) {
 			wantErr:   false,
 			wantFiles: []string{"foo", "bar", "baz"},
 		},
+		{
+			name:       "error if args and config",
+			configFile: "kyml-test.yaml",
+			args: args{
+				args: []string{"foo"},
+			},
+			wantErr:   true,
+			wantFiles: nil,
+		},
+		{
+			name:       "no args with config",
+			configFile: "kyml-test.yaml",
+			args: args{
+				args: []string{},
+			},
+			wantErr:   false,
+			wantFiles: []string{},
+		},
+		{
+			name:     "error if diff mode is invalid",
+			diffMode: "foo",
+			args: args{
+				args: []string{"foo"},
+			},
+			wantErr:   true,
+			wantFiles: nil,
+		},
+		{
+			name:     "structural diff mode",
+			diffMode: "structural",
+			args: args{
+				args: []string{"foo"},
+			},
+			wantErr:   false,
+			wantFiles: []string{"foo"},
+		},
 	}
 	for _, tt := range tests {
-		o := &testOptions{}
+		o := &testOptions{configFile: tt.configFile, diffMode: tt.diffMode}
 		t.Run(tt.name, func(t *testing.T) {
 			if err := o.Validate(tt.args.args); (err != nil) != tt.wantErr {
 				t.Errorf("testOptions.Validate() error = %v, wantErr %v", err, tt.wantErr)
@@ -164,7 +213,27 @@ func Test_testOptions_Run(t *testing.T) {
 			wantOut:          "",
 			wantSnapshot:     "--- staging\n+++ production\n@@ -19 +19 @@\n-  replicas: 1\n+  replicas: 2\n",
 			wantErr:          true,
-			wantErrToContain: "--- snapshot diff\n+++ this diff\n@@ -5 +5 @@\n-+  replicas: 2\n++  replicas: 3\n",
+			wantErrToContain: "--- snapshot diff\n+++ this diff\n@@ -5 +5 @@ Deployment/the-deployment\n-+  replicas: 2\n++  replicas: 3\n",
+		},
+		{
+			name: "snapshot diff doesn't match with context and color",
+			o: &testOptions{
+				nameMain:       "staging",
+				nameComparison: "production",
+				files:          []string{"testdata/production/deployment.yaml", "testdata/production/service.yaml"},
+				snapshotFile:   "kyml-snapshot.diff",
+				updateSnapshot: false,
+				context:        1,
+				useColor:       true,
+			},
+			args: args{
+				in: mustCreateStream(t, "testdata/staging/deployment.yaml", "testdata/staging/service.yaml"),
+				fs: mustCreateFsWithSnapshot(t, "--- staging\n+++ production\n@@ -19 +19 @@\n-  replicas: 1\n+  replicas: 2\n"),
+			},
+			wantOut:          "",
+			wantSnapshot:     "--- staging\n+++ production\n@@ -19 +19 @@\n-  replicas: 1\n+  replicas: 2\n",
+			wantErr:          true,
+			wantErrToContain: "\x1b[1m--- snapshot diff\x1b[0m\n\x1b[1m+++ this diff\x1b[0m\n\x1b[36m@@ -4,2 +4,2 @@ Deployment/the-deployment\x1b[0m\n -  replicas: 1\n\x1b[31m-+  replicas: 2\x1b[0m\n\x1b[32m++  replicas: 3\x1b[0m\n",
 		},
 		{
 			name: "snapshot diff doesn't match and update requested",
@@ -180,7 +249,7 @@ func Test_testOptions_Run(t *testing.T) {
 				fs: mustCreateFsWithSnapshot(t, "--- staging\n+++ production\n@@ -19 +19 @@\n-  replicas: 1\n+  replicas: 2\n"),
 			},
 			wantOut:      "---\napiVersion: v1\nkind: Service\nmetadata:\n  name: the-service\nspec:\n  ports:\n  - port: 80\n    protocol: TCP\n  selector:\n    deployment: hello\n  type: LoadBalancer\n---\napiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: the-deployment\nspec:\n  replicas: 1\n  template:\n    spec:\n      containers:\n      - image: kyml/hello\n        name: the-container\n",
-			wantSnapshot: "--- staging\n+++ production\n@@ -19 +19 @@\n-  replicas: 1\n+  replicas: 3\n",
+			wantSnapshot: "# kyml snapshot\n# format-version: 1\n# diff-mode: unified\n--- staging\n+++ production\n@@ -19 +19 @@\n-  replicas: 1\n+  replicas: 3\n",
 			wantErr:      false,
 		},
 		{
@@ -200,6 +269,87 @@ func Test_testOptions_Run(t *testing.T) {
 			wantSnapshot: "--- staging\n+++ production\n@@ -19 +19 @@\n-  replicas: 1\n+  replicas: 3\n",
 			wantErr:      false,
 		},
+		{
+			name: "structural snapshot diff doesn't match",
+			o: &testOptions{
+				nameMain:       "staging",
+				nameComparison: "production",
+				files:          []string{"testdata/production/deployment.yaml", "testdata/production/service.yaml"},
+				diffMode:       "structural",
+				snapshotFile:   "kyml-snapshot.diff",
+				updateSnapshot: false,
+			},
+			args: args{
+				in: mustCreateStream(t, "testdata/staging/deployment.yaml", "testdata/staging/service.yaml"),
+				fs: mustCreateFsWithSnapshot(t, "--- staging\n+++ production\nDeployment.apps/the-deployment spec.replicas: 1 -> 2\n"),
+			},
+			wantOut:          "",
+			wantSnapshot:     "--- staging\n+++ production\nDeployment.apps/the-deployment spec.replicas: 1 -> 2\n",
+			wantErr:          true,
+			wantErrToContain: "--- snapshot diff\n+++ this diff\n@@ -3 +3 @@ Deployment.apps/the-deployment\n-Deployment.apps/the-deployment spec.replicas: 1 -> 2\n+Deployment.apps/the-deployment spec.replicas: 1 -> 3\n",
+		},
+		{
+			name: "ignored fields",
+			o: &testOptions{
+				nameMain:       "staging",
+				nameComparison: "production",
+				files:          []string{"testdata/production/deployment.yaml", "testdata/production/service.yaml"},
+				diffMode:       "structural",
+				ignoreFile:     "kyml-ignore.yaml",
+				snapshotFile:   "kyml-snapshot.diff",
+				updateSnapshot: false,
+			},
+			args: args{
+				in: mustCreateStream(t, "testdata/staging/deployment.yaml", "testdata/staging/service.yaml"),
+				fs: mustCreateFsWithFiles(t, map[string]string{
+					"kyml-snapshot.diff": "",
+					"kyml-ignore.yaml":   "ignore:\n- match:\n    kind: Deployment\n  paths:\n  - spec.replicas\n",
+				}),
+			},
+			wantOut:      "---\napiVersion: v1\nkind: Service\nmetadata:\n  name: the-service\nspec:\n  ports:\n  - port: 80\n    protocol: TCP\n  selector:\n    deployment: hello\n  type: LoadBalancer\n---\napiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: the-deployment\nspec:\n  replicas: 1\n  template:\n    spec:\n      containers:\n      - image: kyml/hello\n        name: the-container\n",
+			wantSnapshot: "",
+			wantErr:      false,
+		},
+		{
+			name: "ignore file is invalid",
+			o: &testOptions{
+				nameMain:       "staging",
+				nameComparison: "production",
+				files:          []string{"testdata/production/deployment.yaml", "testdata/production/service.yaml"},
+				ignoreFile:     "kyml-ignore.yaml",
+				snapshotFile:   "kyml-snapshot.diff",
+				updateSnapshot: false,
+			},
+			args: args{
+				in: mustCreateStream(t, "testdata/staging/deployment.yaml", "testdata/staging/service.yaml"),
+				fs: mustCreateFsWithFiles(t, map[string]string{
+					"kyml-snapshot.diff": "",
+					"kyml-ignore.yaml":   "ignore:\n- paths: [spec..replicas]\n",
+				}),
+			},
+			wantOut:          "",
+			wantSnapshot:     "",
+			wantErr:          true,
+			wantErrToContain: "error parsing ignore file",
+		},
+		{
+			name: "structural snapshot diff matches",
+			o: &testOptions{
+				nameMain:       "staging",
+				nameComparison: "production",
+				files:          []string{"testdata/production/deployment.yaml", "testdata/production/service.yaml"},
+				diffMode:       "structural",
+				snapshotFile:   "kyml-snapshot.diff",
+				updateSnapshot: false,
+			},
+			args: args{
+				in: mustCreateStream(t, "testdata/staging/deployment.yaml", "testdata/staging/service.yaml"),
+				fs: mustCreateFsWithSnapshot(t, "--- staging\n+++ production\nDeployment.apps/the-deployment spec.replicas: 1 -> 3\n"),
+			},
+			wantOut:      "---\napiVersion: v1\nkind: Service\nmetadata:\n  name: the-service\nspec:\n  ports:\n  - port: 80\n    protocol: TCP\n  selector:\n    deployment: hello\n  type: LoadBalancer\n---\napiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: the-deployment\nspec:\n  replicas: 1\n  template:\n    spec:\n      containers:\n      - image: kyml/hello\n        name: the-container\n",
+			wantSnapshot: "--- staging\n+++ production\nDeployment.apps/the-deployment spec.replicas: 1 -> 3\n",
+			wantErr:      false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -223,3 +373,100 @@ func Test_testOptions_Run(t *testing.T) {
 		})
 	}
 }
+
+func Test_testOptions_Run_snapshotDir(t *testing.T) {
+	deploymentSnapshot := "# kyml snapshot\n# format-version: 1\n# diff-mode: structural\n--- staging\n+++ production\nDeployment.apps/the-deployment spec.replicas: 1 -> 3\n"
+	staleSnapshot := "--- staging\n+++ production\nConfigMap/old: added\n"
+
+	tests := []struct {
+		name             string
+		update           bool
+		files            map[string]string
+		wantFiles        map[string]string
+		wantErr          bool
+		wantErrToContain string
+	}{
+		{
+			name:             "snapshot files don't exist",
+			files:            map[string]string{},
+			wantFiles:        map[string]string{},
+			wantErr:          true,
+			wantErrToContain: "--- snapshots/Deployment.apps_the-deployment.diff\n+++ this diff\n",
+		},
+		{
+			name:   "snapshot files don't exist and update requested",
+			update: true,
+			files:  map[string]string{},
+			wantFiles: map[string]string{
+				"snapshots/Deployment.apps_the-deployment.diff": deploymentSnapshot,
+			},
+		},
+		{
+			name: "snapshot files match",
+			files: map[string]string{
+				"snapshots/Deployment.apps_the-deployment.diff": deploymentSnapshot,
+			},
+			wantFiles: map[string]string{
+				"snapshots/Deployment.apps_the-deployment.diff": deploymentSnapshot,
+			},
+		},
+		{
+			name: "stale snapshot file",
+			files: map[string]string{
+				"snapshots/Deployment.apps_the-deployment.diff": deploymentSnapshot,
+				"snapshots/ConfigMap_old.diff":                  staleSnapshot,
+			},
+			wantFiles: map[string]string{
+				"snapshots/Deployment.apps_the-deployment.diff": deploymentSnapshot,
+				"snapshots/ConfigMap_old.diff":                  staleSnapshot,
+			},
+			wantErr:          true,
+			wantErrToContain: "--- snapshots/ConfigMap_old.diff\n+++ this diff (resource no longer exists)\n",
+		},
+		{
+			name:   "stale snapshot file and update requested",
+			update: true,
+			files: map[string]string{
+				"snapshots/Deployment.apps_the-deployment.diff": deploymentSnapshot,
+				"snapshots/ConfigMap_old.diff":                  staleSnapshot,
+				"snapshots/Service_the-service.diff":            "--- staging\n+++ production\nService/the-service spec.type: \"ClusterIP\" -> \"LoadBalancer\"\n",
+			},
+			wantFiles: map[string]string{
+				"snapshots/Deployment.apps_the-deployment.diff": deploymentSnapshot,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &testOptions{
+				nameMain:       "staging",
+				nameComparison: "production",
+				files:          []string{"testdata/production/deployment.yaml", "testdata/production/service.yaml"},
+				diffMode:       "structural",
+				snapshotDir:    "snapshots",
+				updateSnapshot: tt.update,
+			}
+			in := mustCreateStream(t, "testdata/staging/deployment.yaml", "testdata/staging/service.yaml")
+			fs := mustCreateFsWithFiles(t, tt.files)
+
+			err := o.Run(in, &bytes.Buffer{}, fs)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("testOptions.Run() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil && !strings.Contains(err.Error(), tt.wantErrToContain) {
+				t.Errorf("testOptions.Run() error = %v, wantErrToContain %v", err, tt.wantErrToContain)
+				return
+			}
+
+			gotFiles := make(map[string]string)
+			matches, _ := fs.Glob("snapshots/*")
+			for _, file := range matches {
+				gotFiles[file] = readFileOrEmpty(file, fs)
+			}
+			if !reflect.DeepEqual(gotFiles, tt.wantFiles) {
+				t.Errorf("testOptions.Run() files = %v, want %v", gotFiles, tt.wantFiles)
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+package tmpl
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// funcMap contains the functions available in templates. They are
+// deliberately limited to pure functions on values. There are no functions
+// for reading files, environment variables or network resources.
+//
+// Functions take the value they operate on as last argument, so they can be
+// used in pipelines, e.g. {{.name | trunc 63 | quote}}.
+var funcMap = template.FuncMap{
+	// Defaults and validation
+	"default":  defaultValue,
+	"required": required,
+	"coalesce": coalesce,
+	"empty":    isEmpty,
+
+	// Strings
+	"upper":      func(s interface{}) string { return strings.ToUpper(toString(s)) },
+	"lower":      func(s interface{}) string { return strings.ToLower(toString(s)) },
+	"trim":       func(s interface{}) string { return strings.TrimSpace(toString(s)) },
+	"trimPrefix": func(prefix string, s interface{}) string { return strings.TrimPrefix(toString(s), prefix) },
+	"trimSuffix": func(suffix string, s interface{}) string { return strings.TrimSuffix(toString(s), suffix) },
+	"replace":    func(old, new string, s interface{}) string { return strings.ReplaceAll(toString(s), old, new) },
+	"trunc":      trunc,
+	"contains":   func(substr string, s interface{}) bool { return strings.Contains(toString(s), substr) },
+	"hasPrefix":  func(prefix string, s interface{}) bool { return strings.HasPrefix(toString(s), prefix) },
+	"hasSuffix":  func(suffix string, s interface{}) bool { return strings.HasSuffix(toString(s), suffix) },
+	"quote":      func(s interface{}) string { return strconv.Quote(toString(s)) },
+	"squote":     func(s interface{}) string { return "'" + toString(s) + "'" },
+	"join":       join,
+
+	// Encoding
+	"b64enc":    func(s interface{}) string { return base64.StdEncoding.EncodeToString([]byte(toString(s))) },
+	"b64dec":    b64dec,
+	"sha256sum": sha256sum,
+	"toJson":    toJSON,
+
+	// Type conversions. See typedValueFunc for how they produce typed values.
+	"int":   toInt,
+	"float": toFloat,
+	"bool":  toBool,
+}
+
+func toString(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case []byte:
+		return string(value)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// typeName returns a description of the type of the value for error messages.
+// Errors must not include values, since they can be secret.
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		return "boolean"
+	case string, []byte:
+		return "string"
+	case int, int64, float64, json.Number:
+		return "number"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "list"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// isEmpty returns true for nil, false, 0 and empty strings, lists and objects.
+func isEmpty(value interface{}) bool {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.String, reflect.Array, reflect.Map, reflect.Slice:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
+
+// defaultValue returns the value or, if it is empty, the default.
+func defaultValue(def interface{}, value ...interface{}) interface{} {
+	if len(value) == 0 || isEmpty(value[0]) {
+		return def
+	}
+
+	return value[0]
+}
+
+// required returns the value or fails with the message if it is empty.
+func required(message string, value interface{}) (interface{}, error) {
+	if isEmpty(value) {
+		return nil, fmt.Errorf("%s", message)
+	}
+
+	return value, nil
+}
+
+// coalesce returns the first value, which is not empty.
+func coalesce(values ...interface{}) interface{} {
+	for _, value := range values {
+		if !isEmpty(value) {
+			return value
+		}
+	}
+
+	return nil
+}
+
+// trunc shortens the string to the specified number of characters. A
+// negative length keeps the characters at the end of the string.
+func trunc(length int, s interface{}) string {
+	runes := []rune(toString(s))
+	switch {
+	case length >= 0 && len(runes) > length:
+		return string(runes[:length])
+	case length < 0 && len(runes) > -length:
+		return string(runes[len(runes)+length:])
+	default:
+		return string(runes)
+	}
+}
+
+func join(sep string, list interface{}) (string, error) {
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return "", fmt.Errorf("join expects a list, got %T", list)
+	}
+
+	parts := make([]string, v.Len())
+	for i := range parts {
+		parts[i] = toString(v.Index(i).Interface())
+	}
+
+	return strings.Join(parts, sep), nil
+}
+
+func b64dec(s interface{}) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(toString(s))
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+func sha256sum(s interface{}) string {
+	sum := sha256.Sum256([]byte(toString(s)))
+	return hex.EncodeToString(sum[:])
+}
+
+func toJSON(value interface{}) (string, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(value); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// toInt converts numbers and strings containing integers to int64. Floats are
+// only accepted if they don't have a fractional part.
+func toInt(value interface{}) (int64, error) {
+	switch value := value.(type) {
+	case int:
+		return int64(value), nil
+	case int64:
+		return value, nil
+	case float64:
+		if value == math.Trunc(value) && math.Abs(value) < math.MaxInt64 {
+			return int64(value), nil
+		}
+	case bool, nil:
+	default:
+		if i, err := strconv.ParseInt(strings.TrimSpace(toString(value)), 10, 64); err == nil {
+			return i, nil
+		}
+	}
+
+	return 0, fmt.Errorf("cannot convert %s to an integer", typeName(value))
+}
+
+// toFloat converts numbers and strings containing numbers to float64.
+func toFloat(value interface{}) (float64, error) {
+	switch value := value.(type) {
+	case int:
+		return float64(value), nil
+	case int64:
+		return float64(value), nil
+	case float64:
+		return value, nil
+	case bool, nil:
+	default:
+		if f, err := strconv.ParseFloat(strings.TrimSpace(toString(value)), 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+			return f, nil
+		}
+	}
+
+	return 0, fmt.Errorf("cannot convert %s to a number", typeName(value))
+}
+
+// toBool converts booleans and the strings "true" and "false" to bool.
+func toBool(value interface{}) (bool, error) {
+	switch value := value.(type) {
+	case bool:
+		return value, nil
+	case string:
+		switch strings.TrimSpace(value) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+	}
+
+	return false, fmt.Errorf("cannot convert %s to a boolean", typeName(value))
+}
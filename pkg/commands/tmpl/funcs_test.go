@@ -0,0 +1,91 @@
+package tmpl
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func Test_funcMap(t *testing.T) {
+	vars := map[string]interface{}{
+		"name":     "my-very-long-feature-branch-name",
+		"empty":    "",
+		"replicas": json.Number("3"),
+		"zero":     false,
+		"list":     []interface{}{"a", "b", json.Number("1")},
+		"object":   map[string]interface{}{"key": "value <&>"},
+		"encoded":  "aGVsbG8=",
+	}
+
+	tests := []struct {
+		name             string
+		text             string
+		want             string
+		wantErrToContain string
+	}{
+		{name: "default with value", text: `{{.name | default "main"}}`, want: "my-very-long-feature-branch-name"},
+		{name: "default with empty value", text: `{{.empty | default "main"}}`, want: "main"},
+		{name: "default with false", text: `{{default "yes" .zero}}`, want: "yes"},
+		{name: "default of missing key using index", text: `{{index . "missing" | default "main"}}`, want: "main"},
+		{name: "required with value", text: `{{.name | required "name is required"}}`, want: "my-very-long-feature-branch-name"},
+		{name: "required with empty value", text: `{{.empty | required "empty is required"}}`, wantErrToContain: "empty is required"},
+		{name: "coalesce", text: `{{coalesce .empty .zero .name}}`, want: "my-very-long-feature-branch-name"},
+		{name: "empty", text: `{{empty .empty}} {{empty .name}}`, want: "true false"},
+		{name: "upper", text: `{{upper .name}}`, want: "MY-VERY-LONG-FEATURE-BRANCH-NAME"},
+		{name: "lower", text: `{{"ABC" | lower}}`, want: "abc"},
+		{name: "trim", text: `{{" a b " | trim}}`, want: "a b"},
+		{name: "trimPrefix", text: `{{.name | trimPrefix "my-"}}`, want: "very-long-feature-branch-name"},
+		{name: "trimSuffix", text: `{{.name | trimSuffix "-name"}}`, want: "my-very-long-feature-branch"},
+		{name: "replace", text: `{{.name | replace "-" "_"}}`, want: "my_very_long_feature_branch_name"},
+		{name: "trunc", text: `{{.name | trunc 7}}`, want: "my-very"},
+		{name: "trunc from end", text: `{{.name | trunc -4}}`, want: "name"},
+		{name: "trunc short string", text: `{{.name | trunc 63}}`, want: "my-very-long-feature-branch-name"},
+		{name: "contains", text: `{{.name | contains "feature"}}`, want: "true"},
+		{name: "hasPrefix", text: `{{.name | hasPrefix "my"}}`, want: "true"},
+		{name: "hasSuffix", text: `{{.name | hasSuffix "my"}}`, want: "false"},
+		{name: "quote", text: `{{.replicas | quote}}`, want: `"3"`},
+		{name: "squote", text: `{{.name | squote}}`, want: `'my-very-long-feature-branch-name'`},
+		{name: "join", text: `{{.list | join ","}}`, want: "a,b,1"},
+		{name: "join of non-list", text: `{{.name | join ","}}`, wantErrToContain: "join expects a list"},
+		{name: "b64enc", text: `{{"hello" | b64enc}}`, want: "aGVsbG8="},
+		{name: "b64dec", text: `{{.encoded | b64dec}}`, want: "hello"},
+		{name: "b64dec of invalid data", text: `{{.name | b64dec}}`, wantErrToContain: "illegal base64 data"},
+		{name: "sha256sum", text: `{{"hello" | sha256sum}}`, want: "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"},
+		{name: "int from number", text: `{{.replicas | int}}`, want: "3"},
+		{name: "int from string", text: `{{" 42" | int}}`, want: "42"},
+		{name: "int from float", text: `{{2.0 | int}}`, want: "2"},
+		{name: "int from invalid string", text: `{{.name | int}}`, wantErrToContain: "cannot convert string to an integer"},
+		{name: "int from fraction", text: `{{2.5 | int}}`, wantErrToContain: "cannot convert number to an integer"},
+		{name: "int comparison", text: `{{if gt (.replicas | int) 2}}many{{end}}`, want: "many"},
+		{name: "float", text: `{{"0.25" | float}}`, want: "0.25"},
+		{name: "float from invalid string", text: `{{"NaN" | float}}`, wantErrToContain: "cannot convert string to a number"},
+		{name: "bool", text: `{{"true" | bool}} {{.zero | bool}}`, want: "true false"},
+		{name: "bool from invalid string", text: `{{"yes" | bool}}`, wantErrToContain: "cannot convert string to a boolean"},
+		{name: "toJson", text: `{{.object | toJson}}`, want: `{"key":"value <&>"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := template.New(tt.name).Funcs(funcMap).Option("missingkey=error").Parse(tt.text)
+			if err != nil {
+				t.Fatalf("error parsing template: %v", err)
+			}
+
+			var got bytes.Buffer
+			err = tmpl.Execute(&got, vars)
+			if tt.wantErrToContain != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErrToContain) {
+					t.Errorf("template error = %v, wantErrToContain %v", err, tt.wantErrToContain)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("template error = %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("template = %q, want %q", got.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package tmpl
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/validation/path"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// validateIdentity checks the name and namespace of a document, whose
+// identity was changed by templating. Names must be DNS subdomains, except
+// for RBAC resources, which only need to be valid path segments, e.g.
+// "system:controller". Namespaces must be DNS labels. Names and namespaces
+// produced by templates in secretFields are masked in errors.
+func validateIdentity(doc *unstructured.Unstructured, secretFields map[string]bool) error {
+	name := doc.GetName()
+	if name == "" {
+		return fmt.Errorf("metadata.name is empty after templating")
+	}
+
+	var msgs []string
+	if doc.GroupVersionKind().Group == "rbac.authorization.k8s.io" {
+		msgs = path.IsValidPathSegmentName(name)
+	} else {
+		msgs = validation.IsDNS1123Subdomain(name)
+	}
+	if len(msgs) > 0 {
+		return fmt.Errorf("metadata.name %s is invalid after templating: %s", quoteOutput(name, secretFields["metadata.name"]), strings.Join(msgs, ", "))
+	}
+
+	if namespace := doc.GetNamespace(); namespace != "" {
+		if msgs := validation.IsDNS1123Label(namespace); len(msgs) > 0 {
+			return fmt.Errorf("metadata.namespace %s is invalid after templating: %s", quoteOutput(namespace, secretFields["metadata.namespace"]), strings.Join(msgs, ", "))
+		}
+	}
+
+	return nil
+}
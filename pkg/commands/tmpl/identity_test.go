@@ -0,0 +1,38 @@
+package tmpl
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func Test_validateIdentity(t *testing.T) {
+	tests := []struct {
+		name       string
+		apiVersion string
+		kind       string
+		docName    string
+		namespace  string
+		wantErr    bool
+	}{
+		{name: "valid", apiVersion: "v1", kind: "ConfigMap", docName: "app.config-1", namespace: "production"},
+		{name: "empty name", apiVersion: "v1", kind: "ConfigMap", docName: "", wantErr: true},
+		{name: "uppercase name", apiVersion: "v1", kind: "ConfigMap", docName: "App", wantErr: true},
+		{name: "name with slash", apiVersion: "v1", kind: "ConfigMap", docName: "feature/x", wantErr: true},
+		{name: "rbac name with colon", apiVersion: "rbac.authorization.k8s.io/v1", kind: "ClusterRole", docName: "system:app"},
+		{name: "rbac name with slash", apiVersion: "rbac.authorization.k8s.io/v1", kind: "ClusterRole", docName: "system/app", wantErr: true},
+		{name: "namespace with dot", apiVersion: "v1", kind: "ConfigMap", docName: "app", namespace: "prod.eu", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := &unstructured.Unstructured{Object: map[string]interface{}{}}
+			doc.SetAPIVersion(tt.apiVersion)
+			doc.SetKind(tt.kind)
+			doc.SetName(tt.docName)
+			doc.SetNamespace(tt.namespace)
+			if err := validateIdentity(doc, nil); (err != nil) != tt.wantErr {
+				t.Errorf("validateIdentity() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
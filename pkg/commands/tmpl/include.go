@@ -0,0 +1,45 @@
+package tmpl
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// includeIfAnnotation contains a template, which decides if a document is
+// included in the output. It must evaluate to "true" or "false".
+const includeIfAnnotation = "kyml.io/include-if"
+
+// includeCondition returns the template in the include-if annotation of the
+// document and removes the annotation. It returns false if the document
+// doesn't have the annotation.
+func includeCondition(doc *unstructured.Unstructured) (string, bool) {
+	annotations := doc.GetAnnotations()
+	condition, ok := annotations[includeIfAnnotation]
+	if !ok {
+		return "", false
+	}
+
+	delete(annotations, includeIfAnnotation)
+	if len(annotations) == 0 {
+		unstructured.RemoveNestedField(doc.Object, "metadata", "annotations")
+	} else {
+		doc.SetAnnotations(annotations)
+	}
+
+	return condition, true
+}
+
+// parseCondition parses the result of an include-if template. If the
+// template uses secrets, the result is masked in errors.
+func parseCondition(result interface{}, secret bool) (bool, error) {
+	switch value := strings.TrimSpace(toString(result)); value {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	default:
+		return false, fmt.Errorf("annotation %s must evaluate to true or false, got %s", includeIfAnnotation, quoteOutput(value, secret))
+	}
+}
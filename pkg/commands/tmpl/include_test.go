@@ -0,0 +1,83 @@
+package tmpl
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func Test_includeCondition(t *testing.T) {
+	tests := []struct {
+		name            string
+		annotations     map[string]interface{}
+		wantCondition   string
+		wantOk          bool
+		wantAnnotations map[string]interface{}
+	}{
+		{
+			name:            "no annotations",
+			annotations:     nil,
+			wantCondition:   "",
+			wantOk:          false,
+			wantAnnotations: nil,
+		},
+		{
+			name:            "only include-if",
+			annotations:     map[string]interface{}{"kyml.io/include-if": "{{.a}}"},
+			wantCondition:   "{{.a}}",
+			wantOk:          true,
+			wantAnnotations: nil,
+		},
+		{
+			name:            "other annotations",
+			annotations:     map[string]interface{}{"kyml.io/include-if": "true", "note": "hi"},
+			wantCondition:   "true",
+			wantOk:          true,
+			wantAnnotations: map[string]interface{}{"note": "hi"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata := map[string]interface{}{"name": "a"}
+			if tt.annotations != nil {
+				metadata["annotations"] = tt.annotations
+			}
+			doc := &unstructured.Unstructured{Object: map[string]interface{}{"metadata": metadata}}
+
+			condition, ok := includeCondition(doc)
+			if condition != tt.wantCondition || ok != tt.wantOk {
+				t.Errorf("includeCondition() = %v, %v, want %v, %v", condition, ok, tt.wantCondition, tt.wantOk)
+			}
+
+			annotations, _, _ := unstructured.NestedMap(doc.Object, "metadata", "annotations")
+			if !reflect.DeepEqual(annotations, tt.wantAnnotations) {
+				t.Errorf("includeCondition() annotations = %v, want %v", annotations, tt.wantAnnotations)
+			}
+		})
+	}
+}
+
+func Test_parseCondition(t *testing.T) {
+	tests := []struct {
+		result  interface{}
+		want    bool
+		wantErr bool
+	}{
+		{result: "true", want: true},
+		{result: " false\n", want: false},
+		{result: true, want: true},
+		{result: "", wantErr: true},
+		{result: "yes", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseCondition(tt.result, false)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseCondition(%q) error = %v, wantErr %v", tt.result, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseCondition(%q) = %v, want %v", tt.result, got, tt.want)
+		}
+	}
+}
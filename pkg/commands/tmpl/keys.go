@@ -0,0 +1,262 @@
+package tmpl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/frigus02/kyml/pkg/k8syaml"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+type keyUsage struct {
+	Resource string `json:"resource"`
+	Field    string `json:"field"`
+}
+
+type templateKey struct {
+	Key    string     `json:"key"`
+	Usages []keyUsage `json:"usages"`
+}
+
+// listKeys returns all keys of the template context referenced by templates
+// in the documents, sorted by key. If withKeys is true, templates in keys of
+// objects are included.
+func listKeys(documents []*unstructured.Unstructured, withKeys bool) ([]templateKey, error) {
+	usages := make(map[string][]keyUsage)
+	for _, doc := range documents {
+		resource := k8syaml.ResourceName(doc)
+		collect := func(text, name string) (interface{}, error) {
+			tmpl, err := template.New(name).Funcs(funcMap).Parse(text)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, key := range templateKeys(tmpl) {
+				usages[key] = append(usages[key], keyUsage{Resource: resource, Field: name})
+			}
+
+			return text, nil
+		}
+
+		if _, err := templateValuesInMap(doc.UnstructuredContent(), "", collect, withKeys); err != nil {
+			return nil, err
+		}
+	}
+
+	keys := make([]templateKey, 0, len(usages))
+	for key, u := range usages {
+		sort.Slice(u, func(i, j int) bool {
+			if u[i].Resource != u[j].Resource {
+				return u[i].Resource < u[j].Resource
+			}
+
+			return u[i].Field < u[j].Field
+		})
+		keys = append(keys, templateKey{Key: key, Usages: u})
+	}
+
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Key < keys[j].Key })
+	return keys, nil
+}
+
+// templateKeys returns the unique keys of the template context referenced in
+// the template, e.g. "image.tag" for "{{.image.tag}}".
+func templateKeys(tmpl *template.Template) []string {
+	refs := templateKeyRefs(tmpl)
+	keys := make([]string, 0, len(refs))
+	for key := range refs {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// templateKeyRefs returns the keys of the template context referenced in the
+// template. The value is true if the key is required, i.e. executing the
+// template with missingkey=error fails if it doesn't exist. Keys only used
+// with index are optional.
+func templateKeyRefs(tmpl *template.Template) map[string]bool {
+	return collectKeys(tmpl).keys
+}
+
+func collectKeys(tmpl *template.Template) *keyCollector {
+	c := &keyCollector{keys: make(map[string]bool)}
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil && t.Tree.Root != nil {
+			c.walk(t.Tree.Root, []string{})
+		}
+	}
+
+	return c
+}
+
+type keyCollector struct {
+	keys map[string]bool
+	// unknown is true if the template accesses values, whose keys are not
+	// known, e.g. the whole context in "{{toJson .}}" or values in a range.
+	unknown bool
+}
+
+func (c *keyCollector) add(path []string, required bool) {
+	if len(path) > 0 {
+		key := strings.Join(path, ".")
+		c.keys[key] = c.keys[key] || required
+	}
+}
+
+// walk collects keys in the node. The dot is the path of "." in the template
+// context or nil if it's unknown, e.g. inside of a range.
+func (c *keyCollector) walk(node parse.Node, dot []string) {
+	switch node := node.(type) {
+	case *parse.ListNode:
+		if node == nil {
+			return
+		}
+
+		for _, n := range node.Nodes {
+			c.walk(n, dot)
+		}
+	case *parse.ActionNode:
+		c.walk(node.Pipe, dot)
+	case *parse.TemplateNode:
+		c.walk(node.Pipe, dot)
+	case *parse.IfNode:
+		c.walkBranch(&node.BranchNode, dot, dot)
+	case *parse.WithNode:
+		c.walkBranch(&node.BranchNode, dot, c.fieldPath(node.Pipe, dot))
+	case *parse.RangeNode:
+		c.walkBranch(&node.BranchNode, dot, nil)
+	case *parse.PipeNode:
+		if node == nil {
+			return
+		}
+
+		for _, cmd := range node.Cmds {
+			c.walk(cmd, dot)
+		}
+	case *parse.CommandNode:
+		if path := c.indexPath(node, dot); path != nil {
+			c.add(path, false)
+			if _, ok := node.Args[1].(*parse.DotNode); !ok {
+				c.walk(node.Args[1], dot)
+			}
+			return
+		}
+
+		for _, arg := range node.Args {
+			c.walk(arg, dot)
+		}
+	case *parse.ChainNode:
+		c.walk(node.Node, dot)
+	case *parse.DotNode:
+		if len(dot) == 0 {
+			c.unknown = true
+		} else {
+			c.add(dot, false)
+		}
+	case *parse.FieldNode:
+		if dot == nil {
+			c.unknown = true
+		} else {
+			c.add(append(append([]string{}, dot...), node.Ident...), true)
+		}
+	case *parse.VariableNode:
+		// Only $ refers to the template context. Other variables are unknown.
+		if node.Ident[0] == "$" && len(node.Ident) > 1 {
+			c.add(node.Ident[1:], true)
+		} else {
+			c.unknown = true
+		}
+	}
+}
+
+func (c *keyCollector) walkBranch(node *parse.BranchNode, dot, bodyDot []string) {
+	c.walk(node.Pipe, dot)
+	c.walk(node.List, bodyDot)
+	c.walk(node.ElseList, dot)
+}
+
+// fieldPath returns the path of the pipeline if it consists of a single
+// field, e.g. ".image" or "$.image". Otherwise it returns nil.
+func (c *keyCollector) fieldPath(pipe *parse.PipeNode, dot []string) []string {
+	if pipe == nil || len(pipe.Decl) != 0 || len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return nil
+	}
+
+	switch arg := pipe.Cmds[0].Args[0].(type) {
+	case *parse.FieldNode:
+		if dot != nil {
+			return append(append([]string{}, dot...), arg.Ident...)
+		}
+	case *parse.VariableNode:
+		if arg.Ident[0] == "$" {
+			return append([]string{}, arg.Ident[1:]...)
+		}
+	}
+
+	return nil
+}
+
+// indexPath returns the path of calls to index with constant string keys,
+// e.g. "image.tag" for {{index . "image" "tag"}}. Otherwise it returns nil.
+func (c *keyCollector) indexPath(cmd *parse.CommandNode, dot []string) []string {
+	if len(cmd.Args) < 3 {
+		return nil
+	}
+
+	if ident, ok := cmd.Args[0].(*parse.IdentifierNode); !ok || ident.Ident != "index" {
+		return nil
+	}
+
+	var path []string
+	switch arg := cmd.Args[1].(type) {
+	case *parse.DotNode:
+		path = append(path, dot...)
+	case *parse.FieldNode:
+		path = append(append(path, dot...), arg.Ident...)
+	default:
+		return nil
+	}
+	if dot == nil {
+		return nil
+	}
+
+	for _, arg := range cmd.Args[2:] {
+		str, ok := arg.(*parse.StringNode)
+		if !ok {
+			return nil
+		}
+
+		path = append(path, str.Text)
+	}
+
+	return path
+}
+
+func writeKeysText(w io.Writer, keys []templateKey) error {
+	for _, key := range keys {
+		if _, err := fmt.Fprintln(w, key.Key); err != nil {
+			return err
+		}
+
+		for _, usage := range key.Usages {
+			if _, err := fmt.Fprintf(w, "  %s %s\n", usage.Resource, usage.Field); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func writeKeysJSON(w io.Writer, keys []templateKey) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(keys)
+}
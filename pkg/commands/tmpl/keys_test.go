@@ -0,0 +1,149 @@
+package tmpl
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/frigus02/kyml/pkg/k8syaml"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func Test_templateKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "no template", text: "hello", want: []string{}},
+		{name: "field", text: "{{.tag}}", want: []string{"tag"}},
+		{name: "nested field", text: "{{.image.repository}}:{{.image.tag}}", want: []string{"image.repository", "image.tag"}},
+		{name: "functions and pipelines", text: `{{.name | default .fallback | trunc 63}}`, want: []string{"fallback", "name"}},
+		{name: "parenthesized pipeline", text: `{{if gt (.replicas | int) 2}}{{.label}}{{end}}`, want: []string{"label", "replicas"}},
+		{name: "if and else", text: `{{if .debug}}{{.a}}{{else}}{{.b}}{{end}}`, want: []string{"a", "b", "debug"}},
+		{name: "with", text: `{{with .image}}{{.tag}}{{else}}{{.tag}}{{end}}`, want: []string{"image", "image.tag", "tag"}},
+		{name: "range", text: `{{range .items}}{{.name}}{{$.prefix}}{{end}}`, want: []string{"items", "prefix"}},
+		{name: "variables", text: `{{$tag := .tag}}{{$tag}}{{$.image.repository}}`, want: []string{"image.repository", "tag"}},
+		{name: "index with constant keys", text: `{{index . "image" "tag" | default "latest"}}`, want: []string{"image.tag"}},
+		{name: "index of field", text: `{{index .labels "app"}}`, want: []string{"labels", "labels.app"}},
+		{name: "index with dynamic key", text: `{{index .labels .key}}`, want: []string{"key", "labels"}},
+		{name: "defined template", text: `{{define "x"}}{{.inner}}{{end}}{{template "x" .outer}}`, want: []string{"inner", "outer"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := template.New(tt.name).Funcs(funcMap).Parse(tt.text)
+			if err != nil {
+				t.Fatalf("error parsing template: %v", err)
+			}
+
+			if got := templateKeys(tmpl); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("templateKeys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_templateKeyRefs(t *testing.T) {
+	tmpl, err := template.New("refs").Funcs(funcMap).Parse(`{{.a}}{{index . "b"}}{{index .c "d"}}{{with .e}}{{toJson .}}{{end}}{{index . "a"}}`)
+	if err != nil {
+		t.Fatalf("error parsing template: %v", err)
+	}
+
+	want := map[string]bool{"a": true, "b": false, "c": true, "c.d": false, "e": true}
+	if got := templateKeyRefs(tmpl); !reflect.DeepEqual(got, want) {
+		t.Errorf("templateKeyRefs() = %v, want %v", got, want)
+	}
+}
+
+func mustDecode(t *testing.T, yaml string) []*unstructured.Unstructured {
+	docs, err := k8syaml.Decode(strings.NewReader(yaml))
+	if err != nil {
+		t.Fatalf("error decoding test manifests: %v", err)
+	}
+
+	return docs
+}
+
+func Test_listKeys(t *testing.T) {
+	documents := mustDecode(t, `---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  namespace: "{{.namespace}}"
+data:
+  tag: "{{.image.tag}}"
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: "kyml/app:{{.image.tag}}"
+`)
+
+	keys, err := listKeys(documents, false)
+	if err != nil {
+		t.Fatalf("listKeys() error = %v", err)
+	}
+
+	var text bytes.Buffer
+	if err := writeKeysText(&text, keys); err != nil {
+		t.Fatal(err)
+	}
+	wantText := `image.tag
+  ConfigMap/{{.namespace}}/config data.tag
+  Deployment/app spec.template.spec.containers[0].image
+namespace
+  ConfigMap/{{.namespace}}/config metadata.namespace
+`
+	if text.String() != wantText {
+		t.Errorf("writeKeysText() = %v, want %v", text.String(), wantText)
+	}
+
+	var json bytes.Buffer
+	if err := writeKeysJSON(&json, keys[1:]); err != nil {
+		t.Fatal(err)
+	}
+	wantJSON := `[
+  {
+    "key": "namespace",
+    "usages": [
+      {
+        "resource": "ConfigMap/{{.namespace}}/config",
+        "field": "metadata.namespace"
+      }
+    ]
+  }
+]
+`
+	if json.String() != wantJSON {
+		t.Errorf("writeKeysJSON() = %v, want %v", json.String(), wantJSON)
+	}
+
+	if _, err := listKeys(mustDecode(t, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\ndata:\n  a: \"{{.a\"\n"), false); err == nil {
+		t.Errorf("listKeys() of invalid template error = nil, want error")
+	}
+}
+
+func Test_listKeys_withKeys(t *testing.T) {
+	documents := mustDecode(t, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n  labels:\n    \"{{.Env}}.kyml.io/tier\": backend\n")
+
+	keys, err := listKeys(documents, false)
+	if err != nil || len(keys) != 0 {
+		t.Errorf("listKeys() = %v, %v, want no keys", keys, err)
+	}
+
+	keys, err = listKeys(documents, true)
+	want := []templateKey{{Key: "Env", Usages: []keyUsage{{Resource: "ConfigMap/config", Field: "metadata.labels.{{.Env}}.kyml.io/tier"}}}}
+	if err != nil || !reflect.DeepEqual(keys, want) {
+		t.Errorf("listKeys() = %v, %v, want %v", keys, err, want)
+	}
+}
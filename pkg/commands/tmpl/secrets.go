@@ -0,0 +1,41 @@
+package tmpl
+
+import (
+	"strconv"
+	"strings"
+)
+
+const secretMask = "***"
+
+// usesSecrets returns true if the template references one of the secret keys,
+// a parent or a child of it, or values with unknown keys, e.g. "{{toJson .}}".
+// The output of these templates must not be printed.
+func usesSecrets(keys *keyCollector, secretKeys map[string]bool) bool {
+	if len(secretKeys) == 0 {
+		return false
+	}
+
+	if keys.unknown {
+		return true
+	}
+
+	for key := range keys.keys {
+		for secretKey := range secretKeys {
+			if key == secretKey || strings.HasPrefix(key, secretKey+".") || strings.HasPrefix(secretKey, key+".") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// quoteOutput quotes the output of a template for error messages. If the
+// template uses secrets, the output is masked.
+func quoteOutput(output string, secret bool) string {
+	if secret {
+		return strconv.Quote(secretMask)
+	}
+
+	return strconv.Quote(output)
+}
@@ -0,0 +1,44 @@
+package tmpl
+
+import (
+	"testing"
+	"text/template"
+)
+
+func Test_usesSecrets(t *testing.T) {
+	secretKeys := map[string]bool{"db.password": true, "token": true}
+
+	tests := []struct {
+		name       string
+		text       string
+		secretKeys map[string]bool
+		want       bool
+	}{
+		{name: "no secrets", text: `{{.token}}`, secretKeys: nil, want: false},
+		{name: "other key", text: `{{.name}}`, secretKeys: secretKeys, want: false},
+		{name: "secret key", text: `{{.token | b64enc}}`, secretKeys: secretKeys, want: true},
+		{name: "nested secret key", text: `{{with .db}}{{.password}}{{end}}`, secretKeys: secretKeys, want: true},
+		{name: "parent of secret key", text: `{{.db | toJson}}`, secretKeys: secretKeys, want: true},
+		{name: "index", text: `{{index . "token"}}`, secretKeys: secretKeys, want: true},
+		{name: "whole context", text: `{{toJson .}}`, secretKeys: secretKeys, want: true},
+		{name: "range", text: `{{range $k, $v := $}}{{$v}}{{end}}`, secretKeys: secretKeys, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl := template.Must(template.New(tt.name).Funcs(funcMap).Parse(tt.text))
+			if got := usesSecrets(collectKeys(tmpl), tt.secretKeys); got != tt.want {
+				t.Errorf("usesSecrets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_quoteOutput(t *testing.T) {
+	if got, want := quoteOutput("hunter2", false), `"hunter2"`; got != want {
+		t.Errorf("quoteOutput() = %v, want %v", got, want)
+	}
+
+	if got, want := quoteOutput("hunter2", true), `"***"`; got != want {
+		t.Errorf("quoteOutput() = %v, want %v", got, want)
+	}
+}
@@ -5,20 +5,33 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sort"
+	"strings"
 	"text/template"
 
 	"github.com/frigus02/kyml/pkg/cat"
+	"github.com/frigus02/kyml/pkg/fs"
 	"github.com/frigus02/kyml/pkg/k8syaml"
 	"github.com/spf13/cobra"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
 type tmplOptions struct {
-	values  map[string]string
-	envVars []string
+	values       map[string]string
+	setValues    map[string]string
+	envVars      []string
+	valuesFiles  []string
+	valueFiles   []string
+	valuesFds    []int
+	strict       bool
+	templateKeys bool
+	listKeys     bool
+	output       string
 }
 
 // NewCmdTmpl creates a new tmpl command.
-func NewCmdTmpl(in io.Reader, out io.Writer) *cobra.Command {
+func NewCmdTmpl(in io.Reader, out io.Writer, fs fs.Filesystem) *cobra.Command {
 	var o tmplOptions
 
 	cmd := &cobra.Command{
@@ -26,7 +39,31 @@ func NewCmdTmpl(in io.Reader, out io.Writer) *cobra.Command {
 		Short: "Template Kubernetes YAML files",
 		Long: `Template Kubernetes YAML files. Data is read from stdin, executed with the specified context, and printed to stdout.
 
-Templates are only supported in values of type string. They use the go template syntax (https://golang.org/pkg/text/template/). You can add data to the template context using the options "--value" and "--env". Please note that keys (including environment variable names) are case sensitive.
+Templates are only supported in values of type string. They use the go template syntax (https://golang.org/pkg/text/template/). You can add data to the template context using the options "--values-file", "--value" and "--env". Please note that keys (including environment variable names) are case sensitive. The command fails if templates use keys, which are not specified, and reports all of them at once. Use "--strict" to also fail if values are not used by any template.
+
+Values files can be YAML (.yaml, .yml), JSON (.json) or dotenv (.env) files. They can contain nested values, which templates access like "{{.image.tag}}". If multiple values files are specified, later files override values of earlier ones. Nested objects are merged. Values specified using "--set", "--value" and "--env" override values from files. Use "--set" to set nested values using keys with dots, e.g. "--set image.tag=1.0". Keys of "--value" are used as they are, so "--value image.tag=1.0" sets the key "image.tag", which templates access like '{{index . "image.tag"}}'.
+
+To keep secrets out of shell history and process listings, use "--value-file key=path" to add the raw content of a file, e.g. a certificate, or "--values-from-fd" to read YAML or JSON values from a file descriptor, e.g. from a secret store using process substitution. Error messages never contain these values and show the output of templates using them as "***". Rendered documents still contain the values.
+
+Templates can use the following functions in addition to the go template builtins. Functions take the piped value as their last argument, e.g. "{{.name | trunc 63}}". On purpose, no function has access to files, the network or environment variables.
+
+  default, required, coalesce, empty
+  upper, lower, trim, trimPrefix, trimSuffix, replace, trunc,
+  contains, hasPrefix, hasSuffix, quote, squote, join
+  b64enc, b64dec, sha256sum, toJson
+  int, float, bool
+
+Templates always produce strings, unless the whole value is a single action ending in "int", "float" or "bool", e.g. "{{.replicas | int}}". In this case the result is validated and stored as a number or boolean.
+
+Keys used with "default" have to exist in the template context. Use "index" for optional keys, e.g. '{{index . "tag" | default "latest"}}'.
+
+Documents with the annotation "kyml.io/include-if" are only included in the output if its template evaluates to "true", e.g. '{{eq .Env "production"}}'. Other documents are dropped without templating them. The annotation is removed from the output.
+
+Use "--list-keys" to print the keys used by templates, including the resources and field paths using them, instead of templating. The list is printed as text or, with "--output json", as JSON.
+
+Keys of objects, e.g. label keys, are only templated with "--template-keys". The command fails if multiple keys of an object are the same after templating.
+
+If templating changes the name or namespace of a document, they are validated again. The command fails if templating gives multiple documents the same apiVersion, kind, namespace and name. Afterwards documents are sorted in the same way as "kyml cat" does.
 
 The command parses the data as Kubernetes YAML documents before templating. While doing so it applies the same transformations as "kyml cat". Since the document is parsed, you need to make sure it is still valid YAML, even with the template characters inside.`,
 		Example: `  # Template feature branch files and deploy to cluster
@@ -34,19 +71,57 @@ The command parses the data as Kubernetes YAML documents before templating. Whil
     kyml tmpl \
       -v ImageTag=$(git rev-parse --short HEAD) \
       -e TRAVIS_BRANCH |
-    kubectl apply -f -`,
+    kubectl apply -f -
+
+  # Use values files for common and environment specific values
+  kyml cat production/* |
+    kyml tmpl \
+      --values-file values.yaml \
+      --values-file values-production.yaml \
+      --set image.tag=$(git rev-parse --short HEAD)
+
+  # Read a certificate from a file and secrets from a secret store
+  kyml cat production/* |
+    kyml tmpl \
+      --value-file tls.crt=certs/tls.crt \
+      --values-from-fd 3 3< <(vault kv get -format=json -field=data secret/app)
+
+  # List keys required by the manifests
+  kyml cat production/* | kyml tmpl --list-keys
+
+  # Use template functions
+  replicas: "{{.replicas | int}}"
+  name: "{{.branch | lower | replace \"/\" \"-\" | trunc 63}}"
+  password: "{{.password | required \"password is required\" | b64enc}}"
+
+  # Example values file
+  image:
+    repository: kyml/hello
+    tag: latest
+  replicas: 3`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			err := o.Validate(args)
 			if err != nil {
 				return err
 			}
 
-			return o.Run(in, out)
+			return o.Run(in, out, fs)
 		},
 	}
 
+	cmd.Flags().StringArrayVarP(&o.valuesFiles, "values-file", "f", nil, "Add values from a YAML, JSON or dotenv file to the template context")
 	cmd.Flags().StringToStringVarP(&o.values, "value", "v", nil, "Add a key-value pair to the template context")
+	cmd.Flags().StringToStringVar(&o.setValues, "set", nil, "Add a nested value to the template context using a key with dots (e.g. image.tag=1.0)")
 	cmd.Flags().StringArrayVarP(&o.envVars, "env", "e", nil, "Add an environment variable to the template context")
+	cmd.Flags().StringArrayVar(&o.valueFiles, "value-file", nil, "Add a key with the content of a file to the template context (key=path), e.g. for certificates. Keys with dots set nested values")
+	cmd.Flags().IntSliceVar(&o.valuesFds, "values-from-fd", nil, "Add values in YAML or JSON format read from a file descriptor to the template context")
+
+	cmd.Flags().BoolVar(&o.strict, "strict", false, "Fail if a value or environment variable is not used by any template")
+	cmd.Flags().BoolVar(&o.templateKeys, "template-keys", false, "Template keys of objects in addition to values")
+	cmd.Flags().BoolVar(&o.listKeys, "list-keys", false, "List keys used by templates instead of templating")
+	cmd.Flags().StringVarP(&o.output, "output", "o", "text", "Output format of --list-keys (text or json)")
+
+	_ = cmd.MarkFlagFilename("values-file", "yaml", "yml", "json", "env")
 
 	return cmd
 }
@@ -57,70 +132,287 @@ func (o *tmplOptions) Validate(args []string) error {
 		return fmt.Errorf("this command takes no positional arguments")
 	}
 
+	if o.output != "text" && o.output != "json" {
+		return fmt.Errorf("output must be text or json")
+	}
+
 	return nil
 }
 
 // Run runs tmpl command.
-func (o *tmplOptions) Run(in io.Reader, out io.Writer) error {
-	vars := make(map[string]string)
+func (o *tmplOptions) Run(in io.Reader, out io.Writer, fs fs.Filesystem) error {
+	if o.listKeys {
+		return o.runListKeys(in, out)
+	}
+
+	vars, secretKeys, err := o.templateContext(fs)
+	if err != nil {
+		return err
+	}
+
+	return o.template(in, out, vars, secretKeys)
+}
+
+// templateContext returns the values for the template context and the keys of
+// values, which are secret and must not be printed.
+func (o *tmplOptions) templateContext(fs fs.Filesystem) (map[string]interface{}, map[string]bool, error) {
+	vars := make(map[string]interface{})
+	secretKeys := make(map[string]bool)
+	for _, filename := range o.valuesFiles {
+		values, err := loadValuesFile(filename, fs)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		mergeValues(vars, values)
+	}
+	for _, fd := range o.valuesFds {
+		values, err := loadValuesFromFd(fd)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		mergeValues(vars, values)
+		for _, key := range valueKeys(values) {
+			secretKeys[key] = true
+		}
+	}
+	for key, value := range o.setValues {
+		setValue(vars, key, value)
+	}
 	for key, value := range o.values {
 		vars[key] = value
 	}
+	for _, arg := range o.valueFiles {
+		key, value, err := loadValueFile(arg, fs)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		setValue(vars, key, value)
+		secretKeys[key] = true
+	}
 	for _, env := range o.envVars {
 		vars[env] = os.Getenv(env)
 	}
 
+	return vars, secretKeys, nil
+}
+
+func (o *tmplOptions) template(in io.Reader, out io.Writer, vars map[string]interface{}, secretKeys map[string]bool) error {
 	documents, err := cat.StreamDecodeOnly(in)
 	if err != nil {
 		return err
 	}
 
-	var execTmpl = func(text, name string) (string, error) {
-		tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	var errs []string
+	var included []*unstructured.Unstructured
+	usedKeys := make(map[string]bool)
+	// identities maps the identity of documents after templating to the
+	// resource name before templating. Input documents are already
+	// deduplicated, so documents with the same identity after templating were
+	// different documents before.
+	identities := make(map[string]string)
+	for _, doc := range documents {
+		resource := k8syaml.ResourceName(doc)
+		// secretFields contains the paths of fields, whose templates use
+		// secrets. Their output must not be printed.
+		secretFields := make(map[string]bool)
+		execTmpl := func(text, name string) (interface{}, error) {
+			tmpl, err := template.New(name).Funcs(funcMap).Option("missingkey=error").Parse(text)
+			if err != nil {
+				return "", err
+			}
+
+			keys := collectKeys(tmpl)
+			if usesSecrets(keys, secretKeys) {
+				secretFields[name] = true
+			}
+
+			missing := false
+			for key, required := range keys.keys {
+				usedKeys[key] = true
+				if required && !hasValue(vars, key) {
+					errs = append(errs, fmt.Sprintf("%s %s: missing key %q", resource, name, key))
+					missing = true
+				}
+			}
+			if missing {
+				return text, nil
+			}
+
+			var result bytes.Buffer
+			if err = tmpl.Execute(&result, vars); err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", resource, err))
+				return text, nil
+			}
+
+			if typ := typedValueFunc(tmpl.Tree); typ != "" {
+				return parseTypedValue(typ, result.String())
+			}
+
+			return result.String(), nil
+		}
+
+		if condition, ok := includeCondition(doc); ok {
+			errCount := len(errs)
+			name := "metadata.annotations." + includeIfAnnotation
+			result, err := execTmpl(condition, name)
+			if err != nil {
+				return err
+			}
+			if len(errs) > errCount {
+				continue
+			}
+
+			include, err := parseCondition(result, secretFields[name])
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", resource, err))
+				continue
+			}
+			if !include {
+				// Values used only by excluded documents are still used, e.g.
+				// in other environments. Record them for --strict.
+				keys, err := listKeys([]*unstructured.Unstructured{doc}, o.templateKeys)
+				if err != nil {
+					return fmt.Errorf("%s: %v", resource, err)
+				}
+
+				for _, key := range keys {
+					usedKeys[key.Key] = true
+				}
+
+				continue
+			}
+		}
+
+		name, namespace := doc.GetName(), doc.GetNamespace()
+		templated, err := templateValuesInMap(doc.UnstructuredContent(), "", execTmpl, o.templateKeys)
 		if err != nil {
-			return "", err
+			return fmt.Errorf("%s: %v", resource, err)
+		}
+
+		doc.SetUnstructuredContent(templated)
+		if doc.GetName() != name || doc.GetNamespace() != namespace {
+			if err := validateIdentity(doc, secretFields); err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", resource, err))
+			}
 		}
 
-		var result bytes.Buffer
-		if err = tmpl.Execute(&result, vars); err != nil {
-			return "", err
+		identity := doc.GroupVersionKind().String() + "/" + doc.GetNamespace() + "/" + doc.GetName()
+		if other, ok := identities[identity]; ok {
+			errs = append(errs, fmt.Sprintf("%s: has the same apiVersion, kind, namespace and name as %s after templating", resource, other))
 		}
+		identities[identity] = resource
 
-		return result.String(), nil
+		included = append(included, doc)
 	}
 
-	for _, doc := range documents {
-		templated, err := templateValuesInMap(doc.UnstructuredContent(), execTmpl)
-		if err != nil {
-			return err
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return fmt.Errorf("templating failed:\n  %s", strings.Join(errs, "\n  "))
+	}
+
+	if o.strict {
+		if unused := unusedValues(vars, usedKeys); len(unused) > 0 {
+			return fmt.Errorf("values are not used by any template: %s", strings.Join(unused, ", "))
 		}
+	}
 
-		doc.SetUnstructuredContent(templated)
+	// Templating can change the identity of documents, so sort them again.
+	return k8syaml.Encode(out, cat.Normalize(included))
+}
+
+func (o *tmplOptions) runListKeys(in io.Reader, out io.Writer) error {
+	documents, err := cat.StreamDecodeOnly(in)
+	if err != nil {
+		return err
+	}
+
+	keys, err := listKeys(documents, o.templateKeys)
+	if err != nil {
+		return err
+	}
+
+	if o.output == "json" {
+		return writeKeysJSON(out, keys)
+	}
+
+	return writeKeysText(out, keys)
+}
+
+// unusedValues returns the keys of all values, which are neither used by a
+// template themselves nor through one of their parent or child keys.
+func unusedValues(vars map[string]interface{}, usedKeys map[string]bool) []string {
+	var unused []string
+	for _, key := range valueKeys(vars) {
+		used := false
+		for usedKey := range usedKeys {
+			if key == usedKey || strings.HasPrefix(key, usedKey+".") || strings.HasPrefix(usedKey, key+".") {
+				used = true
+				break
+			}
+		}
+
+		if !used {
+			unused = append(unused, key)
+		}
 	}
 
-	return k8syaml.Encode(out, documents)
+	return unused
 }
 
-type valueTemplater func(text, name string) (string, error)
+type valueTemplater func(text, name string) (interface{}, error)
 
-func templateValuesInMap(m map[string]interface{}, execTmpl valueTemplater) (map[string]interface{}, error) {
+// templateValuesInMap templates all string values in the map. If withKeys is
+// true, it also templates keys and fails if multiple keys are the same after
+// templating.
+func templateValuesInMap(m map[string]interface{}, path string, execTmpl valueTemplater, withKeys bool) (map[string]interface{}, error) {
 	newMap := make(map[string]interface{}, len(m))
-	for key, value := range m {
-		templated, err := templateValue(value, key, execTmpl)
+	origKeys := make(map[string]string, len(m))
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		name := key
+		if path != "" {
+			name = path + "." + key
+		}
+
+		newKey := key
+		if withKeys {
+			templatedKey, err := execTmpl(key, name)
+			if err != nil {
+				return nil, err
+			}
+
+			// The templated key can contain secrets, so the error message
+			// only includes the original keys.
+			newKey = toString(templatedKey)
+			if origKey, exists := origKeys[newKey]; exists {
+				return nil, fmt.Errorf("%s: key is the same as %q after templating", name, origKey)
+			}
+			origKeys[newKey] = key
+		}
+
+		templated, err := templateValue(m[key], name, execTmpl, withKeys)
 		if err != nil {
 			return nil, err
 		}
 
-		newMap[key] = templated
+		newMap[newKey] = templated
 	}
 
 	return newMap, nil
 }
 
-func templateValuesInSlice(s []interface{}, name string, execTmpl valueTemplater) ([]interface{}, error) {
+func templateValuesInSlice(s []interface{}, name string, execTmpl valueTemplater, withKeys bool) ([]interface{}, error) {
 	newSlice := make([]interface{}, len(s))
 	for index, value := range s {
-		templated, err := templateValue(value, fmt.Sprintf("%s[%d]", name, index), execTmpl)
+		templated, err := templateValue(value, fmt.Sprintf("%s[%d]", name, index), execTmpl, withKeys)
 		if err != nil {
 			return nil, err
 		}
@@ -131,12 +423,12 @@ func templateValuesInSlice(s []interface{}, name string, execTmpl valueTemplater
 	return newSlice, nil
 }
 
-func templateValue(value interface{}, name string, execTmpl valueTemplater) (interface{}, error) {
+func templateValue(value interface{}, name string, execTmpl valueTemplater, withKeys bool) (interface{}, error) {
 	switch value := value.(type) {
 	case map[string]interface{}:
-		return templateValuesInMap(value, execTmpl)
+		return templateValuesInMap(value, name, execTmpl, withKeys)
 	case []interface{}:
-		return templateValuesInSlice(value, name, execTmpl)
+		return templateValuesInSlice(value, name, execTmpl, withKeys)
 	case string:
 		return execTmpl(value, name)
 	default:
@@ -2,10 +2,14 @@ package tmpl
 
 import (
 	"bytes"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"strings"
 	"testing"
+
+	"github.com/frigus02/kyml/pkg/fs"
 )
 
 var testManifestDeployment = `---
@@ -52,11 +56,13 @@ func Test_tmplOptions_Validate(t *testing.T) {
 	}
 	tests := []struct {
 		name    string
+		o       *tmplOptions
 		args    args
 		wantErr bool
 	}{
 		{
 			name: "no args",
+			o:    &tmplOptions{output: "text"},
 			args: args{
 				args: []string{},
 			},
@@ -64,16 +70,32 @@ func Test_tmplOptions_Validate(t *testing.T) {
 		},
 		{
 			name: "error when args are specified",
+			o:    &tmplOptions{output: "text"},
 			args: args{
 				args: []string{"hello"},
 			},
 			wantErr: true,
 		},
+		{
+			name: "list keys as json",
+			o:    &tmplOptions{listKeys: true, output: "json"},
+			args: args{
+				args: []string{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "error when output is invalid",
+			o:    &tmplOptions{listKeys: true, output: "yaml"},
+			args: args{
+				args: []string{},
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			o := &tmplOptions{}
-			if err := o.Validate(tt.args.args); (err != nil) != tt.wantErr {
+			if err := tt.o.Validate(tt.args.args); (err != nil) != tt.wantErr {
 				t.Errorf("tmplOptions.Validate() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -87,13 +109,15 @@ func Test_tmplOptions_Run(t *testing.T) {
 
 	type args struct {
 		in io.Reader
+		fs fs.Filesystem
 	}
 	tests := []struct {
-		name    string
-		o       *tmplOptions
-		args    args
-		wantOut string
-		wantErr bool
+		name             string
+		o                *tmplOptions
+		args             args
+		wantOut          string
+		wantErr          bool
+		wantErrToContain string
 	}{
 		{
 			name: "success",
@@ -124,6 +148,423 @@ func Test_tmplOptions_Run(t *testing.T) {
 			wantOut: "",
 			wantErr: true,
 		},
+		{
+			name: "all missing values",
+			o: &tmplOptions{
+				values: map[string]string{
+					"tag": "latest",
+				},
+			},
+			args: args{
+				in: strings.NewReader(testManifestDeployment + "---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n  namespace: default\ndata:\n  image: \"{{.image.repository}}:{{.tag}}\"\n  items: \"{{range .items}}{{.name}}{{end}}\"\n"),
+			},
+			wantOut:          "",
+			wantErr:          true,
+			wantErrToContain: "templating failed:\n  ConfigMap/default/config data.image: missing key \"image.repository\"\n  ConfigMap/default/config data.items: missing key \"items\"\n  Deployment/the-deployment metadata.labels.branch: missing key \"branch\"\n  Deployment/the-deployment spec.template.spec.containers[0].env[0].value: missing key \"SECRET\"",
+		},
+		{
+			name: "missing value in range",
+			o: &tmplOptions{
+				valuesFiles: []string{"values.json"},
+			},
+			args: args{
+				in: strings.NewReader("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\ndata:\n  items: \"{{range .items}}{{.name}}{{end}}\"\n"),
+				fs: fs.NewFakeFilesystemWithFiles(map[string]string{
+					"values.json": `{"items": [{"name": "a"}, {"title": "b"}]}`,
+				}),
+			},
+			wantOut:          "",
+			wantErr:          true,
+			wantErrToContain: "templating failed:\n  ConfigMap/config: template: data.items:1:18: executing \"data.items\" at <.name>: map has no entry for key \"name\"",
+		},
+		{
+			name: "strict with all values used",
+			o: &tmplOptions{
+				strict:      true,
+				valuesFiles: []string{"values.yaml"},
+				values: map[string]string{
+					"branch": "my-feature",
+				},
+				envVars: []string{"SECRET"},
+			},
+			args: args{
+				in: strings.NewReader(testManifestDeployment),
+				fs: fs.NewFakeFilesystemWithFiles(map[string]string{
+					"values.yaml": "tag: latest\n",
+				}),
+			},
+			wantOut: templatedDeployment,
+			wantErr: false,
+		},
+		{
+			name: "strict with unused values",
+			o: &tmplOptions{
+				strict:      true,
+				valuesFiles: []string{"values.yaml"},
+				values: map[string]string{
+					"branch": "my-feature",
+					"tag":    "latest",
+					"Tga":    "latest",
+				},
+				envVars: []string{"SECRET"},
+			},
+			args: args{
+				in: strings.NewReader(testManifestDeployment),
+				fs: fs.NewFakeFilesystemWithFiles(map[string]string{
+					"values.yaml": "image:\n  repository: kyml/hello\n",
+				}),
+			},
+			wantOut:          "",
+			wantErr:          true,
+			wantErrToContain: "values are not used by any template: Tga, image.repository",
+		},
+		{
+			name: "values files",
+			o: &tmplOptions{
+				valuesFiles: []string{"values.yaml", "values-feature.json", "secrets.env"},
+				setValues: map[string]string{
+					"image.tag": "latest",
+				},
+			},
+			args: args{
+				in: strings.NewReader("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: values\ndata:\n  branch: \"{{.branch}}\"\n  image: \"{{.image.repository}}:{{.image.tag}}\"\n  replicas: \"{{.replicas}}\"\n  secret: \"{{.SECRET}}\"\n"),
+				fs: fs.NewFakeFilesystemWithFiles(map[string]string{
+					"values.yaml":         "branch: main\nimage:\n  repository: kyml/hello\n  tag: \"1.0\"\nreplicas: 3\n",
+					"values-feature.json": `{"branch": "my-feature", "image": {"tag": "2.0"}}`,
+					"secrets.env":         "# comment\nSECRET='123_\"_'\n",
+				}),
+			},
+			wantOut: "---\napiVersion: v1\ndata:\n  branch: my-feature\n  image: kyml/hello:latest\n  replicas: \"3\"\n  secret: 123_\"_\nkind: ConfigMap\nmetadata:\n  name: values\n",
+			wantErr: false,
+		},
+		{
+			name: "template functions",
+			o: &tmplOptions{
+				values: map[string]string{
+					"branch":   "Feature/My-Change",
+					"password": "hunter2",
+				},
+			},
+			args: args{
+				in: strings.NewReader("apiVersion: v1\nkind: Secret\nmetadata:\n  name: \"app-{{.branch | lower | replace \\\"/\\\" \\\"-\\\"}}\"\ndata:\n  password: \"{{.password | required \\\"password is required\\\" | b64enc}}\"\n  tag: \"{{index . \\\"tag\\\" | default \\\"latest\\\"}}\"\n"),
+			},
+			wantOut: "---\napiVersion: v1\ndata:\n  password: aHVudGVyMg==\n  tag: latest\nkind: Secret\nmetadata:\n  name: app-feature-my-change\n",
+			wantErr: false,
+		},
+		{
+			name: "required value is empty",
+			o: &tmplOptions{
+				values: map[string]string{
+					"password": "",
+				},
+			},
+			args: args{
+				in: strings.NewReader("apiVersion: v1\nkind: Secret\nmetadata:\n  name: app\ndata:\n  password: \"{{.password | required \\\"password is required\\\" | b64enc}}\"\n"),
+			},
+			wantOut: "",
+			wantErr: true,
+		},
+		{
+			name: "typed values",
+			o: &tmplOptions{
+				valuesFiles: []string{"values.yaml"},
+				values: map[string]string{
+					"debug": "true",
+				},
+			},
+			args: args{
+				in: strings.NewReader("apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: app\nspec:\n  replicas: \"{{ .replicas | int }}\"\n  paused: \"{{.debug | bool}}\"\n  ratio: \"{{.ratio | float}}\"\n  label: \"{{.replicas | int}}\"\n"),
+				fs: fs.NewFakeFilesystemWithFiles(map[string]string{
+					"values.yaml": "replicas: 3\nratio: \"0.5\"\n",
+				}),
+			},
+			wantOut: "---\napiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: app\nspec:\n  label: 3\n  paused: true\n  ratio: 0.5\n  replicas: 3\n",
+			wantErr: false,
+		},
+		{
+			name: "typed value is invalid",
+			o: &tmplOptions{
+				values: map[string]string{
+					"replicas": "3\nfoo: bar",
+				},
+			},
+			args: args{
+				in: strings.NewReader("apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: app\nspec:\n  replicas: \"{{.replicas | int}}\"\n"),
+			},
+			wantOut: "",
+			wantErr: true,
+		},
+		{
+			name: "list keys",
+			o: &tmplOptions{
+				listKeys: true,
+				output:   "text",
+			},
+			args: args{
+				in: strings.NewReader(testManifestDeployment),
+			},
+			wantOut: "SECRET\n  Deployment/the-deployment spec.template.spec.containers[0].env[0].value\nbranch\n  Deployment/the-deployment metadata.labels.branch\ntag\n  Deployment/the-deployment spec.template.spec.containers[0].image\n",
+			wantErr: false,
+		},
+		{
+			name: "values with dots are flat",
+			o: &tmplOptions{
+				values: map[string]string{
+					"image.tag": "latest",
+				},
+				setValues: map[string]string{
+					"image.repository": "kyml/hello",
+				},
+			},
+			args: args{
+				in: strings.NewReader("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: values\ndata:\n  image: '{{.image.repository}}:{{index . \"image.tag\"}}'\n"),
+			},
+			wantOut: "---\napiVersion: v1\ndata:\n  image: kyml/hello:latest\nkind: ConfigMap\nmetadata:\n  name: values\n",
+			wantErr: false,
+		},
+		{
+			name: "value files",
+			o: &tmplOptions{
+				valueFiles: []string{"tls.crt=certs/tls.crt", "config.json=config.json"},
+			},
+			args: args{
+				in: strings.NewReader("apiVersion: v1\nkind: Secret\nmetadata:\n  name: tls\nstringData:\n  tls.crt: \"{{index . \\\"tls\\\" \\\"crt\\\"}}\"\n  config.json: \"{{index . \\\"config\\\" \\\"json\\\"}}\"\n"),
+				fs: fs.NewFakeFilesystemWithFiles(map[string]string{
+					"certs/tls.crt": "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n",
+					"config.json":   "{\"debug\": true}",
+				}),
+			},
+			wantOut: "---\napiVersion: v1\nkind: Secret\nmetadata:\n  name: tls\nstringData:\n  config.json: '{\"debug\": true}'\n  tls.crt: |\n    -----BEGIN CERTIFICATE-----\n    MIIB\n    -----END CERTIFICATE-----\n",
+			wantErr: false,
+		},
+		{
+			name: "value file is masked in errors",
+			o: &tmplOptions{
+				valueFiles: []string{"password=password.txt"},
+			},
+			args: args{
+				in: strings.NewReader("apiVersion: v1\nkind: Secret\nmetadata:\n  name: password\nstringData:\n  password: \"{{.password | int}}\"\n"),
+				fs: fs.NewFakeFilesystemWithFiles(map[string]string{
+					"password.txt": "hunter2",
+				}),
+			},
+			wantOut:          "",
+			wantErr:          true,
+			wantErrToContain: "error calling int: cannot convert string to an integer",
+		},
+		{
+			name: "short value file doesn't mask other text",
+			o: &tmplOptions{
+				valueFiles: []string{"password=password.txt"},
+			},
+			args: args{
+				in: strings.NewReader("apiVersion: v1\nkind: Secret\nmetadata:\n  name: password\nstringData:\n  password: \"{{.password | int}}\"\n"),
+				fs: fs.NewFakeFilesystemWithFiles(map[string]string{
+					"password.txt": "a",
+				}),
+			},
+			wantOut:          "",
+			wantErr:          true,
+			wantErrToContain: "templating failed:\n  Secret/password: template: stringData.password:1:14: executing \"stringData.password\" at <int>: error calling int: cannot convert string to an integer",
+		},
+		{
+			name: "output of templates using value files is masked",
+			o: &tmplOptions{
+				valueFiles: []string{"name=name.txt"},
+			},
+			args: args{
+				in: strings.NewReader("apiVersion: v1\nkind: Secret\nmetadata:\n  name: \"app-{{.name}}\"\n"),
+				fs: fs.NewFakeFilesystemWithFiles(map[string]string{
+					"name.txt": "Hunter2",
+				}),
+			},
+			wantOut:          "",
+			wantErr:          true,
+			wantErrToContain: "metadata.name \"***\" is invalid after templating",
+		},
+		{
+			name: "value file in invalid format",
+			o: &tmplOptions{
+				valueFiles: []string{"password.txt"},
+			},
+			args: args{
+				in: strings.NewReader(testManifestDeployment),
+			},
+			wantOut:          "",
+			wantErr:          true,
+			wantErrToContain: "value file \"password.txt\" must be in the form key=path",
+		},
+		{
+			name: "include if",
+			o: &tmplOptions{
+				values: map[string]string{
+					"Env": "staging",
+				},
+			},
+			args: args{
+				in: strings.NewReader(`---
+apiVersion: v1
+kind: Service
+metadata:
+  name: debug
+  annotations:
+    kyml.io/include-if: '{{ eq .Env "staging" }}'
+---
+apiVersion: policy/v1
+kind: PodDisruptionBudget
+metadata:
+  name: app
+  annotations:
+    kyml.io/include-if: '{{ eq .Env "production" }}'
+spec:
+  minAvailable: "{{.MinAvailable | int}}"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  annotations:
+    kyml.io/include-if: "true"
+    note: "{{.Env}}"
+`),
+			},
+			wantOut: "---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  annotations:\n    note: staging\n  name: config\n---\napiVersion: v1\nkind: Service\nmetadata:\n  name: debug\n",
+			wantErr: false,
+		},
+		{
+			name: "strict with values used only by excluded documents",
+			o: &tmplOptions{
+				values: map[string]string{
+					"Env":       "prod",
+					"debugPort": "80",
+				},
+				strict: true,
+			},
+			args: args{
+				in: strings.NewReader("apiVersion: v1\nkind: Service\nmetadata:\n  name: debug\n  annotations:\n    kyml.io/include-if: '{{ eq .Env \"staging\" }}'\nspec:\n  ports:\n  - port: \"{{.debugPort | int}}\"\n"),
+			},
+			wantOut: "",
+			wantErr: false,
+		},
+		{
+			name: "include if with invalid result",
+			o: &tmplOptions{
+				values: map[string]string{
+					"Env": "staging",
+				},
+			},
+			args: args{
+				in: strings.NewReader("apiVersion: v1\nkind: Service\nmetadata:\n  name: debug\n  annotations:\n    kyml.io/include-if: '{{ .Env }}'\n"),
+			},
+			wantOut:          "",
+			wantErr:          true,
+			wantErrToContain: "Service/debug: annotation kyml.io/include-if must evaluate to true or false, got \"staging\"",
+		},
+		{
+			name: "include if with missing key",
+			o:    &tmplOptions{},
+			args: args{
+				in: strings.NewReader("apiVersion: v1\nkind: Service\nmetadata:\n  name: debug\n  annotations:\n    kyml.io/include-if: '{{ eq .Env \"staging\" }}'\n"),
+			},
+			wantOut:          "",
+			wantErr:          true,
+			wantErrToContain: "Service/debug metadata.annotations.kyml.io/include-if: missing key \"Env\"",
+		},
+		{
+			name: "keys are not templated by default",
+			o: &tmplOptions{
+				values: map[string]string{
+					"Env": "staging",
+				},
+			},
+			args: args{
+				in: strings.NewReader("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n  labels:\n    \"{{.Env}}.kyml.io/tier\": \"{{.Env}}\"\n"),
+			},
+			wantOut: "---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  labels:\n    '{{.Env}}.kyml.io/tier': staging\n  name: config\n",
+			wantErr: false,
+		},
+		{
+			name: "template keys",
+			o: &tmplOptions{
+				templateKeys: true,
+				values: map[string]string{
+					"Env": "staging",
+				},
+			},
+			args: args{
+				in: strings.NewReader("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n  labels:\n    \"{{.Env}}.kyml.io/tier\": \"{{.Env}}\"\n"),
+			},
+			wantOut: "---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  labels:\n    staging.kyml.io/tier: staging\n  name: config\n",
+			wantErr: false,
+		},
+		{
+			name: "template keys with collision",
+			o: &tmplOptions{
+				templateKeys: true,
+				values: map[string]string{
+					"Env": "staging",
+				},
+			},
+			args: args{
+				in: strings.NewReader("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\ndata:\n  \"{{.Env}}\": a\n  staging: b\n"),
+			},
+			wantOut:          "",
+			wantErr:          true,
+			wantErrToContain: "ConfigMap/config: data.{{.Env}}: key is the same as \"staging\" after templating",
+		},
+		{
+			name: "templated names are sorted",
+			o: &tmplOptions{
+				values: map[string]string{
+					"Name": "app",
+					"Ns":   "production",
+				},
+			},
+			args: args{
+				in: strings.NewReader("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\ndata:\n  a: old\n---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\ndata:\n  a: new\n---\napiVersion: v1\nkind: Namespace\nmetadata:\n  name: \"{{.Ns}}\"\n---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: \"{{.Name}}-config\"\n"),
+			},
+			wantOut: "---\napiVersion: v1\nkind: Namespace\nmetadata:\n  name: production\n---\napiVersion: v1\ndata:\n  a: new\nkind: ConfigMap\nmetadata:\n  name: app\n---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app-config\n",
+			wantErr: false,
+		},
+		{
+			name: "templated names collide",
+			o: &tmplOptions{
+				values: map[string]string{
+					"Name": "app",
+				},
+			},
+			args: args{
+				in: strings.NewReader("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\ndata:\n  a: old\n---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: \"{{.Name}}\"\ndata:\n  a: new\n"),
+			},
+			wantOut:          "",
+			wantErr:          true,
+			wantErrToContain: "ConfigMap/{{.Name}}: has the same apiVersion, kind, namespace and name as ConfigMap/app after templating",
+		},
+		{
+			name: "templated names are validated",
+			o: &tmplOptions{
+				values: map[string]string{
+					"Branch": "Feature/X",
+				},
+			},
+			args: args{
+				in: strings.NewReader("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: \"app-{{.Branch}}\"\n"),
+			},
+			wantOut:          "",
+			wantErr:          true,
+			wantErrToContain: "ConfigMap/app-{{.Branch}}: metadata.name \"app-Feature/X\" is invalid after templating",
+		},
+		{
+			name: "values file doesn't exist",
+			o: &tmplOptions{
+				valuesFiles: []string{"values.yaml"},
+			},
+			args: args{
+				in: strings.NewReader(testManifestDeployment),
+			},
+			wantOut: "",
+			wantErr: true,
+		},
 		{
 			name: "invalid template",
 			o: &tmplOptions{
@@ -142,10 +583,94 @@ func Test_tmplOptions_Run(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			out := &bytes.Buffer{}
-			if err := tt.o.Run(tt.args.in, out); (err != nil) != tt.wantErr {
+			if tt.args.fs == nil {
+				tt.args.fs = fs.NewFakeFilesystem()
+			}
+			err := tt.o.Run(tt.args.in, out, tt.args.fs)
+			if (err != nil) != tt.wantErr {
 				t.Errorf("tmplOptions.Run() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			if err != nil && !strings.Contains(err.Error(), tt.wantErrToContain) {
+				t.Errorf("tmplOptions.Run() error = %v, wantErrToContain %v", err, tt.wantErrToContain)
+				return
+			}
+			if gotOut := out.String(); gotOut != tt.wantOut {
+				t.Errorf("tmplOptions.Run() = %v, want %v", gotOut, tt.wantOut)
+			}
+		})
+	}
+}
+
+func Test_tmplOptions_Run_valuesFromFd(t *testing.T) {
+	origOpenFd := openFd
+	defer func() { openFd = origOpenFd }()
+	openFd = func(fd int) (io.ReadCloser, error) {
+		switch fd {
+		case 3:
+			return ioutil.NopCloser(strings.NewReader("db:\n  password: hunter2\n")), nil
+		case 4:
+			return ioutil.NopCloser(strings.NewReader("password: [hunter2")), nil
+		default:
+			return nil, fmt.Errorf("invalid file descriptor %d", fd)
+		}
+	}
+
+	tests := []struct {
+		name             string
+		fds              []int
+		in               string
+		wantOut          string
+		wantErrToContain string
+	}{
+		{
+			name:    "values",
+			fds:     []int{3},
+			in:      "apiVersion: v1\nkind: Secret\nmetadata:\n  name: db\nstringData:\n  password: \"{{.db.password}}\"\n",
+			wantOut: "---\napiVersion: v1\nkind: Secret\nmetadata:\n  name: db\nstringData:\n  password: hunter2\n",
+		},
+		{
+			name:             "values are not printed in errors",
+			fds:              []int{3},
+			in:               "apiVersion: v1\nkind: Secret\nmetadata:\n  name: db\ndata:\n  password: \"{{.db.password | b64enc | int}}\"\n",
+			wantErrToContain: "error calling int: cannot convert string to an integer",
+		},
+		{
+			name:             "output of templates using values is masked",
+			fds:              []int{3},
+			in:               "apiVersion: v1\nkind: Secret\nmetadata:\n  name: db\n  annotations:\n    kyml.io/include-if: \"{{.db | toJson}}\"\n",
+			wantErrToContain: "annotation kyml.io/include-if must evaluate to true or false, got \"***\"",
+		},
+		{
+			name:             "invalid values",
+			fds:              []int{4},
+			in:               testManifestDeployment,
+			wantErrToContain: "error parsing values from file descriptor 4: values must be a YAML or JSON object",
+		},
+		{
+			name:             "invalid file descriptor",
+			fds:              []int{5},
+			in:               testManifestDeployment,
+			wantErrToContain: "invalid file descriptor 5",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &tmplOptions{valuesFds: tt.fds}
+			out := &bytes.Buffer{}
+			err := o.Run(strings.NewReader(tt.in), out, fs.NewFakeFilesystem())
+			if tt.wantErrToContain != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErrToContain) {
+					t.Errorf("tmplOptions.Run() error = %v, wantErrToContain %v", err, tt.wantErrToContain)
+				}
+				if err != nil && (strings.Contains(err.Error(), "hunter2") || strings.Contains(err.Error(), "aHVudGVyMg==")) {
+					t.Errorf("tmplOptions.Run() error = %v, contains secret", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("tmplOptions.Run() error = %v", err)
+			}
 			if gotOut := out.String(); gotOut != tt.wantOut {
 				t.Errorf("tmplOptions.Run() = %v, want %v", gotOut, tt.wantOut)
 			}
@@ -0,0 +1,64 @@
+package tmpl
+
+import (
+	"fmt"
+	"strconv"
+	"text/template/parse"
+)
+
+// typedValueFunc returns the name of the type conversion function ("int",
+// "float" or "bool") if the template consists of exactly one action, which
+// ends in a call to this function, e.g. "{{.replicas | int}}". Only in this
+// case the templated value is stored as a typed value instead of a string.
+// Text around the action is not allowed, so templates cannot produce
+// arbitrary YAML.
+func typedValueFunc(tree *parse.Tree) string {
+	if tree == nil || tree.Root == nil || len(tree.Root.Nodes) != 1 {
+		return ""
+	}
+
+	action, ok := tree.Root.Nodes[0].(*parse.ActionNode)
+	if !ok || len(action.Pipe.Decl) != 0 || len(action.Pipe.Cmds) == 0 {
+		return ""
+	}
+
+	cmd := action.Pipe.Cmds[len(action.Pipe.Cmds)-1]
+	if len(cmd.Args) == 0 {
+		return ""
+	}
+
+	ident, ok := cmd.Args[0].(*parse.IdentifierNode)
+	if !ok {
+		return ""
+	}
+
+	switch ident.Ident {
+	case "int", "float", "bool":
+		return ident.Ident
+	default:
+		return ""
+	}
+}
+
+// parseTypedValue parses the output of a template, which ends in the
+// specified type conversion function. Errors don't include the output, since
+// it can contain secrets.
+func parseTypedValue(typ, text string) (interface{}, error) {
+	var value interface{}
+	var err error
+	switch typ {
+	case "int":
+		value, err = strconv.ParseInt(text, 10, 64)
+	case "float":
+		value, err = strconv.ParseFloat(text, 64)
+	case "bool":
+		value, err = strconv.ParseBool(text)
+	default:
+		return nil, fmt.Errorf("unknown type %s", typ)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("template output is not a valid %s", typ)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,71 @@
+package tmpl
+
+import (
+	"testing"
+	"text/template"
+)
+
+func Test_typedValueFunc(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "int", text: "{{.replicas | int}}", want: "int"},
+		{name: "int with spaces", text: "{{ .replicas | int }}", want: "int"},
+		{name: "int as function", text: "{{int .replicas}}", want: "int"},
+		{name: "float", text: "{{.ratio | float}}", want: "float"},
+		{name: "bool", text: "{{.enabled | bool}}", want: "bool"},
+		{name: "no conversion", text: "{{.replicas}}", want: ""},
+		{name: "plain text", text: "3", want: ""},
+		{name: "conversion not last", text: "{{.replicas | int | quote}}", want: ""},
+		{name: "conversion as argument", text: "{{add (int .replicas)}}", want: ""},
+		{name: "text around action", text: "{{.replicas | int}}0", want: ""},
+		{name: "multiple actions", text: "{{.replicas | int}}{{.replicas | int}}", want: ""},
+		{name: "variable declaration", text: "{{$x := .replicas | int}}", want: ""},
+		{name: "if", text: "{{if .enabled}}{{.replicas | int}}{{end}}", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			funcs := template.FuncMap{"add": func(i int64) int64 { return i }}
+			tmpl, err := template.New(tt.name).Funcs(funcMap).Funcs(funcs).Parse(tt.text)
+			if err != nil {
+				t.Fatalf("error parsing template: %v", err)
+			}
+
+			if got := typedValueFunc(tmpl.Tree); got != tt.want {
+				t.Errorf("typedValueFunc() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseTypedValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		typ     string
+		text    string
+		want    interface{}
+		wantErr bool
+	}{
+		{name: "int", typ: "int", text: "3", want: int64(3)},
+		{name: "negative int", typ: "int", text: "-3", want: int64(-3)},
+		{name: "float", typ: "float", text: "0.5", want: 0.5},
+		{name: "float with exponent", typ: "float", text: "1e+21", want: 1e21},
+		{name: "bool", typ: "bool", text: "true", want: true},
+		{name: "invalid int", typ: "int", text: "3\nfoo: bar", wantErr: true},
+		{name: "unknown type", typ: "string", text: "3", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTypedValue(tt.typ, tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseTypedValue() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("parseTypedValue() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
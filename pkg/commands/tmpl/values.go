@@ -0,0 +1,229 @@
+package tmpl
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/frigus02/kyml/pkg/fs"
+
+	"sigs.k8s.io/yaml"
+)
+
+// loadValuesFile reads a YAML, JSON or dotenv file with values for the template
+// context. The format is detected using the file extension.
+func loadValuesFile(filename string, fs fs.Filesystem) (map[string]interface{}, error) {
+	data, err := fs.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open values file: %v", err)
+	}
+
+	var values map[string]interface{}
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml", ".json":
+		values, err = parseYAMLValues(data)
+	case ".env":
+		values, err = parseDotenvValues(data)
+	default:
+		return nil, fmt.Errorf("values file %s has unsupported format (supported are .yaml, .yml, .json and .env)", filename)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing values file %s: %v", filename, err)
+	}
+
+	return values, nil
+}
+
+// loadValueFile reads a file for an argument in the form key=path. The file
+// content is used as value without parsing it.
+func loadValueFile(arg string, fs fs.Filesystem) (string, string, error) {
+	parts := strings.SplitN(arg, "=", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("value file %q must be in the form key=path", arg)
+	}
+
+	data, err := fs.ReadFile(parts[1])
+	if err != nil {
+		return "", "", fmt.Errorf("cannot open value file: %v", err)
+	}
+
+	return parts[0], string(data), nil
+}
+
+// openFd opens the file descriptor for reading. It's a variable, so tests can
+// replace it.
+var openFd = func(fd int) (io.ReadCloser, error) {
+	f := os.NewFile(uintptr(fd), fmt.Sprintf("fd %d", fd))
+	if f == nil {
+		return nil, fmt.Errorf("invalid file descriptor %d", fd)
+	}
+
+	return f, nil
+}
+
+// loadValuesFromFd reads YAML or JSON values from the file descriptor, e.g.
+// from a secret store using process substitution. Errors don't include the
+// content, since it's usually secret.
+func loadValuesFromFd(fd int) (map[string]interface{}, error) {
+	f, err := openFd(fd)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read values from file descriptor %d: %v", fd, err)
+	}
+
+	values, err := parseYAMLValues(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing values from file descriptor %d: values must be a YAML or JSON object", fd)
+	}
+
+	return values, nil
+}
+
+// parseYAMLValues parses YAML or JSON values. Numbers keep their original
+// formatting when used in templates.
+func parseYAMLValues(data []byte) (map[string]interface{}, error) {
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if string(jsonData) == "null" {
+		return map[string]interface{}{}, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.UseNumber()
+
+	var values map[string]interface{}
+	if err := decoder.Decode(&values); err != nil {
+		return nil, fmt.Errorf("values must be an object: %v", err)
+	}
+
+	return values, nil
+}
+
+// parseDotenvValues parses lines in the format KEY=VALUE. Empty lines and
+// lines starting with # are ignored. Values may be quoted using single quotes,
+// which are taken literally, or double quotes, which support escape sequences.
+func parseDotenvValues(data []byte) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line = strings.TrimPrefix(line, "export ")
+		parts := strings.SplitN(line, "=", 2)
+		key := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || key == "" {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", lineNumber)
+		}
+
+		value := strings.TrimSpace(parts[1])
+		switch {
+		case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'':
+			value = value[1 : len(value)-1]
+		case len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"':
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid quoted value", lineNumber)
+			}
+			value = unquoted
+		}
+
+		values[key] = value
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// mergeValues merges src into dst. Nested objects are merged recursively.
+// Other values in src replace the ones in dst.
+func mergeValues(dst, src map[string]interface{}) {
+	for key, value := range src {
+		srcMap, srcIsMap := value.(map[string]interface{})
+		dstMap, dstIsMap := dst[key].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			mergeValues(dstMap, srcMap)
+		} else if srcIsMap {
+			copied := make(map[string]interface{}, len(srcMap))
+			mergeValues(copied, srcMap)
+			dst[key] = copied
+		} else {
+			dst[key] = value
+		}
+	}
+}
+
+// setValue sets the value at the dot separated key, e.g. "image.tag", creating
+// nested objects as required.
+func setValue(values map[string]interface{}, key string, value interface{}) {
+	parts := strings.Split(key, ".")
+	for _, part := range parts[:len(parts)-1] {
+		nested, ok := values[part].(map[string]interface{})
+		if !ok {
+			nested = make(map[string]interface{})
+			values[part] = nested
+		}
+
+		values = nested
+	}
+
+	values[parts[len(parts)-1]] = value
+}
+
+// hasValue returns false if an object on the path to the dot separated key
+// doesn't contain the next part of the key. Paths through other values, e.g.
+// lists, are not checked and always return true.
+func hasValue(values map[string]interface{}, key string) bool {
+	var value interface{} = values
+	for _, part := range strings.Split(key, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return true
+		}
+
+		if value, ok = m[part]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// valueKeys returns the dot separated keys of all values, which are not
+// objects, sorted alphabetically. Empty objects are returned as values.
+func valueKeys(values map[string]interface{}) []string {
+	var keys []string
+	for key, value := range values {
+		if m, ok := value.(map[string]interface{}); ok && len(m) > 0 {
+			for _, nested := range valueKeys(m) {
+				keys = append(keys, key+"."+nested)
+			}
+		} else {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,174 @@
+package tmpl
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/frigus02/kyml/pkg/fs"
+)
+
+func Test_loadValuesFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		data     string
+		want     map[string]interface{}
+		wantErr  bool
+	}{
+		{
+			name:     "yaml",
+			filename: "values.yaml",
+			data:     "image:\n  tag: latest\nreplicas: 3\n",
+			want: map[string]interface{}{
+				"image":    map[string]interface{}{"tag": "latest"},
+				"replicas": json.Number("3"),
+			},
+		},
+		{
+			name:     "json",
+			filename: "values.JSON",
+			data:     `{"image": {"tag": "latest"}, "enabled": true}`,
+			want: map[string]interface{}{
+				"image":   map[string]interface{}{"tag": "latest"},
+				"enabled": true,
+			},
+		},
+		{
+			name:     "empty yaml",
+			filename: "values.yml",
+			data:     "",
+			want:     map[string]interface{}{},
+		},
+		{
+			name:     "yaml list",
+			filename: "values.yaml",
+			data:     "- a\n- b\n",
+			wantErr:  true,
+		},
+		{
+			name:     "dotenv",
+			filename: "values.env",
+			data:     "# comment\n\nA=1\nexport B = two words \nC='single ''quoted'\nD=\"double\\nquoted\"\nE=\n",
+			want: map[string]interface{}{
+				"A": "1",
+				"B": "two words",
+				"C": "single ''quoted",
+				"D": "double\nquoted",
+				"E": "",
+			},
+		},
+		{
+			name:     "invalid dotenv",
+			filename: "values.env",
+			data:     "A=1\nB\n",
+			wantErr:  true,
+		},
+		{
+			name:     "unsupported format",
+			filename: "values.txt",
+			data:     "A=1\n",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := fs.NewFakeFilesystemWithFiles(map[string]string{tt.filename: tt.data})
+			got, err := loadValuesFile(tt.filename, fs)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("loadValuesFile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("loadValuesFile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_mergeValues(t *testing.T) {
+	dst := map[string]interface{}{
+		"image":    map[string]interface{}{"repository": "kyml/hello", "tag": "1.0"},
+		"replicas": 1,
+		"env":      "staging",
+	}
+	src := map[string]interface{}{
+		"image":    map[string]interface{}{"tag": "2.0"},
+		"replicas": map[string]interface{}{"min": 2},
+		"debug":    true,
+	}
+
+	mergeValues(dst, src)
+
+	want := map[string]interface{}{
+		"image":    map[string]interface{}{"repository": "kyml/hello", "tag": "2.0"},
+		"replicas": map[string]interface{}{"min": 2},
+		"env":      "staging",
+		"debug":    true,
+	}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("mergeValues() = %v, want %v", dst, want)
+	}
+}
+
+func Test_setValue(t *testing.T) {
+	values := map[string]interface{}{
+		"image": map[string]interface{}{"repository": "kyml/hello"},
+		"name":  "app",
+	}
+
+	setValue(values, "image.tag", "latest")
+	setValue(values, "name.first", "x")
+	setValue(values, "flat", "y")
+
+	want := map[string]interface{}{
+		"image": map[string]interface{}{"repository": "kyml/hello", "tag": "latest"},
+		"name":  map[string]interface{}{"first": "x"},
+		"flat":  "y",
+	}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("setValue() = %v, want %v", values, want)
+	}
+}
+
+func Test_hasValue(t *testing.T) {
+	values := map[string]interface{}{
+		"tag":   "latest",
+		"image": map[string]interface{}{"repository": "kyml/hello"},
+		"list":  []interface{}{"a"},
+		"empty": "",
+	}
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{key: "tag", want: true},
+		{key: "empty", want: true},
+		{key: "image", want: true},
+		{key: "image.repository", want: true},
+		{key: "image.tag", want: false},
+		{key: "missing", want: false},
+		{key: "missing.tag", want: false},
+		{key: "list.anything", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := hasValue(values, tt.key); got != tt.want {
+				t.Errorf("hasValue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_valueKeys(t *testing.T) {
+	values := map[string]interface{}{
+		"tag":   "latest",
+		"image": map[string]interface{}{"repository": "kyml/hello", "pull": map[string]interface{}{"policy": "Always"}},
+		"empty": map[string]interface{}{},
+		"list":  []interface{}{"a"},
+	}
+	want := []string{"empty", "image.pull.policy", "image.repository", "list", "tag"}
+	if got := valueKeys(values); !reflect.DeepEqual(got, want) {
+		t.Errorf("valueKeys() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,134 @@
+package validate
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/frigus02/kyml/pkg/cat"
+	"github.com/frigus02/kyml/pkg/fs"
+	"github.com/frigus02/kyml/pkg/k8syaml"
+	"github.com/frigus02/kyml/pkg/kubeversion"
+	"github.com/frigus02/kyml/pkg/validate"
+	"github.com/spf13/cobra"
+)
+
+type validateOptions struct {
+	kubernetesVersion    string
+	schemaFiles          []string
+	ignoreMissingSchemas bool
+}
+
+// NewCmdValidate creates a new validate command.
+func NewCmdValidate(in io.Reader, out io.Writer, fs fs.Filesystem) *cobra.Command {
+	var o validateOptions
+
+	var supportedVersions []string
+	for _, v := range validate.BundledVersions() {
+		supportedVersions = append(supportedVersions, v.String())
+	}
+
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validate Kubernetes YAML files against the Kubernetes OpenAPI schemas",
+		Long: `Validate Kubernetes YAML documents against the OpenAPI schemas of a Kubernetes version. Data is read from stdin. If all documents are valid, they are printed to stdout, so they can be piped into followup commands like "kubectl apply". Otherwise all problems are printed to stderr and the command exits with a non-zero exit code.
+
+Schemas for the following Kubernetes versions are bundled with kyml, so no cluster connection is required: ` + strings.Join(supportedVersions, ", ") + `.
+
+Schemas for custom resources are taken from CustomResourceDefinitions in the input. Additional schemas can be specified using "--schema-file". A schema file is either a YAML file containing CustomResourceDefinitions or an OpenAPI v3 document with schemas in "components.schemas", which have the "x-kubernetes-group-version-kind" extension.`,
+		Example: `  # Validate manifests before deploying them
+  kyml cat production/* |
+    kyml validate --kubernetes-version 1.29 |
+    kubectl apply -f -
+
+  # Use schemas of CRDs, which are installed separately
+  kyml cat production/* | kyml validate --schema-file crds/cert-manager.yaml`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := o.Validate(args)
+			if err != nil {
+				return err
+			}
+
+			return o.Run(in, out, fs)
+		},
+	}
+
+	cmd.Flags().StringVar(&o.kubernetesVersion, "kubernetes-version", validate.LatestBundledVersion().String(), "Kubernetes version to validate against")
+	cmd.Flags().StringArrayVar(&o.schemaFiles, "schema-file", nil, "Add schemas from a file containing CustomResourceDefinitions or an OpenAPI v3 document")
+	cmd.Flags().BoolVar(&o.ignoreMissingSchemas, "ignore-missing-schemas", false, "Skip documents, for which no schema is known, instead of failing")
+
+	_ = cmd.MarkFlagFilename("schema-file")
+
+	return cmd
+}
+
+// Validate validates validate command.
+func (o *validateOptions) Validate(args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("this command takes no positional arguments")
+	}
+
+	if _, err := kubeversion.Parse(o.kubernetesVersion); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Run runs validate command.
+func (o *validateOptions) Run(in io.Reader, out io.Writer, fs fs.Filesystem) error {
+	version, err := kubeversion.Parse(o.kubernetesVersion)
+	if err != nil {
+		return err
+	}
+
+	validator, err := validate.NewValidator(version)
+	if err != nil {
+		return err
+	}
+
+	for _, schemaFile := range o.schemaFiles {
+		data, err := fs.ReadFile(schemaFile)
+		if err != nil {
+			return err
+		}
+
+		if err = validator.AddSchemaFile(data); err != nil {
+			return fmt.Errorf("%s: %v", schemaFile, err)
+		}
+	}
+
+	documents, err := cat.StreamDecodeOnly(in)
+	if err != nil {
+		return err
+	}
+
+	for _, doc := range documents {
+		if validate.IsCRD(doc) {
+			if err = validator.AddCRD(doc); err != nil {
+				return err
+			}
+		}
+	}
+
+	var problems []string
+	for _, doc := range documents {
+		fieldErrs, err := validator.Validate(doc)
+		if err != nil {
+			if !o.ignoreMissingSchemas {
+				problems = append(problems, fmt.Sprintf("%s: %v", k8syaml.ResourceName(doc), err))
+			}
+			continue
+		}
+
+		for _, fieldErr := range fieldErrs {
+			problems = append(problems, fmt.Sprintf("%s: %v", k8syaml.ResourceName(doc), fieldErr))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("documents are not valid for Kubernetes %s\n\n%s", version, strings.Join(problems, "\n"))
+	}
+
+	return k8syaml.Encode(out, documents)
+}
@@ -0,0 +1,193 @@
+package validate
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/frigus02/kyml/pkg/fs"
+)
+
+var testManifestDeployment = `---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: the-deployment
+spec:
+  selector:
+    matchLabels:
+      app: hello
+  template:
+    spec:
+      containers:
+      - image: kyml/hello
+        name: the-container
+`
+
+var testManifestDeploymentTypo = `---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: the-deployment
+spec:
+  selector:
+    matchLabels:
+      app: hello
+  template:
+    spec:
+      containers:
+      - image: kyml/hello
+        name: the-container
+        prots:
+        - containerPort: 80
+`
+
+var testManifestCRDAndCustomResource = `---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: crontabs.stable.example.com
+spec:
+  group: stable.example.com
+  names:
+    kind: CronTab
+    plural: crontabs
+  scope: Namespaced
+  versions:
+  - name: v1
+    schema:
+      openAPIV3Schema:
+        properties:
+          spec:
+            properties:
+              cronSpec:
+                type: string
+            type: object
+        type: object
+    served: true
+    storage: true
+---
+apiVersion: stable.example.com/v1
+kind: CronTab
+metadata:
+  name: the-crontab
+spec:
+  cronSpec: '* * * * */5'
+`
+
+var testManifestCustomResource = `---
+apiVersion: stable.example.com/v1
+kind: CronTab
+metadata:
+  name: the-crontab
+spec:
+  cronSpec: '* * * * */5'
+`
+
+func Test_validateOptions_Validate(t *testing.T) {
+	type args struct {
+		args []string
+	}
+	tests := []struct {
+		name    string
+		o       *validateOptions
+		args    args
+		wantErr bool
+	}{
+		{
+			name:    "error if any args",
+			o:       &validateOptions{kubernetesVersion: "1.29"},
+			args:    args{args: []string{"foo"}},
+			wantErr: true,
+		},
+		{
+			name:    "error if version is invalid",
+			o:       &validateOptions{kubernetesVersion: "latest"},
+			args:    args{args: []string{}},
+			wantErr: true,
+		},
+		{
+			name:    "success",
+			o:       &validateOptions{kubernetesVersion: "1.29"},
+			args:    args{args: []string{}},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.o.Validate(tt.args.args); (err != nil) != tt.wantErr {
+				t.Errorf("validateOptions.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_validateOptions_Run(t *testing.T) {
+	type args struct {
+		in io.Reader
+	}
+	tests := []struct {
+		name             string
+		o                *validateOptions
+		args             args
+		wantOut          string
+		wantErr          bool
+		wantErrToContain string
+	}{
+		{
+			name:    "valid documents are printed",
+			o:       &validateOptions{kubernetesVersion: "1.29"},
+			args:    args{strings.NewReader(testManifestDeployment)},
+			wantOut: testManifestDeployment,
+			wantErr: false,
+		},
+		{
+			name:             "unknown field",
+			o:                &validateOptions{kubernetesVersion: "1.29"},
+			args:             args{strings.NewReader(testManifestDeploymentTypo)},
+			wantOut:          "",
+			wantErr:          true,
+			wantErrToContain: "Deployment/the-deployment: spec.template.spec.containers[0].prots: unknown field",
+		},
+		{
+			name:    "schema from crd in stream",
+			o:       &validateOptions{kubernetesVersion: "1.29"},
+			args:    args{strings.NewReader(testManifestCRDAndCustomResource)},
+			wantOut: testManifestCRDAndCustomResource,
+			wantErr: false,
+		},
+		{
+			name:             "missing schema",
+			o:                &validateOptions{kubernetesVersion: "1.29"},
+			args:             args{strings.NewReader(testManifestCustomResource)},
+			wantOut:          "",
+			wantErr:          true,
+			wantErrToContain: "CronTab/the-crontab: no schema found for stable.example.com/v1, Kind=CronTab",
+		},
+		{
+			name:    "missing schema ignored",
+			o:       &validateOptions{kubernetesVersion: "1.29", ignoreMissingSchemas: true},
+			args:    args{strings.NewReader(testManifestCustomResource)},
+			wantOut: testManifestCustomResource,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			err := tt.o.Run(tt.args.in, out, fs.NewFakeFilesystem())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateOptions.Run() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil && !strings.Contains(err.Error(), tt.wantErrToContain) {
+				t.Errorf("validateOptions.Run() error = %v, wantErrToContain %v", err, tt.wantErrToContain)
+				return
+			}
+			if gotOut := out.String(); gotOut != tt.wantOut {
+				t.Errorf("validateOptions.Run() = %v, want %v", gotOut, tt.wantOut)
+			}
+		})
+	}
+}
@@ -0,0 +1,275 @@
+package confighash
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/frigus02/kyml/pkg/k8syaml"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ChecksumAnnotation is the annotation written into pod templates in
+// annotation mode.
+const ChecksumAnnotation = "kyml.io/config-checksum"
+
+// DisableAnnotation excludes a ConfigMap or Secret from hashing, if set to
+// "false".
+const DisableAnnotation = "kyml.io/content-hash"
+
+// configKey identifies a ConfigMap or Secret.
+type configKey struct {
+	kind      string
+	namespace string
+	name      string
+}
+
+// Hash returns a hash of the content of the ConfigMap or Secret. It includes
+// the kind, name, type and data, but not labels or annotations.
+func Hash(doc *unstructured.Unstructured) string {
+	content := map[string]interface{}{
+		"kind": doc.GetKind(),
+		"name": doc.GetName(),
+	}
+	for _, field := range []string{"type", "data", "binaryData", "stringData"} {
+		if value, ok := doc.Object[field]; ok {
+			content[field] = value
+		}
+	}
+
+	// Maps are encoded with sorted keys, so the hash is stable.
+	data, _ := json.Marshal(content)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:10]
+}
+
+// AppendNameSuffix appends the content hash to the names of ConfigMaps and
+// Secrets and updates all references to them in the same namespace: in pods,
+// workloads, Ingresses and ServiceAccounts. ConfigMaps and Secrets without any
+// of these references keep their names, because they may be referenced from
+// places kyml doesn't know about. Use cat.Normalize afterwards to sort the
+// documents.
+func AppendNameSuffix(documents []*unstructured.Unstructured) {
+	hashable := make(map[configKey]*unstructured.Unstructured)
+	for _, doc := range documents {
+		if isHashable(doc) {
+			hashable[configKey{doc.GetKind(), doc.GetNamespace(), doc.GetName()}] = doc
+		}
+	}
+
+	renamed := make(map[configKey]string)
+	for _, doc := range documents {
+		walkDocumentReferences(doc, func(kind string, ref map[string]interface{}, field string) {
+			name, _ := ref[field].(string)
+			key := configKey{kind, doc.GetNamespace(), name}
+			if config, ok := hashable[key]; ok {
+				if _, ok := renamed[key]; !ok {
+					renamed[key] = name + "-" + Hash(config)
+				}
+
+				ref[field] = renamed[key]
+			}
+		})
+	}
+
+	for key, name := range renamed {
+		hashable[key].SetName(name)
+	}
+}
+
+// WriteChecksumAnnotation writes a checksum of all ConfigMaps and Secrets
+// referenced by a pod or workload into the annotation kyml.io/config-checksum
+// of the pod template. Only ConfigMaps and Secrets in the documents are
+// considered. If a workload doesn't reference any, the annotation is not
+// written.
+func WriteChecksumAnnotation(documents []*unstructured.Unstructured) {
+	hashes := make(map[configKey]string)
+	for _, doc := range documents {
+		if isHashable(doc) {
+			hashes[configKey{doc.GetKind(), doc.GetNamespace(), doc.GetName()}] = Hash(doc)
+		}
+	}
+
+	for _, doc := range documents {
+		podSpec := podSpec(doc)
+		if podSpec == nil {
+			continue
+		}
+
+		refs := make(map[string]bool)
+		walkReferences(podSpec, func(kind string, ref map[string]interface{}, field string) {
+			name, _ := ref[field].(string)
+			if hash, ok := hashes[configKey{kind, doc.GetNamespace(), name}]; ok {
+				refs[kind+"/"+name+":"+hash] = true
+			}
+		})
+		if len(refs) == 0 {
+			continue
+		}
+
+		sorted := make([]string, 0, len(refs))
+		for ref := range refs {
+			sorted = append(sorted, ref)
+		}
+		sort.Strings(sorted)
+		sum := sha256.Sum256([]byte(strings.Join(sorted, "\n")))
+
+		path := podMetadataPath(doc)
+		annotations, _, _ := unstructured.NestedStringMap(doc.Object, append(path, "annotations")...)
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		annotations[ChecksumAnnotation] = hex.EncodeToString(sum[:])
+		_ = unstructured.SetNestedStringMap(doc.Object, annotations, append(path, "annotations")...)
+	}
+}
+
+func isHashable(doc *unstructured.Unstructured) bool {
+	gvk := doc.GroupVersionKind()
+	if gvk.Group != "" || (gvk.Kind != "ConfigMap" && gvk.Kind != "Secret") {
+		return false
+	}
+
+	if doc.GetAnnotations()[DisableAnnotation] == "false" {
+		return false
+	}
+
+	// Service account tokens are filled by Kubernetes and referenced by name.
+	if secretType, _, _ := unstructured.NestedString(doc.Object, "type"); secretType == "kubernetes.io/service-account-token" {
+		return false
+	}
+
+	return true
+}
+
+// podSpecPath returns the path to the pod spec in workloads and pods.
+func podSpecPath(doc *unstructured.Unstructured) []string {
+	gvk := doc.GroupVersionKind()
+	if gvk.Group == "" && gvk.Kind == "Pod" {
+		return []string{"spec"}
+	}
+
+	return k8syaml.PathToPodSpec(gvk)
+}
+
+// podMetadataPath returns the path to the metadata of the pod template in
+// workloads or the metadata of pods.
+func podMetadataPath(doc *unstructured.Unstructured) []string {
+	path := podSpecPath(doc)
+	return append(append([]string{}, path[:len(path)-1]...), "metadata")
+}
+
+// podSpec returns the pod spec of pods and workloads or nil for other
+// documents. Changes to the returned map change the document.
+func podSpec(doc *unstructured.Unstructured) map[string]interface{} {
+	path := podSpecPath(doc)
+	if path == nil {
+		return nil
+	}
+
+	m := doc.Object
+	for _, field := range path {
+		var ok bool
+		if m, ok = m[field].(map[string]interface{}); !ok {
+			return nil
+		}
+	}
+
+	return m
+}
+
+// referenceVisitor is called for every reference to a ConfigMap or Secret.
+// The name of the referenced resource is in ref[field].
+type referenceVisitor func(kind string, ref map[string]interface{}, field string)
+
+// walkDocumentReferences calls visit for all references to ConfigMaps and
+// Secrets in the document. Besides pod specs these are TLS Secrets of
+// Ingresses and Secrets of ServiceAccounts.
+func walkDocumentReferences(doc *unstructured.Unstructured, visit referenceVisitor) {
+	if podSpec := podSpec(doc); podSpec != nil {
+		walkReferences(podSpec, visit)
+		return
+	}
+
+	gvk := doc.GroupVersionKind()
+	switch {
+	case gvk.Kind == "Ingress" && (gvk.Group == "networking.k8s.io" || gvk.Group == "extensions"):
+		spec, _ := doc.Object["spec"].(map[string]interface{})
+		for _, tls := range mapsInSlice(spec["tls"]) {
+			if _, ok := tls["secretName"].(string); ok {
+				visit("Secret", tls, "secretName")
+			}
+		}
+	case gvk.Kind == "ServiceAccount" && gvk.Group == "":
+		for _, field := range []string{"secrets", "imagePullSecrets"} {
+			for _, secret := range mapsInSlice(doc.Object[field]) {
+				if _, ok := secret["name"].(string); ok {
+					visit("Secret", secret, "name")
+				}
+			}
+		}
+	}
+}
+
+func walkReferences(podSpec map[string]interface{}, visit referenceVisitor) {
+	visitRef := func(kind string, parent map[string]interface{}, key, field string) {
+		if ref, ok := parent[key].(map[string]interface{}); ok {
+			if _, ok := ref[field].(string); ok {
+				visit(kind, ref, field)
+			}
+		}
+	}
+
+	for _, volume := range mapsInSlice(podSpec["volumes"]) {
+		visitRef("ConfigMap", volume, "configMap", "name")
+		visitRef("Secret", volume, "secret", "secretName")
+
+		if csi, ok := volume["csi"].(map[string]interface{}); ok {
+			visitRef("Secret", csi, "nodePublishSecretRef", "name")
+		}
+
+		if projected, ok := volume["projected"].(map[string]interface{}); ok {
+			for _, source := range mapsInSlice(projected["sources"]) {
+				visitRef("ConfigMap", source, "configMap", "name")
+				visitRef("Secret", source, "secret", "name")
+			}
+		}
+	}
+
+	for _, containersField := range []string{"initContainers", "containers", "ephemeralContainers"} {
+		for _, container := range mapsInSlice(podSpec[containersField]) {
+			for _, envFrom := range mapsInSlice(container["envFrom"]) {
+				visitRef("ConfigMap", envFrom, "configMapRef", "name")
+				visitRef("Secret", envFrom, "secretRef", "name")
+			}
+
+			for _, env := range mapsInSlice(container["env"]) {
+				if valueFrom, ok := env["valueFrom"].(map[string]interface{}); ok {
+					visitRef("ConfigMap", valueFrom, "configMapKeyRef", "name")
+					visitRef("Secret", valueFrom, "secretKeyRef", "name")
+				}
+			}
+		}
+	}
+
+	for _, pullSecret := range mapsInSlice(podSpec["imagePullSecrets"]) {
+		if _, ok := pullSecret["name"].(string); ok {
+			visit("Secret", pullSecret, "name")
+		}
+	}
+}
+
+func mapsInSlice(value interface{}) []map[string]interface{} {
+	slice, _ := value.([]interface{})
+	var maps []map[string]interface{}
+	for _, item := range slice {
+		if m, ok := item.(map[string]interface{}); ok {
+			maps = append(maps, m)
+		}
+	}
+
+	return maps
+}
@@ -0,0 +1,328 @@
+package confighash
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/frigus02/kyml/pkg/k8syaml"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var testManifests = `---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  a: "1"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  namespace: other
+data:
+  a: "1"
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: secret
+data:
+  b: Mg==
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: pull
+type: kubernetes.io/dockerconfigjson
+data:
+  .dockerconfigjson: e30=
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: token
+type: kubernetes.io/service-account-token
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: static
+  annotations:
+    kyml.io/content-hash: "false"
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    metadata:
+      annotations:
+        existing: annotation
+    spec:
+      imagePullSecrets:
+      - name: pull
+      volumes:
+      - name: config
+        configMap:
+          name: config
+      - name: secret
+        secret:
+          secretName: secret
+      - name: projected
+        projected:
+          sources:
+          - configMap:
+              name: config
+          - secret:
+              name: secret
+      - name: static
+        configMap:
+          name: static
+      initContainers:
+      - name: init
+        envFrom:
+        - secretRef:
+            name: secret
+      containers:
+      - name: app
+        envFrom:
+        - configMapRef:
+            name: config
+        env:
+        - name: A
+          valueFrom:
+            configMapKeyRef:
+              name: config
+              key: a
+        - name: B
+          valueFrom:
+            secretKeyRef:
+              name: secret
+              key: b
+        - name: EXTERNAL
+          valueFrom:
+            secretKeyRef:
+              name: external
+              key: c
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: pod
+  namespace: other
+spec:
+  containers:
+  - name: app
+    envFrom:
+    - configMapRef:
+        name: config
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: job
+spec:
+  template:
+    spec:
+      containers:
+      - name: job
+        image: kyml/job
+`
+
+func mustDecode(t *testing.T, yaml string) []*unstructured.Unstructured {
+	docs, err := k8syaml.Decode(strings.NewReader(yaml))
+	if err != nil {
+		t.Fatalf("error decoding test manifests: %v", err)
+	}
+
+	return docs
+}
+
+func mustEncode(t *testing.T, docs []*unstructured.Unstructured) string {
+	var buf bytes.Buffer
+	if err := k8syaml.Encode(&buf, docs); err != nil {
+		t.Fatalf("error encoding documents: %v", err)
+	}
+
+	return buf.String()
+}
+
+func TestHash(t *testing.T) {
+	docs := mustDecode(t, testManifests)
+
+	// The hash must stay stable between versions of kyml, otherwise updating
+	// kyml renames all ConfigMaps and Secrets.
+	if got, want := Hash(docs[0]), "627100a68e"; got != want {
+		t.Errorf("Hash() = %v, want %v", got, want)
+	}
+
+	if Hash(docs[0]) != Hash(docs[1]) {
+		t.Errorf("Hash() differs for ConfigMaps with the same name and data in different namespaces")
+	}
+
+	changed := docs[0].DeepCopy()
+	changed.Object["data"] = map[string]interface{}{"a": "2"}
+	if Hash(docs[0]) == Hash(changed) {
+		t.Errorf("Hash() is the same for ConfigMaps with different data")
+	}
+
+	annotated := docs[0].DeepCopy()
+	annotated.SetAnnotations(map[string]string{"note": "hi"})
+	if Hash(docs[0]) != Hash(annotated) {
+		t.Errorf("Hash() differs for ConfigMaps with different annotations")
+	}
+}
+
+func TestAppendNameSuffix(t *testing.T) {
+	docs := mustDecode(t, testManifests)
+	config, secret, pull := Hash(docs[0]), Hash(docs[2]), Hash(docs[3])
+
+	AppendNameSuffix(docs)
+
+	got := mustEncode(t, docs)
+	for _, want := range []string{
+		"  name: config-" + config + "\n",
+		"  name: config-" + config + "\n  namespace: other\n",
+		"  name: secret-" + secret + "\n",
+		"  name: pull-" + pull + "\n",
+		"  name: token\n",
+		"  name: static\n",
+		"      - name: pull-" + pull + "\n",
+		"      - configMap:\n          name: config-" + config + "\n",
+		"        secret:\n          secretName: secret-" + secret + "\n",
+		"          - configMap:\n              name: config-" + config + "\n",
+		"          - secret:\n              name: secret-" + secret + "\n",
+		"      - configMap:\n          name: static\n",
+		"        - secretRef:\n            name: secret-" + secret + "\n",
+		"        - configMapRef:\n            name: config-" + config + "\n",
+		"            configMapKeyRef:\n              key: a\n              name: config-" + config + "\n",
+		"            secretKeyRef:\n              key: b\n              name: secret-" + secret + "\n",
+		"            secretKeyRef:\n              key: c\n              name: external\n",
+		"    - configMapRef:\n        name: config-" + config + "\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("AppendNameSuffix() = %v, want to contain %v", got, want)
+		}
+	}
+}
+
+func TestAppendNameSuffixOtherReferences(t *testing.T) {
+	docs := mustDecode(t, `---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: tls
+type: kubernetes.io/tls
+data:
+  tls.crt: YQ==
+  tls.key: Yg==
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: regcred
+type: kubernetes.io/dockerconfigjson
+data:
+  .dockerconfigjson: e30=
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: csi
+data:
+  token: Yw==
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: unused
+data:
+  a: "1"
+---
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: ingress
+spec:
+  tls:
+  - hosts:
+    - example.com
+    secretName: tls
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: sa
+imagePullSecrets:
+- name: regcred
+secrets:
+- name: regcred
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: pod
+spec:
+  volumes:
+  - name: csi
+    csi:
+      driver: example.com/csi
+      nodePublishSecretRef:
+        name: csi
+`)
+	tls, regcred, csi := Hash(docs[0]), Hash(docs[1]), Hash(docs[2])
+
+	AppendNameSuffix(docs)
+
+	got := mustEncode(t, docs)
+	for _, want := range []string{
+		"  name: tls-" + tls + "\n",
+		"  name: regcred-" + regcred + "\n",
+		"  name: csi-" + csi + "\n",
+		"  name: unused\n",
+		"    secretName: tls-" + tls + "\n",
+		"imagePullSecrets:\n- name: regcred-" + regcred + "\n",
+		"secrets:\n- name: regcred-" + regcred + "\n",
+		"      nodePublishSecretRef:\n        name: csi-" + csi + "\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("AppendNameSuffix() = %v, want to contain %v", got, want)
+		}
+	}
+}
+
+func TestWriteChecksumAnnotation(t *testing.T) {
+	docs := mustDecode(t, testManifests)
+
+	WriteChecksumAnnotation(docs)
+
+	deployment, _, _ := unstructured.NestedStringMap(docs[6].Object, "spec", "template", "metadata", "annotations")
+	if deployment["existing"] != "annotation" || len(deployment[ChecksumAnnotation]) != 64 {
+		t.Errorf("WriteChecksumAnnotation() deployment annotations = %v", deployment)
+	}
+
+	if pod := docs[7].GetAnnotations(); len(pod[ChecksumAnnotation]) != 64 {
+		t.Errorf("WriteChecksumAnnotation() pod annotations = %v", pod)
+	}
+
+	if _, found, _ := unstructured.NestedMap(docs[8].Object, "spec", "template", "metadata"); found {
+		t.Errorf("WriteChecksumAnnotation() wrote annotation to job without references")
+	}
+
+	if docs[0].GetName() != "config" {
+		t.Errorf("WriteChecksumAnnotation() renamed ConfigMap to %v", docs[0].GetName())
+	}
+
+	before := deployment[ChecksumAnnotation]
+	docs = mustDecode(t, strings.Replace(testManifests, "b: Mg==", "b: Mw==", 1))
+	WriteChecksumAnnotation(docs)
+	after, _, _ := unstructured.NestedString(docs[6].Object, "spec", "template", "metadata", "annotations", ChecksumAnnotation)
+	if before == after {
+		t.Errorf("WriteChecksumAnnotation() checksum didn't change after changing a referenced Secret")
+	}
+}
@@ -0,0 +1,103 @@
+package deprecation
+
+import (
+	"strings"
+
+	"github.com/frigus02/kyml/pkg/kubeversion"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// The list is based on the Kubernetes deprecated API migration guide. Only
+// APIs, which are commonly found in manifests, are included.
+//
+// See: https://kubernetes.io/docs/reference/using-api/deprecation-guide/
+var apis = []API{
+	// Removed in 1.16
+	deprecatedAPI("extensions", "v1beta1", "DaemonSet", "1.9", "1.16", "apps/v1"),
+	deprecatedAPI("extensions", "v1beta1", "Deployment", "1.9", "1.16", "apps/v1"),
+	deprecatedAPI("extensions", "v1beta1", "ReplicaSet", "1.9", "1.16", "apps/v1"),
+	deprecatedAPI("extensions", "v1beta1", "NetworkPolicy", "1.9", "1.16", "networking.k8s.io/v1"),
+	deprecatedAPI("extensions", "v1beta1", "PodSecurityPolicy", "1.10", "1.16", "policy/v1beta1"),
+	deprecatedAPI("apps", "v1beta1", "Deployment", "1.9", "1.16", "apps/v1"),
+	deprecatedAPI("apps", "v1beta1", "StatefulSet", "1.9", "1.16", "apps/v1"),
+	deprecatedAPI("apps", "v1beta2", "DaemonSet", "1.9", "1.16", "apps/v1"),
+	deprecatedAPI("apps", "v1beta2", "Deployment", "1.9", "1.16", "apps/v1"),
+	deprecatedAPI("apps", "v1beta2", "ReplicaSet", "1.9", "1.16", "apps/v1"),
+	deprecatedAPI("apps", "v1beta2", "StatefulSet", "1.9", "1.16", "apps/v1"),
+
+	// Removed in 1.22
+	deprecatedAPI("admissionregistration.k8s.io", "v1beta1", "MutatingWebhookConfiguration", "1.16", "1.22", "admissionregistration.k8s.io/v1"),
+	deprecatedAPI("admissionregistration.k8s.io", "v1beta1", "ValidatingWebhookConfiguration", "1.16", "1.22", "admissionregistration.k8s.io/v1"),
+	deprecatedAPI("apiextensions.k8s.io", "v1beta1", "CustomResourceDefinition", "1.16", "1.22", "apiextensions.k8s.io/v1"),
+	deprecatedAPI("apiregistration.k8s.io", "v1beta1", "APIService", "1.19", "1.22", "apiregistration.k8s.io/v1"),
+	deprecatedAPI("certificates.k8s.io", "v1beta1", "CertificateSigningRequest", "1.19", "1.22", "certificates.k8s.io/v1"),
+	deprecatedAPI("coordination.k8s.io", "v1beta1", "Lease", "1.19", "1.22", "coordination.k8s.io/v1"),
+	deprecatedAPI("extensions", "v1beta1", "Ingress", "1.14", "1.22", "networking.k8s.io/v1"),
+	deprecatedAPI("networking.k8s.io", "v1beta1", "Ingress", "1.19", "1.22", "networking.k8s.io/v1"),
+	deprecatedAPI("networking.k8s.io", "v1beta1", "IngressClass", "1.19", "1.22", "networking.k8s.io/v1"),
+	deprecatedAPI("rbac.authorization.k8s.io", "v1beta1", "ClusterRole", "1.17", "1.22", "rbac.authorization.k8s.io/v1"),
+	deprecatedAPI("rbac.authorization.k8s.io", "v1beta1", "ClusterRoleBinding", "1.17", "1.22", "rbac.authorization.k8s.io/v1"),
+	deprecatedAPI("rbac.authorization.k8s.io", "v1beta1", "Role", "1.17", "1.22", "rbac.authorization.k8s.io/v1"),
+	deprecatedAPI("rbac.authorization.k8s.io", "v1beta1", "RoleBinding", "1.17", "1.22", "rbac.authorization.k8s.io/v1"),
+	deprecatedAPI("scheduling.k8s.io", "v1beta1", "PriorityClass", "1.14", "1.22", "scheduling.k8s.io/v1"),
+	deprecatedAPI("storage.k8s.io", "v1beta1", "CSIDriver", "1.19", "1.22", "storage.k8s.io/v1"),
+	deprecatedAPI("storage.k8s.io", "v1beta1", "CSINode", "1.17", "1.22", "storage.k8s.io/v1"),
+	deprecatedAPI("storage.k8s.io", "v1beta1", "StorageClass", "1.19", "1.22", "storage.k8s.io/v1"),
+	deprecatedAPI("storage.k8s.io", "v1beta1", "VolumeAttachment", "1.19", "1.22", "storage.k8s.io/v1"),
+
+	// Removed in 1.25
+	deprecatedAPI("batch", "v1beta1", "CronJob", "1.21", "1.25", "batch/v1"),
+	deprecatedAPI("discovery.k8s.io", "v1beta1", "EndpointSlice", "1.21", "1.25", "discovery.k8s.io/v1"),
+	deprecatedAPI("events.k8s.io", "v1beta1", "Event", "1.22", "1.25", "events.k8s.io/v1"),
+	deprecatedAPI("autoscaling", "v2beta1", "HorizontalPodAutoscaler", "1.22", "1.25", "autoscaling/v2"),
+	deprecatedAPI("policy", "v1beta1", "PodDisruptionBudget", "1.21", "1.25", "policy/v1"),
+	deprecatedAPI("policy", "v1beta1", "PodSecurityPolicy", "1.21", "1.25", ""),
+	deprecatedAPI("node.k8s.io", "v1beta1", "RuntimeClass", "1.22", "1.25", "node.k8s.io/v1"),
+
+	// Removed in 1.26
+	deprecatedAPI("flowcontrol.apiserver.k8s.io", "v1beta1", "FlowSchema", "1.23", "1.26", "flowcontrol.apiserver.k8s.io/v1"),
+	deprecatedAPI("flowcontrol.apiserver.k8s.io", "v1beta1", "PriorityLevelConfiguration", "1.23", "1.26", "flowcontrol.apiserver.k8s.io/v1"),
+	deprecatedAPI("autoscaling", "v2beta2", "HorizontalPodAutoscaler", "1.23", "1.26", "autoscaling/v2"),
+
+	// Removed in 1.27
+	deprecatedAPI("storage.k8s.io", "v1beta1", "CSIStorageCapacity", "1.24", "1.27", "storage.k8s.io/v1"),
+
+	// Removed in 1.29
+	deprecatedAPI("flowcontrol.apiserver.k8s.io", "v1beta2", "FlowSchema", "1.26", "1.29", "flowcontrol.apiserver.k8s.io/v1"),
+	deprecatedAPI("flowcontrol.apiserver.k8s.io", "v1beta2", "PriorityLevelConfiguration", "1.26", "1.29", "flowcontrol.apiserver.k8s.io/v1"),
+
+	// Removed in 1.32
+	deprecatedAPI("flowcontrol.apiserver.k8s.io", "v1beta3", "FlowSchema", "1.29", "1.32", "flowcontrol.apiserver.k8s.io/v1"),
+	deprecatedAPI("flowcontrol.apiserver.k8s.io", "v1beta3", "PriorityLevelConfiguration", "1.29", "1.32", "flowcontrol.apiserver.k8s.io/v1"),
+
+	// Deprecated, but not scheduled for removal
+	deprecatedAPI("", "v1", "ComponentStatus", "1.19", "", ""),
+	deprecatedAPI("", "v1", "Endpoints", "1.33", "", "discovery.k8s.io/v1 EndpointSlice"),
+}
+
+// deprecatedAPI creates a new API entry. The replacement is either an
+// apiVersion, in which case the kind stays the same, or an apiVersion followed
+// by a kind.
+func deprecatedAPI(group, version, kind, deprecatedIn, removedIn, replacement string) API {
+	api := API{
+		GroupVersionKind: schema.GroupVersionKind{Group: group, Version: version, Kind: kind},
+		DeprecatedIn:     kubeversion.MustParse(deprecatedIn),
+	}
+
+	if removedIn != "" {
+		removed := kubeversion.MustParse(removedIn)
+		api.RemovedIn = &removed
+	}
+
+	if replacement != "" {
+		parts := strings.SplitN(replacement, " ", 2)
+		replacementGVK := schema.FromAPIVersionAndKind(parts[0], kind)
+		if len(parts) == 2 {
+			replacementGVK.Kind = parts[1]
+		}
+		api.Replacement = &replacementGVK
+	}
+
+	return api
+}
@@ -0,0 +1,97 @@
+package deprecation
+
+import (
+	"fmt"
+
+	"github.com/frigus02/kyml/pkg/k8syaml"
+	"github.com/frigus02/kyml/pkg/kubeversion"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// API describes a deprecated Kubernetes API version of a kind.
+type API struct {
+	GroupVersionKind schema.GroupVersionKind
+	DeprecatedIn     kubeversion.Version
+	// RemovedIn is nil if the API is not scheduled for removal.
+	RemovedIn *kubeversion.Version
+	// Replacement is nil if there is no replacement API.
+	Replacement *schema.GroupVersionKind
+}
+
+// Lookup returns information about the specified API, if it is deprecated in
+// any Kubernetes version.
+func Lookup(gvk schema.GroupVersionKind) (API, bool) {
+	for _, api := range apis {
+		if k8syaml.GVKEquals(gvk, api.GroupVersionKind) {
+			return api, true
+		}
+	}
+
+	return API{}, false
+}
+
+// IsRemoved returns true if the API is no longer served in the specified
+// Kubernetes version.
+func (a API) IsRemoved(version kubeversion.Version) bool {
+	return a.RemovedIn != nil && version.AtLeast(*a.RemovedIn)
+}
+
+// IsDeprecated returns true if the API is deprecated, but still served in the
+// specified Kubernetes version.
+func (a API) IsDeprecated(version kubeversion.Version) bool {
+	return version.AtLeast(a.DeprecatedIn) && !a.IsRemoved(version)
+}
+
+// Finding describes a document, which uses a deprecated or removed API.
+type Finding struct {
+	Document *unstructured.Unstructured
+	API      API
+	Removed  bool
+}
+
+func (f Finding) String() string {
+	gvk := f.API.GroupVersionKind
+	api := gvk.GroupVersion().String() + " " + gvk.Kind
+
+	var msg string
+	if f.Removed {
+		msg = fmt.Sprintf("%s is removed in Kubernetes %s", api, f.API.RemovedIn)
+	} else {
+		msg = fmt.Sprintf("%s is deprecated since Kubernetes %s", api, f.API.DeprecatedIn)
+		if f.API.RemovedIn != nil {
+			msg += fmt.Sprintf(" and will be removed in %s", f.API.RemovedIn)
+		}
+	}
+
+	if f.API.Replacement == nil {
+		msg += " without replacement"
+	} else if f.API.Replacement.Kind != gvk.Kind {
+		msg += fmt.Sprintf(", use %s %s instead", f.API.Replacement.GroupVersion(), f.API.Replacement.Kind)
+	} else {
+		msg += fmt.Sprintf(", use %s instead", f.API.Replacement.GroupVersion())
+	}
+
+	return k8syaml.ResourceName(f.Document) + ": " + msg
+}
+
+// Check returns a finding for every document, which uses an API that is
+// deprecated or removed in the specified Kubernetes version.
+func Check(documents []*unstructured.Unstructured, version kubeversion.Version) []Finding {
+	var findings []Finding
+	for _, doc := range documents {
+		api, ok := Lookup(doc.GroupVersionKind())
+		if !ok {
+			continue
+		}
+
+		if api.IsRemoved(version) {
+			findings = append(findings, Finding{Document: doc, API: api, Removed: true})
+		} else if api.IsDeprecated(version) {
+			findings = append(findings, Finding{Document: doc, API: api, Removed: false})
+		}
+	}
+
+	return findings
+}
@@ -0,0 +1,79 @@
+package deprecation
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/frigus02/kyml/pkg/kubeversion"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newDoc(apiVersion, kind, name string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": apiVersion,
+			"kind":       kind,
+			"metadata": map[string]interface{}{
+				"name": name,
+			},
+		},
+	}
+}
+
+func findingStrings(findings []Finding) []string {
+	var result []string
+	for _, f := range findings {
+		result = append(result, f.String())
+	}
+
+	return result
+}
+
+func TestCheck(t *testing.T) {
+	documents := []*unstructured.Unstructured{
+		newDoc("batch/v1beta1", "CronJob", "backup"),
+		newDoc("policy/v1beta1", "PodSecurityPolicy", "restricted"),
+		newDoc("flowcontrol.apiserver.k8s.io/v1beta3", "FlowSchema", "the-flow-schema"),
+		newDoc("v1", "Endpoints", "the-endpoints"),
+		newDoc("apps/v1", "Deployment", "the-deployment"),
+	}
+
+	tests := []struct {
+		name    string
+		version string
+		want    []string
+	}{
+		{
+			name:    "before deprecation",
+			version: "1.20",
+			want:    nil,
+		},
+		{
+			name:    "deprecated",
+			version: "1.24",
+			want: []string{
+				"CronJob/backup: batch/v1beta1 CronJob is deprecated since Kubernetes 1.21 and will be removed in 1.25, use batch/v1 instead",
+				"PodSecurityPolicy/restricted: policy/v1beta1 PodSecurityPolicy is deprecated since Kubernetes 1.21 and will be removed in 1.25 without replacement",
+			},
+		},
+		{
+			name:    "removed",
+			version: "1.33",
+			want: []string{
+				"CronJob/backup: batch/v1beta1 CronJob is removed in Kubernetes 1.25, use batch/v1 instead",
+				"PodSecurityPolicy/restricted: policy/v1beta1 PodSecurityPolicy is removed in Kubernetes 1.25 without replacement",
+				"FlowSchema/the-flow-schema: flowcontrol.apiserver.k8s.io/v1beta3 FlowSchema is removed in Kubernetes 1.32, use flowcontrol.apiserver.k8s.io/v1 instead",
+				"Endpoints/the-endpoints: v1 Endpoints is deprecated since Kubernetes 1.33, use discovery.k8s.io/v1 EndpointSlice instead",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Check(documents, kubeversion.MustParse(tt.version))
+			if !reflect.DeepEqual(findingStrings(got), tt.want) {
+				t.Errorf("Check() = %q, want %q", findingStrings(got), tt.want)
+			}
+		})
+	}
+}
@@ -2,8 +2,10 @@ package diff
 
 import "github.com/pmezard/go-difflib/difflib"
 
-// Diff returns a diff between the two specified strings A and B.
-func Diff(nameA string, a string, nameB string, b string) (string, error) {
+// Diff returns a diff between the two specified strings A and B with the
+// specified number of context lines. Snapshots store this format, so it must
+// not change. Use Render for diffs meant to be read by humans.
+func Diff(nameA string, a string, nameB string, b string, context int) (string, error) {
 	linesA := difflib.SplitLines(a)
 	linesB := difflib.SplitLines(b)
 
@@ -12,6 +14,6 @@ func Diff(nameA string, a string, nameB string, b string) (string, error) {
 		FromFile: nameA,
 		B:        linesB,
 		ToFile:   nameB,
-		Context:  0,
+		Context:  context,
 	})
 }
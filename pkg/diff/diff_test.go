@@ -4,10 +4,11 @@ import "testing"
 
 func TestDiff(t *testing.T) {
 	type args struct {
-		nameA string
-		a     string
-		nameB string
-		b     string
+		nameA   string
+		a       string
+		nameB   string
+		b       string
+		context int
 	}
 	tests := []struct {
 		name    string
@@ -26,6 +27,40 @@ func TestDiff(t *testing.T) {
 			want:    "--- staging\n+++ production\n@@ -2 +2 @@\n-2\n+a\n",
 			wantErr: false,
 		},
+		{
+			name: "context",
+			args: args{
+				nameA:   "staging",
+				a:       "1\n2\n3\n4",
+				nameB:   "production",
+				b:       "1\na\n3\n4",
+				context: 1,
+			},
+			want:    "--- staging\n+++ production\n@@ -1,3 +1,3 @@\n 1\n-2\n+a\n 3\n",
+			wantErr: false,
+		},
+		{
+			name: "trailing newline in both",
+			args: args{
+				nameA: "staging",
+				a:     "1\n2\n3\n",
+				nameB: "production",
+				b:     "1\na\n3\n",
+			},
+			want:    "--- staging\n+++ production\n@@ -2 +2 @@\n-2\n+a\n",
+			wantErr: false,
+		},
+		{
+			name: "trailing newline only in B",
+			args: args{
+				nameA: "staging",
+				a:     "1\n2\n3",
+				nameB: "production",
+				b:     "1\n2\n3\n",
+			},
+			want:    "--- staging\n+++ production\n@@ -3,0 +4 @@\n+\n",
+			wantErr: false,
+		},
 		{
 			name: "no difference",
 			args: args{
@@ -40,7 +75,7 @@ func TestDiff(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := Diff(tt.args.nameA, tt.args.a, tt.args.nameB, tt.args.b)
+			got, err := Diff(tt.args.nameA, tt.args.a, tt.args.nameB, tt.args.b, tt.args.context)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Diff() error = %v, wantErr %v", err, tt.wantErr)
 				return
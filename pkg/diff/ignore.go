@@ -0,0 +1,120 @@
+package diff
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/frigus02/kyml/pkg/fieldpath"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// IgnoreRule describes fields, which should be removed from matching documents
+// before diffing, because they are expected to differ between environments.
+type IgnoreRule struct {
+	Match IgnoreMatch
+	Paths []fieldpath.Path
+}
+
+// IgnoreMatch selects documents. Every field is a pattern as understood by
+// path.Match, e.g. "web-*". Empty fields match all documents.
+type IgnoreMatch struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace"`
+	Name       string `json:"name"`
+}
+
+type ignoreFile struct {
+	Ignore []struct {
+		Match IgnoreMatch `json:"match"`
+		Paths []string    `json:"paths"`
+	} `json:"ignore"`
+}
+
+// ParseIgnoreRules parses a file containing ignore rules, e.g.
+//
+//	ignore:
+//	- match:
+//	    kind: Deployment
+//	    name: web-*
+//	  paths:
+//	  - spec.replicas
+//	  - spec.template.spec.containers[*].resources
+func ParseIgnoreRules(data []byte) ([]IgnoreRule, error) {
+	var file ignoreFile
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, err
+	}
+
+	var rules []IgnoreRule
+	for i, entry := range file.Ignore {
+		for _, pattern := range []string{entry.Match.APIVersion, entry.Match.Kind, entry.Match.Namespace, entry.Match.Name} {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("rule %d: invalid pattern %s", i, pattern)
+			}
+		}
+
+		if len(entry.Paths) == 0 {
+			return nil, fmt.Errorf("rule %d: specify at least one path", i)
+		}
+
+		rule := IgnoreRule{Match: entry.Match}
+		for _, p := range entry.Paths {
+			parsed, err := fieldpath.Parse(p)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: %v", i, err)
+			}
+
+			rule.Paths = append(rule.Paths, parsed)
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// Matches returns true if the document is selected by the match.
+func (m IgnoreMatch) Matches(doc *unstructured.Unstructured) bool {
+	return matchPattern(m.APIVersion, doc.GetAPIVersion()) &&
+		matchPattern(m.Kind, doc.GetKind()) &&
+		matchPattern(m.Namespace, doc.GetNamespace()) &&
+		matchPattern(m.Name, doc.GetName())
+}
+
+func matchPattern(pattern, value string) bool {
+	if pattern == "" {
+		return true
+	}
+
+	matched, _ := path.Match(pattern, value)
+	return matched
+}
+
+// Ignore returns copies of the documents with all fields removed, which are
+// matched by one of the rules. The original documents are not modified.
+func Ignore(docs []*unstructured.Unstructured, rules []IgnoreRule) []*unstructured.Unstructured {
+	if len(rules) == 0 {
+		return docs
+	}
+
+	result := make([]*unstructured.Unstructured, 0, len(docs))
+	for _, doc := range docs {
+		doc = doc.DeepCopy()
+		for _, rule := range rules {
+			if !rule.Match.Matches(doc) {
+				continue
+			}
+
+			for _, p := range rule.Paths {
+				fieldpath.Remove(doc.Object, p)
+			}
+		}
+
+		result = append(result, doc)
+	}
+
+	return result
+}
@@ -0,0 +1,74 @@
+package diff
+
+import (
+	"testing"
+)
+
+var testIgnoreRules = `ignore:
+- paths:
+  - metadata.labels.env
+- match:
+    kind: Deployment
+    name: ap*
+  paths:
+  - spec.replicas
+  - spec.template.spec.containers[*].image
+`
+
+func TestIgnore(t *testing.T) {
+	rules, err := ParseIgnoreRules([]byte(testIgnoreRules))
+	if err != nil {
+		t.Fatalf("ParseIgnoreRules() error = %v", err)
+	}
+
+	a := mustDecode(t, testStructuralA)
+	b := mustDecode(t, testStructuralB)
+
+	want := `--- staging
++++ production
+ConfigMap/only-in-a: removed
+Deployment.apps/app metadata.labels.tier: added "backend"
+Deployment.apps/app spec.template.spec.containers[name=app].args[1]: added "--debug"
+Deployment.apps/app spec.template.spec.containers[name=sidecar]: removed {"name":"sidecar"}
+Deployment.apps/app spec.template.spec.containers[name=proxy]: added {"name":"proxy"}
+Secret/only-in-b: added
+`
+	if got := Structural("staging", Ignore(a, rules), "production", Ignore(b, rules)); got != want {
+		t.Errorf("Structural() = %v, want %v", got, want)
+	}
+
+	if replicas := a[1].Object["spec"].(map[string]interface{})["replicas"]; replicas != int64(3) {
+		t.Errorf("Ignore() modified original document")
+	}
+}
+
+func TestParseIgnoreRules_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules string
+	}{
+		{
+			name:  "unknown field",
+			rules: "ignore:\n- path: [spec.replicas]\n",
+		},
+		{
+			name:  "no paths",
+			rules: "ignore:\n- match:\n    kind: Deployment\n",
+		},
+		{
+			name:  "invalid path",
+			rules: "ignore:\n- paths: [spec..replicas]\n",
+		},
+		{
+			name:  "invalid pattern",
+			rules: "ignore:\n- match:\n    name: '[a'\n  paths: [spec.replicas]\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseIgnoreRules([]byte(tt.rules)); err == nil {
+				t.Errorf("ParseIgnoreRules() expected error")
+			}
+		})
+	}
+}
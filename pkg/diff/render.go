@@ -0,0 +1,252 @@
+package diff
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// ANSI escape sequences used to color diffs.
+const (
+	colorReset  = "\x1b[0m"
+	colorBold   = "\x1b[1m"
+	colorRed    = "\x1b[31m"
+	colorGreen  = "\x1b[32m"
+	colorYellow = "\x1b[33m"
+	colorCyan   = "\x1b[36m"
+)
+
+// RenderOptions configures how Render formats a diff.
+type RenderOptions struct {
+	// Context is the number of unchanged lines shown around every change.
+	Context int
+	// Color enables ANSI colors.
+	Color bool
+	// Header returns a description of the hunk, which starts at the specified
+	// line of B (zero-based), e.g. the name of a Kubernetes resource. It is
+	// printed after the line numbers. Optional.
+	Header func(lineB int) string
+	// Width is the maximum width of lines in SideBySide. Defaults to 160.
+	Width int
+}
+
+// IsTerminal returns true if the file is a terminal, so diffs printed to it
+// should use colors by default. The NO_COLOR environment variable disables
+// colors even for terminals, see https://no-color.org.
+func IsTerminal(file *os.File) bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// Render returns a unified diff between the two specified strings A and B,
+// which is meant to be read by humans. Unlike Diff, it supports colors and
+// hunk headers.
+func Render(nameA string, a string, nameB string, b string, opts RenderOptions) string {
+	linesA := splitLines(a)
+	linesB := splitLines(b)
+
+	groups := difflib.NewMatcher(linesA, linesB).GetGroupedOpCodes(opts.Context)
+	if len(groups) == 0 {
+		return ""
+	}
+
+	w := renderer{opts: opts}
+	w.writeLine(colorBold, "--- "+nameA)
+	w.writeLine(colorBold, "+++ "+nameB)
+	for _, group := range groups {
+		w.writeGroup(linesA, linesB, group)
+	}
+
+	return w.sb.String()
+}
+
+// Hunk is a single change between A and B, which replaces the lines
+// A[I1:I2] with the lines B[J1:J2].
+type Hunk struct {
+	I1, I2, J1, J2 int
+}
+
+// Hunks returns all changes between the two specified strings A and B.
+func Hunks(a, b string) []Hunk {
+	var hunks []Hunk
+	for _, c := range difflib.NewMatcher(splitLines(a), splitLines(b)).GetOpCodes() {
+		if c.Tag != 'e' {
+			hunks = append(hunks, Hunk{I1: c.I1, I2: c.I2, J1: c.J1, J2: c.J2})
+		}
+	}
+
+	return hunks
+}
+
+// RenderHunk returns the specified hunk between A and B formatted like a hunk
+// in Render.
+func RenderHunk(a, b string, h Hunk, opts RenderOptions) string {
+	linesA := splitLines(a)
+	linesB := splitLines(b)
+
+	before := opts.Context
+	if h.I1 < before {
+		before = h.I1
+	}
+	if h.J1 < before {
+		before = h.J1
+	}
+
+	after := opts.Context
+	if len(linesA)-h.I2 < after {
+		after = len(linesA) - h.I2
+	}
+	if len(linesB)-h.J2 < after {
+		after = len(linesB) - h.J2
+	}
+
+	tag := byte('r')
+	if h.I1 == h.I2 {
+		tag = 'i'
+	} else if h.J1 == h.J2 {
+		tag = 'd'
+	}
+
+	group := []difflib.OpCode{
+		{Tag: 'e', I1: h.I1 - before, I2: h.I1, J1: h.J1 - before, J2: h.J1},
+		{Tag: tag, I1: h.I1, I2: h.I2, J1: h.J1, J2: h.J2},
+		{Tag: 'e', I1: h.I2, I2: h.I2 + after, J1: h.J2, J2: h.J2 + after},
+	}
+
+	w := renderer{opts: opts}
+	w.writeGroup(linesA, linesB, group)
+	return w.sb.String()
+}
+
+// Apply returns A with the accepted hunks replaced by the corresponding lines
+// of B. Hunks have to be the ones returned by Hunks for A and B.
+func Apply(a, b string, hunks []Hunk, accepted []bool) string {
+	linesA := splitLines(a)
+	linesB := splitLines(b)
+
+	var sb strings.Builder
+	i := 0
+	for k, h := range hunks {
+		sb.WriteString(strings.Join(linesA[i:h.I1], ""))
+		if accepted[k] {
+			sb.WriteString(strings.Join(linesB[h.J1:h.J2], ""))
+		} else {
+			sb.WriteString(strings.Join(linesA[h.I1:h.I2], ""))
+		}
+		i = h.I2
+	}
+	sb.WriteString(strings.Join(linesA[i:], ""))
+
+	return sb.String()
+}
+
+type renderer struct {
+	opts RenderOptions
+	sb   strings.Builder
+}
+
+func (w *renderer) writeLine(color, line string) {
+	line = strings.TrimSuffix(line, "\n")
+	if w.opts.Color && color != "" {
+		w.sb.WriteString(color + line + colorReset + "\n")
+	} else {
+		w.sb.WriteString(line + "\n")
+	}
+}
+
+func (w *renderer) writeGroup(linesA, linesB []string, group []difflib.OpCode) {
+	w.writeLine(colorCyan, w.hunkHeader(linesB, group))
+
+	for _, c := range group {
+		if c.Tag == 'e' {
+			for _, line := range linesA[c.I1:c.I2] {
+				w.writeLine("", " "+line)
+			}
+			continue
+		}
+
+		if c.Tag == 'r' || c.Tag == 'd' {
+			for _, line := range linesA[c.I1:c.I2] {
+				w.writeLine(colorRed, "-"+line)
+			}
+		}
+
+		if c.Tag == 'r' || c.Tag == 'i' {
+			for _, line := range linesB[c.J1:c.J2] {
+				w.writeLine(colorGreen, "+"+line)
+			}
+		}
+	}
+}
+
+func (w *renderer) hunkHeader(linesB []string, group []difflib.OpCode) string {
+	first, last := group[0], group[len(group)-1]
+	header := fmt.Sprintf("@@ -%s +%s @@", formatRange(first.I1, last.I2), formatRange(first.J1, last.J2))
+	if w.opts.Header != nil {
+		if description := w.opts.Header(firstChangeInB(group, len(linesB))); description != "" {
+			header += " " + description
+		}
+	}
+
+	return header
+}
+
+// splitLines splits the string into lines, each ending with a newline. Unlike
+// difflib.SplitLines it doesn't add an empty line at the end, which would show
+// up as context.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	lines := strings.SplitAfter(s, "\n")
+	if last := len(lines) - 1; lines[last] == "" {
+		lines = lines[:last]
+	} else {
+		lines[last] += "\n"
+	}
+
+	return lines
+}
+
+// firstChangeInB returns the line in B, where the first change of the group
+// happens. For deletions this is the line following the deleted lines.
+func firstChangeInB(group []difflib.OpCode, lenB int) int {
+	line := group[0].J1
+	for _, c := range group {
+		if c.Tag != 'e' {
+			line = c.J1
+			break
+		}
+	}
+
+	if line >= lenB && lenB > 0 {
+		line = lenB - 1
+	}
+
+	return line
+}
+
+// formatRange formats a range of lines in the same way as difflib.
+func formatRange(start, stop int) string {
+	beginning := start + 1
+	length := stop - start
+	if length == 1 {
+		return fmt.Sprintf("%d", beginning)
+	}
+	if length == 0 {
+		beginning--
+	}
+
+	return fmt.Sprintf("%d,%d", beginning, length)
+}
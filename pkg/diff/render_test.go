@@ -0,0 +1,102 @@
+package diff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n"
+	b := "1\n2\n3\na\n5\n6\n7\n8\n"
+
+	tests := []struct {
+		name string
+		opts RenderOptions
+		want string
+	}{
+		{
+			name: "no context",
+			opts: RenderOptions{},
+			want: "--- a\n+++ b\n@@ -4 +4 @@\n-4\n+a\n@@ -9 +8,0 @@\n-9\n",
+		},
+		{
+			name: "context",
+			opts: RenderOptions{Context: 2},
+			want: "--- a\n+++ b\n@@ -2,8 +2,7 @@\n 2\n 3\n-4\n+a\n 5\n 6\n 7\n 8\n-9\n",
+		},
+		{
+			name: "color",
+			opts: RenderOptions{Context: 0, Color: true},
+			want: "\x1b[1m--- a\x1b[0m\n\x1b[1m+++ b\x1b[0m\n\x1b[36m@@ -4 +4 @@\x1b[0m\n\x1b[31m-4\x1b[0m\n\x1b[32m+a\x1b[0m\n\x1b[36m@@ -9 +8,0 @@\x1b[0m\n\x1b[31m-9\x1b[0m\n",
+		},
+		{
+			name: "header",
+			opts: RenderOptions{Context: 1, Header: func(line int) string { return "line " + strings.TrimSpace(strings.Split(b, "\n")[line]) }},
+			want: "--- a\n+++ b\n@@ -3,3 +3,3 @@ line a\n 3\n-4\n+a\n 5\n@@ -8,2 +8 @@ line 8\n 8\n-9\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Render("a", a, "b", b, tt.opts); got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRender_sameAsDiff(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n"
+	b := "0\n1\n2\n3\na\n5\n6\n7\n8\n"
+
+	want, err := Diff("a", a, "b", b, 0)
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+
+	if got := Render("a", a, "b", b, RenderOptions{}); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+}
+
+func TestRender_noDifference(t *testing.T) {
+	if got := Render("a", "1\n2\n", "b", "1\n2\n", RenderOptions{Context: 3}); got != "" {
+		t.Errorf("Render() = %q, want empty", got)
+	}
+}
+
+func TestHunks(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n"
+	b := "0\n1\n2\n3\na\n5\n6\n7\n8\n"
+
+	hunks := Hunks(a, b)
+	want := []Hunk{{0, 0, 0, 1}, {3, 4, 4, 5}, {8, 9, 9, 9}}
+	if !reflect.DeepEqual(hunks, want) {
+		t.Fatalf("Hunks() = %v, want %v", hunks, want)
+	}
+
+	if got, want := RenderHunk(a, b, hunks[1], RenderOptions{Context: 1}), "@@ -3,3 +4,3 @@\n 3\n-4\n+a\n 5\n"; got != want {
+		t.Errorf("RenderHunk() = %q, want %q", got, want)
+	}
+
+	if got, want := RenderHunk(a, b, hunks[2], RenderOptions{Context: 3}), "@@ -6,4 +7,3 @@\n 6\n 7\n 8\n-9\n"; got != want {
+		t.Errorf("RenderHunk() = %q, want %q", got, want)
+	}
+
+	tests := []struct {
+		name     string
+		accepted []bool
+		want     string
+	}{
+		{name: "none", accepted: []bool{false, false, false}, want: a},
+		{name: "all", accepted: []bool{true, true, true}, want: b},
+		{name: "some", accepted: []bool{true, false, true}, want: "0\n1\n2\n3\n4\n5\n6\n7\n8\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Apply(a, b, hunks, tt.accepted); got != tt.want {
+				t.Errorf("Apply() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package diff
+
+import (
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+const defaultSideBySideWidth = 160
+
+// SideBySide returns a diff between the two specified strings A and B, which
+// shows lines of A and B next to each other, similar to "diff --side-by-side".
+// Lines are marked with "|" if they changed, "<" if they only exist in A and
+// ">" if they only exist in B. Lines, which don't fit into their column, are
+// truncated.
+func SideBySide(nameA string, a string, nameB string, b string, opts RenderOptions) string {
+	linesA := splitLines(a)
+	linesB := splitLines(b)
+
+	groups := difflib.NewMatcher(linesA, linesB).GetGroupedOpCodes(opts.Context)
+	if len(groups) == 0 {
+		return ""
+	}
+
+	width := opts.Width
+	if width <= 0 {
+		width = defaultSideBySideWidth
+	}
+
+	column := (width - 3) / 2
+	if column < 1 {
+		column = 1
+	}
+
+	w := sideBySideRenderer{renderer: renderer{opts: opts}, column: column}
+	w.writeRow(colorBold, "--- "+nameA, ' ', colorBold, "+++ "+nameB)
+	for _, group := range groups {
+		w.writeLine(colorCyan, w.hunkHeader(linesB, group))
+		for _, c := range group {
+			w.writeOpCode(linesA, linesB, c)
+		}
+	}
+
+	return w.sb.String()
+}
+
+type sideBySideRenderer struct {
+	renderer
+	column int
+}
+
+func (w *sideBySideRenderer) writeOpCode(linesA, linesB []string, c difflib.OpCode) {
+	if c.Tag == 'e' {
+		for i := c.I1; i < c.I2; i++ {
+			w.writeRow("", linesA[i], ' ', "", linesB[c.J1+i-c.I1])
+		}
+		return
+	}
+
+	lenA, lenB := c.I2-c.I1, c.J2-c.J1
+	for k := 0; k < lenA || k < lenB; k++ {
+		switch {
+		case k >= lenA:
+			w.writeRow("", "", '>', colorGreen, linesB[c.J1+k])
+		case k >= lenB:
+			w.writeRow(colorRed, linesA[c.I1+k], '<', "", "")
+		default:
+			w.writeRow(colorRed, linesA[c.I1+k], '|', colorGreen, linesB[c.J1+k])
+		}
+	}
+}
+
+func (w *sideBySideRenderer) writeRow(colorA, a string, marker rune, colorB, b string) {
+	a = truncate(strings.TrimSuffix(a, "\n"), w.column)
+	b = truncate(strings.TrimSuffix(b, "\n"), w.column)
+	padding := strings.Repeat(" ", w.column-len([]rune(a)))
+
+	row := w.colorize(colorA, a) + padding + " " + string(marker) + " " + w.colorize(colorB, b)
+	w.sb.WriteString(strings.TrimRight(row, " ") + "\n")
+}
+
+func (w *sideBySideRenderer) colorize(color, s string) string {
+	if w.opts.Color && color != "" && s != "" {
+		return color + s + colorReset
+	}
+
+	return s
+}
+
+func truncate(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+
+	if width < 1 {
+		return ""
+	}
+
+	return string(runes[:width-1]) + "…"
+}
@@ -0,0 +1,88 @@
+package diff
+
+import "testing"
+
+func TestSideBySide(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n"
+	b := "1\n2\nthree\n5\n6\n7\n"
+
+	tests := []struct {
+		name string
+		opts RenderOptions
+		want string
+	}{
+		{
+			name: "no context",
+			opts: RenderOptions{Width: 13},
+			want: "--- a   +++ b\n" +
+				"@@ -3,2 +3 @@\n" +
+				"3     | three\n" +
+				"4     <\n" +
+				"@@ -6,0 +6 @@\n" +
+				"      > 7\n",
+		},
+		{
+			name: "context",
+			opts: RenderOptions{Context: 1, Width: 13},
+			want: "--- a   +++ b\n" +
+				"@@ -2,5 +2,5 @@\n" +
+				"2       2\n" +
+				"3     | three\n" +
+				"4     <\n" +
+				"5       5\n" +
+				"6       6\n" +
+				"      > 7\n",
+		},
+		{
+			name: "truncated",
+			opts: RenderOptions{Width: 9},
+			want: "--…   ++…\n" +
+				"@@ -3,2 +3 @@\n" +
+				"3   | th…\n" +
+				"4   <\n" +
+				"@@ -6,0 +6 @@\n" +
+				"    > 7\n",
+		},
+		{
+			name: "width 1",
+			opts: RenderOptions{Width: 1},
+			want: "…   …\n" +
+				"@@ -3,2 +3 @@\n" +
+				"3 | …\n" +
+				"4 <\n" +
+				"@@ -6,0 +6 @@\n" +
+				"  > 7\n",
+		},
+		{
+			name: "width 2",
+			opts: RenderOptions{Width: 2},
+			want: "…   …\n" +
+				"@@ -3,2 +3 @@\n" +
+				"3 | …\n" +
+				"4 <\n" +
+				"@@ -6,0 +6 @@\n" +
+				"  > 7\n",
+		},
+		{
+			name: "color",
+			opts: RenderOptions{Width: 13, Color: true},
+			want: "\x1b[1m--- a\x1b[0m   \x1b[1m+++ b\x1b[0m\n" +
+				"\x1b[36m@@ -3,2 +3 @@\x1b[0m\n" +
+				"\x1b[31m3\x1b[0m     | \x1b[32mthree\x1b[0m\n" +
+				"\x1b[31m4\x1b[0m     <\n" +
+				"\x1b[36m@@ -6,0 +6 @@\x1b[0m\n" +
+				"      > \x1b[32m7\x1b[0m\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SideBySide("a", a, "b", b, tt.opts); got != tt.want {
+				t.Errorf("SideBySide() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if got := SideBySide("a", a, "b", a, RenderOptions{}); got != "" {
+		t.Errorf("SideBySide() of equal strings = %q, want empty string", got)
+	}
+}
@@ -0,0 +1,330 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/frigus02/kyml/pkg/fieldpath"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ChangeType describes how a value differs between A and B.
+type ChangeType int
+
+// Available change types.
+const (
+	Modified ChangeType = iota
+	Added
+	Removed
+)
+
+// Change is a single difference between two sets of documents. If Path is
+// empty, the whole resource was added or removed.
+type Change struct {
+	Resource string
+	Path     fieldpath.Path
+	Type     ChangeType
+	A        interface{}
+	B        interface{}
+}
+
+func (c Change) String() string {
+	if len(c.Path) == 0 {
+		switch c.Type {
+		case Added:
+			return fmt.Sprintf("%s: added", c.Resource)
+		case Removed:
+			return fmt.Sprintf("%s: removed", c.Resource)
+		}
+	}
+
+	switch c.Type {
+	case Added:
+		return fmt.Sprintf("%s %s: added %s", c.Resource, c.Path, formatValue(c.B))
+	case Removed:
+		return fmt.Sprintf("%s %s: removed %s", c.Resource, c.Path, formatValue(c.A))
+	default:
+		return fmt.Sprintf("%s %s: %s -> %s", c.Resource, c.Path, formatValue(c.A), formatValue(c.B))
+	}
+}
+
+func formatValue(v interface{}) string {
+	var sb strings.Builder
+	encoder := json.NewEncoder(&sb)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// Structural returns a diff between the two specified sets of documents A and
+// B, which lists every change on a separate line. Resources are matched by
+// group, kind, namespace and name and named using StructuralResource. Changes
+// are reported as field paths, which makes the diff independent of the order
+// of fields and of named list items like containers.
+func Structural(nameA string, a []*unstructured.Unstructured, nameB string, b []*unstructured.Unstructured) string {
+	changes := StructuralChanges(a, b)
+	if len(changes) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", nameA, nameB)
+	for _, change := range changes {
+		sb.WriteString(change.String())
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// StructuralResource returns the name of the document used in structural
+// diffs, e.g. "Deployment.apps/default/app". It contains the group, so kinds
+// with the same name in different groups don't collide. Whitespace and "%" in
+// the name are percent-encoded, so the name never contains a space, which
+// separates it from the field path.
+func StructuralResource(doc *unstructured.Unstructured) string {
+	gv, _ := schema.ParseGroupVersion(doc.GetAPIVersion())
+	kind := doc.GetKind()
+	if gv.Group != "" {
+		kind += "." + gv.Group
+	}
+
+	name := escapeResourceName(doc.GetName())
+	if namespace := doc.GetNamespace(); namespace != "" {
+		return kind + "/" + namespace + "/" + name
+	}
+
+	return kind + "/" + name
+}
+
+func escapeResourceName(name string) string {
+	var sb strings.Builder
+	for i := 0; i < len(name); i++ {
+		if c := name[i]; c <= ' ' || c == '%' || c == 0x7f {
+			fmt.Fprintf(&sb, "%%%02X", c)
+		} else {
+			sb.WriteByte(c)
+		}
+	}
+
+	return sb.String()
+}
+
+// StructuralLineResource returns the resource a line of a structural diff
+// belongs to or an empty string for header lines. Resources never contain
+// spaces, so the resource ends at the first space. Lines of added or removed
+// resources consist of the resource followed by ": added" or ": removed".
+func StructuralLineResource(line string) string {
+	line = strings.TrimSuffix(line, "\n")
+	if line == "" || strings.HasPrefix(line, "--- ") || strings.HasPrefix(line, "+++ ") {
+		return ""
+	}
+
+	end := strings.Index(line, " ")
+	if end == -1 {
+		return ""
+	}
+
+	resource, rest := line[:end], line[end+1:]
+	if rest == "added" || rest == "removed" {
+		return strings.TrimSuffix(resource, ":")
+	}
+
+	return resource
+}
+
+// RenderStructural returns the same diff as Structural, which is meant to be
+// read by humans. With colors added values are green, removed values red and
+// modified values yellow.
+func RenderStructural(nameA string, a []*unstructured.Unstructured, nameB string, b []*unstructured.Unstructured, opts RenderOptions) string {
+	changes := StructuralChanges(a, b)
+	if len(changes) == 0 {
+		return ""
+	}
+
+	w := renderer{opts: opts}
+	w.writeLine(colorBold, "--- "+nameA)
+	w.writeLine(colorBold, "+++ "+nameB)
+	for _, change := range changes {
+		switch change.Type {
+		case Added:
+			w.writeLine(colorGreen, change.String())
+		case Removed:
+			w.writeLine(colorRed, change.String())
+		default:
+			w.writeLine(colorYellow, change.String())
+		}
+	}
+
+	return w.sb.String()
+}
+
+// StructuralChanges returns all changes between the two specified sets of
+// documents A and B. See Structural for details.
+func StructuralChanges(a, b []*unstructured.Unstructured) []Change {
+	var changes []Change
+	matched := make(map[int]bool)
+	for _, docA := range a {
+		resource := StructuralResource(docA)
+
+		i := findDocument(b, docA)
+		if i == -1 {
+			changes = append(changes, Change{Resource: resource, Type: Removed, A: docA.Object})
+			continue
+		}
+
+		matched[i] = true
+		changes = compareValues(changes, resource, nil, docA.Object, b[i].Object)
+	}
+
+	for i, docB := range b {
+		if !matched[i] {
+			changes = append(changes, Change{Resource: StructuralResource(docB), Type: Added, B: docB.Object})
+		}
+	}
+
+	return changes
+}
+
+func documentKey(doc *unstructured.Unstructured) string {
+	gv, _ := schema.ParseGroupVersion(doc.GetAPIVersion())
+	return strings.Join([]string{gv.Group, doc.GetKind(), doc.GetNamespace(), doc.GetName()}, "/")
+}
+
+func findDocument(docs []*unstructured.Unstructured, doc *unstructured.Unstructured) int {
+	key := documentKey(doc)
+	for i, other := range docs {
+		if documentKey(other) == key {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func compareValues(changes []Change, resource string, path fieldpath.Path, a, b interface{}) []Change {
+	switch a := a.(type) {
+	case map[string]interface{}:
+		if b, ok := b.(map[string]interface{}); ok {
+			return compareMaps(changes, resource, path, a, b)
+		}
+	case []interface{}:
+		if b, ok := b.([]interface{}); ok {
+			return compareLists(changes, resource, path, a, b)
+		}
+	}
+
+	if !reflect.DeepEqual(a, b) {
+		changes = append(changes, Change{Resource: resource, Path: path, Type: Modified, A: a, B: b})
+	}
+
+	return changes
+}
+
+func compareMaps(changes []Change, resource string, path fieldpath.Path, a, b map[string]interface{}) []Change {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		childPath := path.Child(fieldpath.Field(key))
+		valueA, inA := a[key]
+		valueB, inB := b[key]
+		switch {
+		case !inA:
+			changes = append(changes, Change{Resource: resource, Path: childPath, Type: Added, B: valueB})
+		case !inB:
+			changes = append(changes, Change{Resource: resource, Path: childPath, Type: Removed, A: valueA})
+		default:
+			changes = compareValues(changes, resource, childPath, valueA, valueB)
+		}
+	}
+
+	return changes
+}
+
+// listKey is the field, which identifies items in lists like containers,
+// volumes or ports. Lists are compared by this key if all items have a unique
+// value for it. Otherwise they are compared by index.
+const listKey = "name"
+
+func compareLists(changes []Change, resource string, path fieldpath.Path, a, b []interface{}) []Change {
+	keysA, okA := listItemKeys(a)
+	keysB, okB := listItemKeys(b)
+	if !okA || !okB {
+		for i := 0; i < len(a) || i < len(b); i++ {
+			childPath := path.Child(fieldpath.Index(i))
+			switch {
+			case i >= len(a):
+				changes = append(changes, Change{Resource: resource, Path: childPath, Type: Added, B: b[i]})
+			case i >= len(b):
+				changes = append(changes, Change{Resource: resource, Path: childPath, Type: Removed, A: a[i]})
+			default:
+				changes = compareValues(changes, resource, childPath, a[i], b[i])
+			}
+		}
+
+		return changes
+	}
+
+	indexB := make(map[string]int)
+	for i, key := range keysB {
+		indexB[key] = i
+	}
+
+	inA := make(map[string]bool)
+	for i, key := range keysA {
+		inA[key] = true
+		childPath := path.Child(fieldpath.Key(listKey, key))
+		if j, ok := indexB[key]; ok {
+			changes = compareValues(changes, resource, childPath, a[i], b[j])
+		} else {
+			changes = append(changes, Change{Resource: resource, Path: childPath, Type: Removed, A: a[i]})
+		}
+	}
+
+	for j, key := range keysB {
+		if !inA[key] {
+			childPath := path.Child(fieldpath.Key(listKey, key))
+			changes = append(changes, Change{Resource: resource, Path: childPath, Type: Added, B: b[j]})
+		}
+	}
+
+	return changes
+}
+
+func listItemKeys(list []interface{}) ([]string, bool) {
+	keys := make([]string, 0, len(list))
+	seen := make(map[string]bool)
+	for _, item := range list {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+
+		key, ok := obj[listKey].(string)
+		if !ok || seen[key] {
+			return nil, false
+		}
+
+		seen[key] = true
+		keys = append(keys, key)
+	}
+
+	return keys, true
+}
@@ -0,0 +1,196 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/frigus02/kyml/pkg/k8syaml"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var testStructuralA = `---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: only-in-a
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  labels:
+    env: staging
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - name: app
+        image: kyml/app:1
+        args: [--verbose]
+      - name: sidecar
+        image: kyml/sidecar
+`
+
+var testStructuralB = `---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    tier: backend
+  name: app
+spec:
+  template:
+    spec:
+      containers:
+      - name: proxy
+        image: kyml/proxy
+      - name: app
+        image: kyml/app:2
+        args: [--verbose, --debug]
+  replicas: 1
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: only-in-b
+`
+
+func mustDecode(t *testing.T, yaml string) []*unstructured.Unstructured {
+	docs, err := k8syaml.Decode(strings.NewReader(yaml))
+	if err != nil {
+		t.Fatalf("error decoding test manifests: %v", err)
+	}
+
+	return docs
+}
+
+func TestStructural(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want string
+	}{
+		{
+			name: "changes",
+			a:    testStructuralA,
+			b:    testStructuralB,
+			want: `--- staging
++++ production
+ConfigMap/only-in-a: removed
+Deployment.apps/app metadata.labels.env: removed "staging"
+Deployment.apps/app metadata.labels.tier: added "backend"
+Deployment.apps/app spec.replicas: 3 -> 1
+Deployment.apps/app spec.template.spec.containers[name=app].args[1]: added "--debug"
+Deployment.apps/app spec.template.spec.containers[name=app].image: "kyml/app:1" -> "kyml/app:2"
+Deployment.apps/app spec.template.spec.containers[name=sidecar]: removed {"image":"kyml/sidecar","name":"sidecar"}
+Deployment.apps/app spec.template.spec.containers[name=proxy]: added {"image":"kyml/proxy","name":"proxy"}
+Secret/only-in-b: added
+`,
+		},
+		{
+			name: "no difference",
+			a:    testStructuralA,
+			b:    testStructuralA,
+			want: "",
+		},
+		{
+			name: "api version change",
+			a:    "apiVersion: apps/v1beta1\nkind: Deployment\nmetadata:\n  name: app\n",
+			b:    "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: app\n",
+			want: "--- staging\n+++ production\nDeployment.apps/app apiVersion: \"apps/v1beta1\" -> \"apps/v1\"\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Structural("staging", mustDecode(t, tt.a), "production", mustDecode(t, tt.b))
+			if got != tt.want {
+				t.Errorf("Structural() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderStructural(t *testing.T) {
+	a := mustDecode(t, testStructuralA)
+	b := mustDecode(t, testStructuralB)
+
+	if got, want := RenderStructural("a", a, "b", b, RenderOptions{}), Structural("a", a, "b", b); got != want {
+		t.Errorf("RenderStructural() = %q, want %q", got, want)
+	}
+
+	got := RenderStructural("a", a, "b", b, RenderOptions{Color: true})
+	for _, want := range []string{
+		"\x1b[1m--- a\x1b[0m\n",
+		"\x1b[31mConfigMap/only-in-a: removed\x1b[0m\n",
+		"\x1b[33mDeployment.apps/app spec.replicas: 3 -> 1\x1b[0m\n",
+		"\x1b[32mSecret/only-in-b: added\x1b[0m\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("RenderStructural() = %q, want to contain %q", got, want)
+		}
+	}
+}
+
+func TestStructuralResource(t *testing.T) {
+	docs := mustDecode(t, `---
+apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata:
+  name: app
+  namespace: prod
+---
+apiVersion: networking.gke.io/v1
+kind: Certificate
+metadata:
+  name: app
+  namespace: prod
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: read only 100%
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+`)
+	want := []string{
+		"Certificate.cert-manager.io/prod/app",
+		"Certificate.networking.gke.io/prod/app",
+		"ClusterRole.rbac.authorization.k8s.io/read%20only%20100%25",
+		"Service/app",
+	}
+	for i, doc := range docs {
+		if got := StructuralResource(doc); got != want[i] {
+			t.Errorf("StructuralResource() = %q, want %q", got, want[i])
+		}
+	}
+}
+
+func TestStructuralLineResource(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{line: "--- staging\n", want: ""},
+		{line: "+++ production\n", want: ""},
+		{line: "", want: ""},
+		{line: "ConfigMap/only-in-a: removed\n", want: "ConfigMap/only-in-a"},
+		{line: "Secret/only-in-b: added", want: "Secret/only-in-b"},
+		{line: "Deployment.apps/app spec.replicas: 3 -> 1\n", want: "Deployment.apps/app"},
+		{line: "ClusterRole.rbac.authorization.k8s.io/system:controller:foo: added\n", want: "ClusterRole.rbac.authorization.k8s.io/system:controller:foo"},
+		{line: "ClusterRole.rbac.authorization.k8s.io/system:controller:foo rules[0].verbs[1]: added \"list\"\n", want: "ClusterRole.rbac.authorization.k8s.io/system:controller:foo"},
+		{line: "RoleBinding.rbac.authorization.k8s.io/prod/system:app metadata.labels.env: removed\n", want: "RoleBinding.rbac.authorization.k8s.io/prod/system:app"},
+		{line: "ClusterRole.rbac.authorization.k8s.io/read%20only: added\n", want: "ClusterRole.rbac.authorization.k8s.io/read%20only"},
+		{line: "ClusterRole.rbac.authorization.k8s.io/read%20only rules[0].verbs[0]: added \"get\"\n", want: "ClusterRole.rbac.authorization.k8s.io/read%20only"},
+	}
+	for _, tt := range tests {
+		if got := StructuralLineResource(tt.line); got != tt.want {
+			t.Errorf("StructuralLineResource(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Summary lists the resources, which differ between two sets of documents.
+type Summary struct {
+	Added     []string
+	Removed   []string
+	Changed   []string
+	Unchanged int
+}
+
+// Summarize returns which resources were added, removed or changed from A to
+// B. Resources are matched in the same way as in Structural.
+func Summarize(a, b []*unstructured.Unstructured) Summary {
+	var s Summary
+	changed := make(map[string]bool)
+	for _, change := range StructuralChanges(a, b) {
+		switch {
+		case len(change.Path) == 0 && change.Type == Added:
+			s.Added = append(s.Added, change.Resource)
+		case len(change.Path) == 0 && change.Type == Removed:
+			s.Removed = append(s.Removed, change.Resource)
+		case !changed[change.Resource]:
+			changed[change.Resource] = true
+			s.Changed = append(s.Changed, change.Resource)
+		}
+	}
+
+	for _, doc := range a {
+		if findDocument(b, doc) != -1 && !changed[StructuralResource(doc)] {
+			s.Unchanged++
+		}
+	}
+
+	return s
+}
+
+// Differs returns true if any resource was added, removed or changed.
+func (s Summary) Differs() bool {
+	return len(s.Added) > 0 || len(s.Removed) > 0 || len(s.Changed) > 0
+}
+
+func (s Summary) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d added, %d removed, %d changed, %d unchanged\n", len(s.Added), len(s.Removed), len(s.Changed), s.Unchanged)
+	for _, list := range []struct {
+		name      string
+		resources []string
+	}{
+		{"added", s.Added},
+		{"removed", s.Removed},
+		{"changed", s.Changed},
+	} {
+		for _, resource := range list.resources {
+			fmt.Fprintf(&sb, "  %-8s %s\n", list.name, resource)
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,38 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSummarize(t *testing.T) {
+	a := mustDecode(t, testStructuralA+"---\napiVersion: v1\nkind: Service\nmetadata:\n  name: same\n")
+	b := mustDecode(t, testStructuralB+"---\napiVersion: v1\nkind: Service\nmetadata:\n  name: same\n")
+
+	got := Summarize(a, b)
+	want := Summary{
+		Added:     []string{"Secret/only-in-b"},
+		Removed:   []string{"ConfigMap/only-in-a"},
+		Changed:   []string{"Deployment.apps/app"},
+		Unchanged: 1,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Summarize() = %v, want %v", got, want)
+	}
+
+	if !got.Differs() {
+		t.Errorf("Summary.Differs() = false, want true")
+	}
+
+	wantString := "1 added, 1 removed, 1 changed, 1 unchanged\n" +
+		"  added    Secret/only-in-b\n" +
+		"  removed  ConfigMap/only-in-a\n" +
+		"  changed  Deployment.apps/app\n"
+	if gotString := got.String(); gotString != wantString {
+		t.Errorf("Summary.String() = %q, want %q", gotString, wantString)
+	}
+
+	if same := Summarize(a, a); same.Differs() || same.Unchanged != 3 {
+		t.Errorf("Summarize() of equal documents = %v, want 3 unchanged", same)
+	}
+}
@@ -0,0 +1,33 @@
+// Package exitcode allows commands to exit with specific exit codes.
+package exitcode
+
+import "errors"
+
+// Error is an error, which makes kyml exit with the specified code.
+type Error struct {
+	Code int
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Get returns the exit code for the error: 0 if it's nil, the code of an
+// Error in its chain or 1 otherwise.
+func Get(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var exitErr *Error
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+
+	return 1
+}
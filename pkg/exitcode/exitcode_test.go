@@ -0,0 +1,43 @@
+package exitcode
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestGet(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{
+			name: "no error",
+			err:  nil,
+			want: 0,
+		},
+		{
+			name: "error",
+			err:  errors.New("failed"),
+			want: 1,
+		},
+		{
+			name: "exit code error",
+			err:  &Error{Code: 2, Err: errors.New("failed")},
+			want: 2,
+		},
+		{
+			name: "wrapped exit code error",
+			err:  fmt.Errorf("command: %w", &Error{Code: 3, Err: errors.New("failed")}),
+			want: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Get(tt.err); got != tt.want {
+				t.Errorf("Get() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package fieldpath
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type elementKind int
+
+const (
+	fieldElement elementKind = iota
+	indexElement
+	keyElement
+	wildcardElement
+)
+
+// Element is a single step in a Path. It is either a field in an object or an
+// item in a list, identified by its index or by the value of one of its fields.
+type Element struct {
+	kind  elementKind
+	field string
+	index int
+	value string
+}
+
+// Field returns an element describing the field with the specified name.
+func Field(name string) Element {
+	return Element{kind: fieldElement, field: name}
+}
+
+// Index returns an element describing the list item at the specified index.
+func Index(index int) Element {
+	return Element{kind: indexElement, index: index}
+}
+
+// Key returns an element describing the list item, whose field with the
+// specified name has the specified value, e.g. containers[name=app].
+func Key(field, value string) Element {
+	return Element{kind: keyElement, field: field, value: value}
+}
+
+// Wildcard returns an element describing all fields of an object or all items
+// of a list.
+func Wildcard() Element {
+	return Element{kind: wildcardElement}
+}
+
+// Path describes the location of a value inside a Kubernetes object, e.g.
+// spec.template.spec.containers[0].image.
+type Path []Element
+
+// Child returns a new path, which has the specified element appended. The
+// original path is never modified.
+func (p Path) Child(e Element) Path {
+	child := make(Path, len(p), len(p)+1)
+	copy(child, p)
+	return append(child, e)
+}
+
+var simpleFieldRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func (p Path) String() string {
+	var sb strings.Builder
+	for i, e := range p {
+		switch e.kind {
+		case fieldElement:
+			if simpleFieldRegexp.MatchString(e.field) {
+				if i > 0 {
+					sb.WriteString(".")
+				}
+				sb.WriteString(e.field)
+			} else {
+				sb.WriteString("[")
+				sb.WriteString(strconv.Quote(e.field))
+				sb.WriteString("]")
+			}
+		case indexElement:
+			sb.WriteString("[")
+			sb.WriteString(strconv.Itoa(e.index))
+			sb.WriteString("]")
+		case keyElement:
+			sb.WriteString("[")
+			sb.WriteString(e.field)
+			sb.WriteString("=")
+			sb.WriteString(e.value)
+			sb.WriteString("]")
+		case wildcardElement:
+			sb.WriteString("[*]")
+		}
+	}
+
+	return sb.String()
+}
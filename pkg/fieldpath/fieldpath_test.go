@@ -0,0 +1,50 @@
+package fieldpath
+
+import "testing"
+
+func TestPath_String(t *testing.T) {
+	tests := []struct {
+		name string
+		p    Path
+		want string
+	}{
+		{
+			name: "empty",
+			p:    nil,
+			want: "",
+		},
+		{
+			name: "fields and indices",
+			p:    Path{Field("spec"), Field("containers"), Index(0), Field("image")},
+			want: "spec.containers[0].image",
+		},
+		{
+			name: "field with special characters",
+			p:    Path{Field("metadata"), Field("annotations"), Field("example.com/owner")},
+			want: `metadata.annotations["example.com/owner"]`,
+		},
+		{
+			name: "keys",
+			p:    Path{Field("spec"), Field("containers"), Key("name", "app"), Field("image")},
+			want: "spec.containers[name=app].image",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.p.String(); got != tt.want {
+				t.Errorf("Path.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPath_Child(t *testing.T) {
+	parent := make(Path, 1, 10)
+	parent[0] = Field("spec")
+
+	a := parent.Child(Field("a"))
+	b := parent.Child(Field("b"))
+	if a.String() != "spec.a" || b.String() != "spec.b" {
+		t.Errorf("Path.Child() modified parent: a = %v, b = %v", a, b)
+	}
+}
@@ -0,0 +1,101 @@
+package fieldpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Parse parses the string representation of a path. In addition to the
+// format returned by Path.String, it accepts "*" as a field name or index to
+// match all fields of an object or all items of a list, e.g.
+// spec.template.spec.containers[*].resources or metadata.labels.*.
+func Parse(s string) (Path, error) {
+	var path Path
+	rest := s
+	for rest != "" {
+		if rest[0] == '[' {
+			end := closingBracket(rest)
+			if end == -1 {
+				return nil, fmt.Errorf("invalid path %s: missing closing bracket", s)
+			}
+
+			e, err := parseBracket(rest[1:end])
+			if err != nil {
+				return nil, fmt.Errorf("invalid path %s: %v", s, err)
+			}
+
+			path = append(path, e)
+			rest = rest[end+1:]
+		} else {
+			if len(path) > 0 {
+				if rest[0] != '.' {
+					return nil, fmt.Errorf("invalid path %s: expected . or [ after %s", s, path)
+				}
+				rest = rest[1:]
+			}
+
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+
+			name := rest[:end]
+			if name == "" {
+				return nil, fmt.Errorf("invalid path %s: empty field name", s)
+			}
+
+			if name == "*" {
+				path = append(path, Wildcard())
+			} else {
+				path = append(path, Field(name))
+			}
+
+			rest = rest[end:]
+		}
+	}
+
+	return path, nil
+}
+
+// closingBracket returns the index of the bracket closing the one at the
+// start of s. Brackets inside quoted strings are skipped.
+func closingBracket(s string) int {
+	inQuotes := false
+	for i := 1; i < len(s); i++ {
+		switch {
+		case inQuotes && s[i] == '\\':
+			i++
+		case s[i] == '"':
+			inQuotes = !inQuotes
+		case !inQuotes && s[i] == ']':
+			return i
+		}
+	}
+
+	return -1
+}
+
+func parseBracket(s string) (Element, error) {
+	switch {
+	case s == "*":
+		return Wildcard(), nil
+	case strings.HasPrefix(s, `"`):
+		field, err := strconv.Unquote(s)
+		if err != nil {
+			return Element{}, fmt.Errorf("invalid quoted field %s", s)
+		}
+
+		return Field(field), nil
+	case strings.Contains(s, "="):
+		parts := strings.SplitN(s, "=", 2)
+		return Key(parts[0], parts[1]), nil
+	default:
+		index, err := strconv.Atoi(s)
+		if err != nil || index < 0 {
+			return Element{}, fmt.Errorf("invalid index %s", s)
+		}
+
+		return Index(index), nil
+	}
+}
@@ -0,0 +1,82 @@
+package fieldpath
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    Path
+		wantErr bool
+	}{
+		{
+			name:    "fields and indices",
+			s:       "spec.containers[0].image",
+			want:    Path{Field("spec"), Field("containers"), Index(0), Field("image")},
+			wantErr: false,
+		},
+		{
+			name:    "quoted field",
+			s:       `metadata.annotations["example.com/owner"]`,
+			want:    Path{Field("metadata"), Field("annotations"), Field("example.com/owner")},
+			wantErr: false,
+		},
+		{
+			name:    "quoted field with brackets",
+			s:       `data["a]b"]`,
+			want:    Path{Field("data"), Field("a]b")},
+			wantErr: false,
+		},
+		{
+			name:    "keys",
+			s:       "spec.containers[name=app].image",
+			want:    Path{Field("spec"), Field("containers"), Key("name", "app"), Field("image")},
+			wantErr: false,
+		},
+		{
+			name:    "wildcards",
+			s:       "spec.containers[*].resources.*",
+			want:    Path{Field("spec"), Field("containers"), Wildcard(), Field("resources"), Wildcard()},
+			wantErr: false,
+		},
+		{
+			name:    "leading bracket",
+			s:       `["kyml.io/x"].y`,
+			want:    Path{Field("kyml.io/x"), Field("y")},
+			wantErr: false,
+		},
+		{
+			name:    "empty field",
+			s:       "spec..replicas",
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "missing closing bracket",
+			s:       "spec.containers[0",
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "invalid index",
+			s:       "spec.containers[a]",
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package fieldpath
+
+// Remove deletes all values matching the specified path from the object. Paths
+// may contain wildcards. Values, which don't exist, are ignored.
+func Remove(obj map[string]interface{}, p Path) {
+	if len(p) == 0 {
+		return
+	}
+
+	removeFrom(obj, p)
+}
+
+// removeFrom deletes the values matching the path from the specified object or
+// list and returns the new value. Lists have to be returned, since removing
+// items creates a new slice.
+func removeFrom(value interface{}, p Path) interface{} {
+	e, last := p[0], len(p) == 1
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, child := range value {
+			if !e.matchesField(key) {
+				continue
+			}
+
+			if last {
+				delete(value, key)
+			} else {
+				value[key] = removeFrom(child, p[1:])
+			}
+		}
+	case []interface{}:
+		result := value[:0]
+		for i, child := range value {
+			if !e.matchesItem(i, child) {
+				result = append(result, child)
+			} else if !last {
+				result = append(result, removeFrom(child, p[1:]))
+			}
+		}
+
+		return result
+	}
+
+	return value
+}
+
+func (e Element) matchesField(name string) bool {
+	return e.kind == wildcardElement || (e.kind == fieldElement && e.field == name)
+}
+
+func (e Element) matchesItem(index int, item interface{}) bool {
+	switch e.kind {
+	case wildcardElement:
+		return true
+	case indexElement:
+		return e.index == index
+	case keyElement:
+		obj, ok := item.(map[string]interface{})
+		return ok && obj[e.field] == e.value
+	default:
+		return false
+	}
+}
@@ -0,0 +1,98 @@
+package fieldpath
+
+import (
+	"reflect"
+	"testing"
+)
+
+func testObject() map[string]interface{} {
+	return map[string]interface{}{
+		"spec": map[string]interface{}{
+			"replicas": int64(3),
+			"containers": []interface{}{
+				map[string]interface{}{"name": "app", "image": "kyml/app", "resources": map[string]interface{}{"cpu": "1"}},
+				map[string]interface{}{"name": "sidecar", "image": "kyml/sidecar", "resources": map[string]interface{}{"cpu": "2"}},
+			},
+		},
+	}
+}
+
+func TestRemove(t *testing.T) {
+	tests := []struct {
+		name string
+		path Path
+		want map[string]interface{}
+	}{
+		{
+			name: "field",
+			path: Path{Field("spec"), Field("replicas")},
+			want: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "app", "image": "kyml/app", "resources": map[string]interface{}{"cpu": "1"}},
+						map[string]interface{}{"name": "sidecar", "image": "kyml/sidecar", "resources": map[string]interface{}{"cpu": "2"}},
+					},
+				},
+			},
+		},
+		{
+			name: "wildcard in list",
+			path: Path{Field("spec"), Field("containers"), Wildcard(), Field("resources")},
+			want: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"replicas": int64(3),
+					"containers": []interface{}{
+						map[string]interface{}{"name": "app", "image": "kyml/app"},
+						map[string]interface{}{"name": "sidecar", "image": "kyml/sidecar"},
+					},
+				},
+			},
+		},
+		{
+			name: "list item by key",
+			path: Path{Field("spec"), Field("containers"), Key("name", "sidecar")},
+			want: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"replicas": int64(3),
+					"containers": []interface{}{
+						map[string]interface{}{"name": "app", "image": "kyml/app", "resources": map[string]interface{}{"cpu": "1"}},
+					},
+				},
+			},
+		},
+		{
+			name: "list item by index",
+			path: Path{Field("spec"), Field("containers"), Index(0), Field("image")},
+			want: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"replicas": int64(3),
+					"containers": []interface{}{
+						map[string]interface{}{"name": "app", "resources": map[string]interface{}{"cpu": "1"}},
+						map[string]interface{}{"name": "sidecar", "image": "kyml/sidecar", "resources": map[string]interface{}{"cpu": "2"}},
+					},
+				},
+			},
+		},
+		{
+			name: "wildcard field",
+			path: Path{Field("spec"), Wildcard()},
+			want: map[string]interface{}{
+				"spec": map[string]interface{}{},
+			},
+		},
+		{
+			name: "missing field",
+			path: Path{Field("status"), Field("replicas")},
+			want: testObject(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := testObject()
+			Remove(got, tt.path)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Remove() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
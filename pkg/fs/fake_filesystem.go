@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"sort"
 )
 
 type fakeFilesystem struct {
@@ -19,6 +21,17 @@ func NewFakeFilesystem() Filesystem {
 	}
 }
 
+// NewFakeFilesystemWithFiles creates a new filesystem prefilled with the
+// specified files, which map file names to their content.
+func NewFakeFilesystemWithFiles(files map[string]string) Filesystem {
+	fs := NewFakeFilesystem()
+	for name, data := range files {
+		_ = fs.WriteFile(name, []byte(data), 0644)
+	}
+
+	return fs
+}
+
 // NewFakeFilesystemFromDisk creates a new filesystem prefilled with the
 // specified files, which are read from disk.
 func NewFakeFilesystemFromDisk(files ...string) (Filesystem, error) {
@@ -62,3 +75,34 @@ func (fs *fakeFilesystem) WriteFile(filename string, data []byte, perm os.FileMo
 	fs.fileModes[filename] = perm
 	return nil
 }
+
+func (fs *fakeFilesystem) Glob(pattern string) ([]string, error) {
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+
+	var matches []string
+	for filename := range fs.files {
+		if matched, _ := filepath.Match(pattern, filename); matched {
+			matches = append(matches, filename)
+		}
+	}
+
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// MkdirAll does nothing, because the fake filesystem doesn't have directories.
+func (fs *fakeFilesystem) MkdirAll(path string, perm os.FileMode) error {
+	return nil
+}
+
+func (fs *fakeFilesystem) Remove(name string) error {
+	if _, ok := fs.files[name]; !ok {
+		return os.ErrNotExist
+	}
+
+	delete(fs.files, name)
+	delete(fs.fileModes, name)
+	return nil
+}
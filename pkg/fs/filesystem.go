@@ -2,10 +2,13 @@ package fs
 
 import "os"
 
-// Filesystem is an interface abstraction for some file system methods on `os`
-// and `ioutil`.
+// Filesystem is an interface abstraction for some file system methods on `os`,
+// `ioutil` and `filepath`.
 type Filesystem interface {
 	Open(name string) (File, error)
 	ReadFile(filename string) ([]byte, error)
 	WriteFile(filename string, data []byte, perm os.FileMode) error
+	Glob(pattern string) ([]string, error)
+	MkdirAll(path string, perm os.FileMode) error
+	Remove(name string) error
 }
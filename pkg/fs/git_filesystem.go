@@ -0,0 +1,171 @@
+package fs
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type gitFilesystem struct {
+	dir    string
+	rev    string
+	commit string
+	root   string
+	prefix string
+	files  map[string]bool
+}
+
+// NewGitFilesystem creates a new read-only filesystem, which contains the files
+// of the git repository in the specified directory at the specified revision.
+// Relative paths are relative to the directory. It uses the git CLI and
+// only reads the local repository.
+func NewGitFilesystem(dir, rev string) (Filesystem, error) {
+	fs := &gitFilesystem{dir: dir, rev: rev}
+
+	out, err := fs.git("rev-parse", "--show-toplevel", "--show-prefix")
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.SplitN(string(out), "\n", 3)
+	if len(lines) < 2 {
+		return nil, fmt.Errorf("cannot find git repository")
+	}
+	fs.root = filepath.FromSlash(lines[0])
+	fs.prefix = strings.TrimSuffix(lines[1], "/")
+	if fs.prefix == "" {
+		fs.prefix = "."
+	}
+
+	out, err = fs.git("rev-parse", "--verify", "--quiet", "--end-of-options", rev+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("unknown git revision %s", rev)
+	}
+	fs.commit = strings.TrimSpace(string(out))
+
+	out, err = fs.git("ls-tree", "-r", "-z", "--name-only", "--full-tree", fs.commit)
+	if err != nil {
+		return nil, err
+	}
+
+	fs.files = make(map[string]bool)
+	for _, file := range strings.Split(string(out), "\x00") {
+		if file != "" {
+			fs.files[file] = true
+		}
+	}
+
+	return fs, nil
+}
+
+func (fs *gitFilesystem) git(args ...string) ([]byte, error) {
+	command := args[0]
+	if fs.dir != "" {
+		args = append([]string{"-C", fs.dir}, args...)
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("git %s: %s", command, message)
+		}
+
+		return nil, fmt.Errorf("git: %v", err)
+	}
+
+	return out, nil
+}
+
+// repoPath returns the path of the file relative to the repository root using
+// forward slashes. It returns false if the file is outside of the repository.
+func (fs *gitFilesystem) repoPath(name string) (string, bool) {
+	var p string
+	if filepath.IsAbs(name) {
+		rel, err := filepath.Rel(fs.root, name)
+		if err != nil {
+			return "", false
+		}
+		p = filepath.ToSlash(rel)
+	} else {
+		p = path.Join(fs.prefix, filepath.ToSlash(name))
+	}
+
+	if p == ".." || strings.HasPrefix(p, "../") {
+		return "", false
+	}
+
+	return p, true
+}
+
+func (fs *gitFilesystem) Open(name string) (File, error) {
+	data, err := fs.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fakeFile{bytes.NewBuffer(data), false}, nil
+}
+
+func (fs *gitFilesystem) ReadFile(filename string) ([]byte, error) {
+	p, ok := fs.repoPath(filename)
+	if !ok || !fs.files[p] {
+		return nil, &os.PathError{Op: "open", Path: filename + "@" + fs.rev, Err: os.ErrNotExist}
+	}
+
+	return fs.git("cat-file", "blob", fs.commit+":"+p)
+}
+
+func (fs *gitFilesystem) WriteFile(filename string, data []byte, perm os.FileMode) error {
+	return fs.readOnly(filename)
+}
+
+func (fs *gitFilesystem) Glob(pattern string) ([]string, error) {
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+
+	repoPattern, ok := fs.repoPath(pattern)
+	if !ok {
+		return nil, nil
+	}
+
+	var matches []string
+	for file := range fs.files {
+		if matched, _ := path.Match(repoPattern, file); !matched {
+			continue
+		}
+
+		if filepath.IsAbs(pattern) {
+			matches = append(matches, filepath.Join(fs.root, filepath.FromSlash(file)))
+		} else {
+			rel, err := filepath.Rel(filepath.FromSlash(fs.prefix), filepath.FromSlash(file))
+			if err != nil {
+				return nil, err
+			}
+			matches = append(matches, rel)
+		}
+	}
+
+	sort.Strings(matches)
+	return matches, nil
+}
+
+func (fs *gitFilesystem) MkdirAll(path string, perm os.FileMode) error {
+	return fs.readOnly(path)
+}
+
+func (fs *gitFilesystem) Remove(name string) error {
+	return fs.readOnly(name)
+}
+
+func (fs *gitFilesystem) readOnly(name string) error {
+	return fmt.Errorf("cannot change %s: files at git revision %s are read-only", name, fs.rev)
+}
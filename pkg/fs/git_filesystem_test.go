@@ -0,0 +1,116 @@
+package fs
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func mustCreateGitRepository(t *testing.T) string {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir, err := ioutil.TempDir("", "kyml-git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	run := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=kyml", "-c", "user.email=kyml@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, data string) {
+		name = filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(name, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run("init", "-q")
+	write("production/deployment.yaml", "replicas: 3\n")
+	write("production/service.yaml", "port: 80\n")
+	write("staging/deployment.yaml", "replicas: 1\n")
+	run("add", ".")
+	run("commit", "-q", "-m", "first")
+	run("tag", "first")
+	write("production/deployment.yaml", "replicas: 5\n")
+	write("production/ingress.yaml", "host: example.com\n")
+	run("add", ".")
+	run("commit", "-q", "-m", "second")
+
+	return dir
+}
+
+func TestGitFilesystem(t *testing.T) {
+	dir := mustCreateGitRepository(t)
+
+	fs, err := NewGitFilesystem(dir, "first")
+	if err != nil {
+		t.Fatalf("NewGitFilesystem() error = %v", err)
+	}
+
+	data, err := fs.ReadFile("production/deployment.yaml")
+	if err != nil || string(data) != "replicas: 3\n" {
+		t.Errorf("ReadFile() = %q, %v, want %q", data, err, "replicas: 3\n")
+	}
+
+	data, err = fs.ReadFile(filepath.Join(dir, "production", "service.yaml"))
+	if err != nil || string(data) != "port: 80\n" {
+		t.Errorf("ReadFile() with absolute path = %q, %v, want %q", data, err, "port: 80\n")
+	}
+
+	if _, err := fs.ReadFile("production/ingress.yaml"); !os.IsNotExist(err) {
+		t.Errorf("ReadFile() of file missing at revision error = %v, want not exist", err)
+	}
+
+	matches, err := fs.Glob("production/*.yaml")
+	if want := []string{"production/deployment.yaml", "production/service.yaml"}; err != nil || !reflect.DeepEqual(matches, want) {
+		t.Errorf("Glob() = %v, %v, want %v", matches, err, want)
+	}
+
+	if err := fs.WriteFile("production/deployment.yaml", nil, 0644); err == nil {
+		t.Errorf("WriteFile() error = nil, want read-only error")
+	}
+
+	if _, err := NewGitFilesystem(dir, "does-not-exist"); err == nil {
+		t.Errorf("NewGitFilesystem() of unknown revision error = nil, want error")
+	}
+}
+
+func TestGitFilesystem_subdirectory(t *testing.T) {
+	dir := mustCreateGitRepository(t)
+
+	fs, err := NewGitFilesystem(filepath.Join(dir, "production"), "HEAD")
+	if err != nil {
+		t.Fatalf("NewGitFilesystem() error = %v", err)
+	}
+
+	data, err := fs.ReadFile("deployment.yaml")
+	if err != nil || string(data) != "replicas: 5\n" {
+		t.Errorf("ReadFile() = %q, %v, want %q", data, err, "replicas: 5\n")
+	}
+
+	data, err = fs.ReadFile("../staging/deployment.yaml")
+	if err != nil || string(data) != "replicas: 1\n" {
+		t.Errorf("ReadFile() of parent directory = %q, %v, want %q", data, err, "replicas: 1\n")
+	}
+
+	matches, err := fs.Glob("*.yaml")
+	if want := []string{"deployment.yaml", "ingress.yaml", "service.yaml"}; err != nil || !reflect.DeepEqual(matches, want) {
+		t.Errorf("Glob() = %v, %v, want %v", matches, err, want)
+	}
+
+	if _, err := fs.ReadFile("../../outside.yaml"); !os.IsNotExist(err) {
+		t.Errorf("ReadFile() outside of repository error = %v, want not exist", err)
+	}
+}
@@ -3,6 +3,7 @@ package fs
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
 )
 
 type osFilesystem struct{}
@@ -28,3 +29,15 @@ func (fs osFilesystem) ReadFile(filename string) ([]byte, error) {
 func (fs osFilesystem) WriteFile(filename string, data []byte, perm os.FileMode) error {
 	return ioutil.WriteFile(filename, data, perm)
 }
+
+func (fs osFilesystem) Glob(pattern string) ([]string, error) {
+	return filepath.Glob(pattern)
+}
+
+func (fs osFilesystem) MkdirAll(path string, perm os.FileMode) error {
+	return os.MkdirAll(path, perm)
+}
+
+func (fs osFilesystem) Remove(name string) error {
+	return os.Remove(name)
+}
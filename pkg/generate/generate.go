@@ -0,0 +1,183 @@
+package generate
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/frigus02/kyml/pkg/fs"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var validKey = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+
+// Data contains the keys and values of a ConfigMap or Secret.
+type Data map[string][]byte
+
+func (d Data) add(key string, value []byte) error {
+	if len(key) > 253 || !validKey.MatchString(key) || key == "." || key == ".." {
+		return fmt.Errorf("key %q is invalid: keys must consist of alphanumeric characters, '-', '_' or '.'", key)
+	}
+
+	if _, ok := d[key]; ok {
+		return fmt.Errorf("key %q is specified multiple times", key)
+	}
+
+	d[key] = value
+	return nil
+}
+
+// AddLiteral adds a value in the form key=value.
+func (d Data) AddLiteral(literal string) error {
+	parts := strings.SplitN(literal, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return fmt.Errorf("literal %q must be in the form key=value", literal)
+	}
+
+	return d.add(parts[0], []byte(parts[1]))
+}
+
+// AddFile adds the content of a file using the file name as key. The source
+// can also specify the key as key=path. If the path is a directory, all files
+// in it are added. Subdirectories are ignored.
+func (d Data) AddFile(source string, fs fs.Filesystem) error {
+	key, path := "", source
+	if parts := strings.SplitN(source, "=", 2); len(parts) == 2 {
+		key, path = parts[0], parts[1]
+		if key == "" || path == "" {
+			return fmt.Errorf("file %q must be in the form path or key=path", source)
+		}
+	}
+
+	data, err := fs.ReadFile(path)
+	if err == nil {
+		if key == "" {
+			key = filepath.Base(path)
+		}
+
+		return d.add(key, data)
+	}
+
+	files, globErr := fs.Glob(filepath.Join(path, "*"))
+	if globErr != nil || len(files) == 0 {
+		return fmt.Errorf("cannot read file %s: %v", path, err)
+	}
+
+	if key != "" {
+		return fmt.Errorf("cannot specify a key for directory %s", path)
+	}
+
+	for _, file := range files {
+		data, err := fs.ReadFile(file)
+		if err != nil {
+			// Subdirectories cannot be read and are skipped.
+			continue
+		}
+
+		if err := d.add(filepath.Base(file), data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AddEnvFile adds values from a file with lines in the form KEY=VALUE. Empty
+// lines and lines starting with # are ignored. Values are used as they are,
+// including quotes.
+func (d Data) AddEnvFile(path string, fs fs.Filesystem) error {
+	data, err := fs.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("cannot read env file %s: %v", path, err)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimLeft(scanner.Text(), " \t")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return fmt.Errorf("env file %s, line %d: expected KEY=VALUE", path, lineNumber)
+		}
+
+		if err := d.add(parts[0], []byte(parts[1])); err != nil {
+			return fmt.Errorf("env file %s, line %d: %v", path, lineNumber, err)
+		}
+	}
+
+	return scanner.Err()
+}
+
+func (d Data) keys() []string {
+	keys := make([]string, 0, len(d))
+	for key := range d {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// ConfigMap creates a ConfigMap with the data. Values, which are not valid
+// UTF-8, are stored base64 encoded in binaryData.
+func ConfigMap(name, namespace string, data Data) *unstructured.Unstructured {
+	doc := newDocument("ConfigMap", name, namespace)
+
+	stringData := make(map[string]interface{})
+	binaryData := make(map[string]interface{})
+	for _, key := range data.keys() {
+		if value := data[key]; utf8.Valid(value) {
+			stringData[key] = string(value)
+		} else {
+			binaryData[key] = base64.StdEncoding.EncodeToString(value)
+		}
+	}
+
+	if len(stringData) > 0 {
+		doc.Object["data"] = stringData
+	}
+	if len(binaryData) > 0 {
+		doc.Object["binaryData"] = binaryData
+	}
+
+	return doc
+}
+
+// Secret creates a Secret of the type with the base64 encoded data.
+func Secret(name, namespace, secretType string, data Data) *unstructured.Unstructured {
+	doc := newDocument("Secret", name, namespace)
+	doc.Object["type"] = secretType
+
+	encoded := make(map[string]interface{}, len(data))
+	for _, key := range data.keys() {
+		encoded[key] = base64.StdEncoding.EncodeToString(data[key])
+	}
+
+	if len(encoded) > 0 {
+		doc.Object["data"] = encoded
+	}
+
+	return doc
+}
+
+func newDocument(kind, name, namespace string) *unstructured.Unstructured {
+	doc := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	doc.SetAPIVersion("v1")
+	doc.SetKind(kind)
+	doc.SetName(name)
+	if namespace != "" {
+		doc.SetNamespace(namespace)
+	}
+
+	return doc
+}
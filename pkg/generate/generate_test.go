@@ -0,0 +1,157 @@
+package generate
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/frigus02/kyml/pkg/fs"
+)
+
+func TestData(t *testing.T) {
+	fs := fs.NewFakeFilesystemWithFiles(map[string]string{
+		"config/app.properties": "debug=true\n",
+		"config/log.xml":        "<log/>",
+		"certs/tls.crt":         "cert",
+		"production.env":        "# comment\n\nLOG_LEVEL=info\n  QUOTED=\"value\"\nEMPTY=\n",
+		"invalid.env":           "LOG_LEVEL\n",
+	})
+
+	tests := []struct {
+		name             string
+		files            []string
+		literals         []string
+		envFiles         []string
+		want             Data
+		wantErrToContain string
+	}{
+		{
+			name:  "file",
+			files: []string{"certs/tls.crt"},
+			want:  Data{"tls.crt": []byte("cert")},
+		},
+		{
+			name:  "file with key",
+			files: []string{"cert=certs/tls.crt"},
+			want:  Data{"cert": []byte("cert")},
+		},
+		{
+			name:  "directory",
+			files: []string{"config"},
+			want:  Data{"app.properties": []byte("debug=true\n"), "log.xml": []byte("<log/>")},
+		},
+		{
+			name:             "directory with key",
+			files:            []string{"config=config"},
+			wantErrToContain: "cannot specify a key for directory config",
+		},
+		{
+			name:             "file doesn't exist",
+			files:            []string{"missing.txt"},
+			wantErrToContain: "cannot read file missing.txt",
+		},
+		{
+			name:     "literals",
+			literals: []string{"env=production", "url=http://a?b=c"},
+			want:     Data{"env": []byte("production"), "url": []byte("http://a?b=c")},
+		},
+		{
+			name:             "invalid literal",
+			literals:         []string{"production"},
+			wantErrToContain: "literal \"production\" must be in the form key=value",
+		},
+		{
+			name:             "invalid key",
+			literals:         []string{"a/b=c"},
+			wantErrToContain: "key \"a/b\" is invalid",
+		},
+		{
+			name:     "env file",
+			envFiles: []string{"production.env"},
+			want:     Data{"LOG_LEVEL": []byte("info"), "QUOTED": []byte("\"value\""), "EMPTY": []byte("")},
+		},
+		{
+			name:             "invalid env file",
+			envFiles:         []string{"invalid.env"},
+			wantErrToContain: "env file invalid.env, line 1: expected KEY=VALUE",
+		},
+		{
+			name:             "duplicate key",
+			files:            []string{"certs/tls.crt"},
+			literals:         []string{"tls.crt=other"},
+			wantErrToContain: "key \"tls.crt\" is specified multiple times",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := make(Data)
+			var err error
+			for _, file := range tt.files {
+				if err == nil {
+					err = data.AddFile(file, fs)
+				}
+			}
+			for _, literal := range tt.literals {
+				if err == nil {
+					err = data.AddLiteral(literal)
+				}
+			}
+			for _, envFile := range tt.envFiles {
+				if err == nil {
+					err = data.AddEnvFile(envFile, fs)
+				}
+			}
+
+			if tt.wantErrToContain != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErrToContain) {
+					t.Errorf("Data error = %v, wantErrToContain %v", err, tt.wantErrToContain)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Data error = %v", err)
+			}
+			if !reflect.DeepEqual(data, tt.want) {
+				t.Errorf("Data = %v, want %v", data, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigMap(t *testing.T) {
+	got := ConfigMap("config", "default", Data{"text": []byte("hello"), "binary": {0xff, 0x00}})
+	want := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "config", "namespace": "default"},
+		"data":       map[string]interface{}{"text": "hello"},
+		"binaryData": map[string]interface{}{"binary": "/wA="},
+	}
+	if !reflect.DeepEqual(got.Object, want) {
+		t.Errorf("ConfigMap() = %v, want %v", got.Object, want)
+	}
+
+	got = ConfigMap("empty", "", Data{})
+	want = map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "empty"},
+	}
+	if !reflect.DeepEqual(got.Object, want) {
+		t.Errorf("ConfigMap() = %v, want %v", got.Object, want)
+	}
+}
+
+func TestSecret(t *testing.T) {
+	got := Secret("tls", "", "kubernetes.io/tls", Data{"tls.crt": []byte("cert"), "tls.key": []byte("key")})
+	want := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   map[string]interface{}{"name": "tls"},
+		"type":       "kubernetes.io/tls",
+		"data":       map[string]interface{}{"tls.crt": "Y2VydA==", "tls.key": "a2V5"},
+	}
+	if !reflect.DeepEqual(got.Object, want) {
+		t.Errorf("Secret() = %v, want %v", got.Object, want)
+	}
+}
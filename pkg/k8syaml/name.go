@@ -0,0 +1,37 @@
+package k8syaml
+
+import (
+	"bytes"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ResourceName returns a human readable name for the specified Kubernetes
+// resource in the form kind/name or kind/namespace/name.
+func ResourceName(doc *unstructured.Unstructured) string {
+	if namespace := doc.GetNamespace(); namespace != "" {
+		return doc.GetKind() + "/" + namespace + "/" + doc.GetName()
+	}
+
+	return doc.GetKind() + "/" + doc.GetName()
+}
+
+// LineResources returns the name of the resource every line of the documents
+// encoded using Encode belongs to.
+func LineResources(documents []*unstructured.Unstructured) ([]string, error) {
+	var resources []string
+	for _, doc := range documents {
+		var buf bytes.Buffer
+		if err := Encode(&buf, []*unstructured.Unstructured{doc}); err != nil {
+			return nil, err
+		}
+
+		name := ResourceName(doc)
+		for i := strings.Count(buf.String(), "\n"); i > 0; i-- {
+			resources = append(resources, name)
+		}
+	}
+
+	return resources, nil
+}
@@ -0,0 +1,61 @@
+package k8syaml
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestResourceName(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  *unstructured.Unstructured
+		want string
+	}{
+		{
+			name: "cluster scoped",
+			doc:  unstructuredDocuments[0],
+			want: "Namespace/the-namespace",
+		},
+		{
+			name: "namespaced",
+			doc:  unstructuredDocuments[1],
+			want: "Service/the-namespace/the-service",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ResourceName(tt.doc); got != tt.want {
+				t.Errorf("ResourceName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLineResources(t *testing.T) {
+	got, err := LineResources(unstructuredDocuments)
+	if err != nil {
+		t.Fatalf("LineResources() error = %v", err)
+	}
+
+	want := []string{
+		"Namespace/the-namespace",
+		"Namespace/the-namespace",
+		"Namespace/the-namespace",
+		"Namespace/the-namespace",
+		"Namespace/the-namespace",
+		"Service/the-namespace/the-service",
+		"Service/the-namespace/the-service",
+		"Service/the-namespace/the-service",
+		"Service/the-namespace/the-service",
+		"Service/the-namespace/the-service",
+		"Service/the-namespace/the-service",
+		"Service/the-namespace/the-service",
+		"Service/the-namespace/the-service",
+		"Service/the-namespace/the-service",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LineResources() = %v, want %v", got, want)
+	}
+}
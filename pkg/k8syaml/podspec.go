@@ -1,17 +1,12 @@
-package resolve
+package k8syaml
 
-import (
-	"github.com/frigus02/kyml/pkg/k8syaml"
+import "k8s.io/apimachinery/pkg/runtime/schema"
 
-	"k8s.io/apimachinery/pkg/runtime/schema"
-)
-
-// We only want to resolve images mentioned in the `image` property of
-// containers. These currently only appear in PodSpec, which is under the path
-// spec.template.spec in the listed resource kinds.
+// Workload resources contain a PodSpec, which is under the path
+// spec.template.spec in most of the listed resource kinds.
 //
 // See: https://kubernetes.io/docs/reference/generated/kubernetes-api/v1.13/#container-v1-core
-var supportedKinds = []struct {
+var workloadKinds = []struct {
 	GroupVersionKind schema.GroupVersionKind
 	PathToPodSpec    []string
 }{
@@ -35,6 +30,10 @@ var supportedKinds = []struct {
 		GroupVersionKind: schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "Job"},
 		PathToPodSpec:    []string{"spec", "template", "spec"},
 	},
+	{
+		GroupVersionKind: schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "CronJob"},
+		PathToPodSpec:    []string{"spec", "jobTemplate", "spec", "template", "spec"},
+	},
 	{
 		GroupVersionKind: schema.GroupVersionKind{Group: "batch", Version: "v1beta1", Kind: "CronJob"},
 		PathToPodSpec:    []string{"spec", "jobTemplate", "spec", "template", "spec"},
@@ -45,9 +44,11 @@ var supportedKinds = []struct {
 	},
 }
 
-func getPathToPodSpec(gvk schema.GroupVersionKind) []string {
-	for _, kind := range supportedKinds {
-		if k8syaml.GVKEquals(gvk, kind.GroupVersionKind) {
+// PathToPodSpec returns the path to the PodSpec in workload resources of the
+// specified kind. It returns nil for all other kinds.
+func PathToPodSpec(gvk schema.GroupVersionKind) []string {
+	for _, kind := range workloadKinds {
+		if GVKEquals(gvk, kind.GroupVersionKind) {
 			return kind.PathToPodSpec
 		}
 	}
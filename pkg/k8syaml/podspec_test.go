@@ -1,4 +1,4 @@
-package resolve
+package k8syaml
 
 import (
 	"reflect"
@@ -7,7 +7,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
-func Test_getPathToPodSpec(t *testing.T) {
+func TestPathToPodSpec(t *testing.T) {
 	type args struct {
 		gvk schema.GroupVersionKind
 	}
@@ -21,6 +21,11 @@ func Test_getPathToPodSpec(t *testing.T) {
 			args: args{schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}},
 			want: []string{"spec", "template", "spec"},
 		},
+		{
+			name: "supported cron job",
+			args: args{schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "CronJob"}},
+			want: []string{"spec", "jobTemplate", "spec", "template", "spec"},
+		},
 		{
 			name: "not supported",
 			args: args{schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Service"}},
@@ -29,8 +34,8 @@ func Test_getPathToPodSpec(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := getPathToPodSpec(tt.args.gvk); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("getPathToPodSpec() = %v, want %v", got, tt.want)
+			if got := PathToPodSpec(tt.args.gvk); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PathToPodSpec() = %v, want %v", got, tt.want)
 			}
 		})
 	}
@@ -0,0 +1,63 @@
+package kubeversion
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a Kubernetes minor version like 1.29. Patch versions are not
+// relevant for API availability and therefore not represented.
+type Version struct {
+	Major int
+	Minor int
+}
+
+// Parse parses a Kubernetes version. It accepts "1.29", "v1.29" and "1.29.3".
+// A patch version is ignored.
+func Parse(s string) (Version, error) {
+	parts := strings.Split(strings.TrimPrefix(s, "v"), ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return Version{}, fmt.Errorf("invalid Kubernetes version \"%s\" (expected format is 1.29)", s)
+	}
+
+	major, err := strconv.Atoi(parts[0])
+	if err != nil || major < 0 {
+		return Version{}, fmt.Errorf("invalid Kubernetes version \"%s\" (expected format is 1.29)", s)
+	}
+
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil || minor < 0 {
+		return Version{}, fmt.Errorf("invalid Kubernetes version \"%s\" (expected format is 1.29)", s)
+	}
+
+	return Version{Major: major, Minor: minor}, nil
+}
+
+// MustParse works like Parse, but panics if the version is invalid.
+func MustParse(s string) Version {
+	v, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return v
+}
+
+// Less returns true if v is an older version than other.
+func (v Version) Less(other Version) bool {
+	if v.Major != other.Major {
+		return v.Major < other.Major
+	}
+
+	return v.Minor < other.Minor
+}
+
+// AtLeast returns true if v is the same or a newer version than other.
+func (v Version) AtLeast(other Version) bool {
+	return !v.Less(other)
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
@@ -0,0 +1,87 @@
+package kubeversion
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    Version
+		wantErr bool
+	}{
+		{
+			name:    "major and minor",
+			s:       "1.29",
+			want:    Version{Major: 1, Minor: 29},
+			wantErr: false,
+		},
+		{
+			name:    "v prefix and patch version",
+			s:       "v1.25.3",
+			want:    Version{Major: 1, Minor: 25},
+			wantErr: false,
+		},
+		{
+			name:    "missing minor",
+			s:       "1",
+			want:    Version{},
+			wantErr: true,
+		},
+		{
+			name:    "not a number",
+			s:       "1.x",
+			want:    Version{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVersion_Less(t *testing.T) {
+	tests := []struct {
+		name string
+		a    Version
+		b    Version
+		want bool
+	}{
+		{
+			name: "older minor",
+			a:    MustParse("1.9"),
+			b:    MustParse("1.10"),
+			want: true,
+		},
+		{
+			name: "equal",
+			a:    MustParse("1.29"),
+			b:    MustParse("1.29"),
+			want: false,
+		},
+		{
+			name: "newer major",
+			a:    MustParse("2.0"),
+			b:    MustParse("1.29"),
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.Less(tt.b); got != tt.want {
+				t.Errorf("Version.Less() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
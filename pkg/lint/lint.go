@@ -0,0 +1,115 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/frigus02/kyml/pkg/fieldpath"
+	"github.com/frigus02/kyml/pkg/k8syaml"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// IgnoreAnnotation lists IDs of rules, which should not be checked for the
+// annotated document, separated by commas.
+const IgnoreAnnotation = "kyml.io/lint-ignore"
+
+// Severity describes how important a finding is.
+type Severity int
+
+// Available severities.
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+}
+
+// ParseSeverity parses the string representation of a severity.
+func ParseSeverity(s string) (Severity, error) {
+	switch s {
+	case "info":
+		return SeverityInfo, nil
+	case "warning":
+		return SeverityWarning, nil
+	case "error":
+		return SeverityError, nil
+	default:
+		return 0, fmt.Errorf("invalid severity \"%s\" (supported are info, warning and error)", s)
+	}
+}
+
+// Problem is reported by a rule for a single document.
+type Problem struct {
+	Path    fieldpath.Path
+	Message string
+}
+
+// Rule checks documents for a specific problem.
+type Rule struct {
+	ID          string
+	Severity    Severity
+	Description string
+	// Check returns all problems in the document. All documents in the stream
+	// are passed as well, so rules can check references between documents.
+	Check func(doc *unstructured.Unstructured, documents []*unstructured.Unstructured) []Problem
+}
+
+// Finding is a problem found by a rule in a document.
+type Finding struct {
+	Rule     *Rule
+	Document *unstructured.Unstructured
+	Problem
+}
+
+func (f Finding) String() string {
+	location := k8syaml.ResourceName(f.Document)
+	if len(f.Path) > 0 {
+		location += ": " + f.Path.String()
+	}
+
+	return fmt.Sprintf("%s: %s (%s: %s)", location, f.Message, f.Rule.Severity, f.Rule.ID)
+}
+
+// Lint checks all documents using the specified rules. Rules listed in the
+// IgnoreAnnotation of a document are skipped for this document.
+func Lint(documents []*unstructured.Unstructured, rules []*Rule) []Finding {
+	var findings []Finding
+	for _, doc := range documents {
+		ignored := ignoredRules(doc)
+		for _, rule := range rules {
+			if ignored[rule.ID] {
+				continue
+			}
+
+			for _, problem := range rule.Check(doc, documents) {
+				findings = append(findings, Finding{Rule: rule, Document: doc, Problem: problem})
+			}
+		}
+	}
+
+	return findings
+}
+
+func ignoredRules(doc *unstructured.Unstructured) map[string]bool {
+	ignored := make(map[string]bool)
+	for _, id := range strings.Split(doc.GetAnnotations()[IgnoreAnnotation], ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ignored[id] = true
+		}
+	}
+
+	return ignored
+}
@@ -0,0 +1,163 @@
+package lint
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/frigus02/kyml/pkg/k8syaml"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var testManifests = `---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: good
+spec:
+  template:
+    metadata:
+      labels:
+        app: good
+    spec:
+      securityContext:
+        runAsNonRoot: true
+      containers:
+      - name: app
+        image: kyml/hello@sha256:2cbb95c7479634c53bc2be243554a98d6928c189360fa958d2c970974e7f131f
+        resources:
+          requests:
+            cpu: 100m
+          limits:
+            memory: 128Mi
+        livenessProbe:
+          httpGet:
+            port: 80
+        readinessProbe:
+          httpGet:
+            port: 80
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: bad
+  annotations:
+    kyml.io/lint-ignore: missing-probes, image-digest
+spec:
+  template:
+    spec:
+      hostNetwork: true
+      containers:
+      - name: app
+        image: kyml/hello
+        securityContext:
+          privileged: true
+          runAsUser: 0
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: good
+spec:
+  selector:
+    app: good
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: orphan
+spec:
+  selector:
+    app: orphan
+---
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: the-ingress
+spec:
+  defaultBackend:
+    service:
+      name: good
+      port:
+        number: 80
+  rules:
+  - http:
+      paths:
+      - path: /
+        pathType: Prefix
+        backend:
+          service:
+            name: missing
+            port:
+              number: 80
+`
+
+func mustDecode(t *testing.T, yaml string) []*unstructured.Unstructured {
+	docs, err := k8syaml.Decode(strings.NewReader(yaml))
+	if err != nil {
+		t.Fatalf("error decoding test manifests: %v", err)
+	}
+
+	return docs
+}
+
+func findingStrings(findings []Finding) []string {
+	var result []string
+	for _, f := range findings {
+		result = append(result, f.String())
+	}
+
+	return result
+}
+
+func TestLint(t *testing.T) {
+	want := []string{
+		`Deployment/bad: spec.template.spec.containers[0].resources: container "app" has no resource requests (warning: missing-resources)`,
+		`Deployment/bad: spec.template.spec.containers[0].resources: container "app" has no resource limits (warning: missing-resources)`,
+		`Deployment/bad: spec.template.spec.containers[0].securityContext.privileged: container "app" runs in privileged mode (error: privileged)`,
+		`Deployment/bad: spec.template.spec.hostNetwork: pod uses the host network (error: host-network)`,
+		`Deployment/bad: spec.template.spec.containers[0].securityContext: container "app" runs as root user (warning: run-as-root)`,
+		`Service/orphan: spec.selector: selector matches no workload (warning: service-selector)`,
+		`Ingress/the-ingress: spec.rules[0].http.paths[0].backend.service.name: service "missing" does not exist (warning: ingress-service)`,
+	}
+
+	got := Lint(mustDecode(t, testManifests), BuiltinRules())
+	if !reflect.DeepEqual(findingStrings(got), want) {
+		t.Errorf("Lint() = %q, want %q", findingStrings(got), want)
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    Severity
+		wantErr bool
+	}{
+		{
+			name:    "valid",
+			s:       "warning",
+			want:    SeverityWarning,
+			wantErr: false,
+		},
+		{
+			name:    "invalid",
+			s:       "fatal",
+			want:    0,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSeverity(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseSeverity() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseSeverity() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
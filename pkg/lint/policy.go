@@ -0,0 +1,178 @@
+package lint
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker/decls"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/ext"
+	"google.golang.org/protobuf/proto"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+type policyFile struct {
+	Rules []policyRule `json:"rules"`
+}
+
+type policyRule struct {
+	ID          string      `json:"id"`
+	Severity    string      `json:"severity"`
+	Description string      `json:"description"`
+	Match       policyMatch `json:"match"`
+	Expression  string      `json:"expression"`
+	Message     string      `json:"message"`
+}
+
+type policyMatch struct {
+	Resources  []policyResource  `json:"resources"`
+	Namespaces []string          `json:"namespaces"`
+	Labels     map[string]string `json:"labels"`
+}
+
+type policyResource struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+}
+
+// ParsePolicy parses a policy file and returns its rules. A policy file
+// contains a list of rules, each with a CEL expression, which is evaluated
+// against every matching document. The document is available in the
+// expression as the variable "object". If the expression evaluates to false,
+// the rule reports the configured message.
+//
+//	rules:
+//	- id: cost-center-label
+//	  severity: error
+//	  match:
+//	    resources:
+//	    - apiVersion: apps/v1
+//	      kind: Deployment
+//	    namespaces: [payments]
+//	  expression: has(object.metadata.labels) && 'cost-center' in object.metadata.labels
+//	  message: Deployments in payments need a cost-center label
+func ParsePolicy(data []byte) ([]*Rule, error) {
+	var policy policyFile
+	if err := yaml.UnmarshalStrict(data, &policy); err != nil {
+		return nil, err
+	}
+
+	env, err := cel.NewEnv(
+		cel.Declarations(decls.NewVar("object", decls.Dyn)),
+		ext.Strings(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []*Rule
+	for i, policyRule := range policy.Rules {
+		if policyRule.ID == "" {
+			return nil, fmt.Errorf("rule %d: id is required", i)
+		}
+
+		rule, err := compilePolicyRule(env, policyRule)
+		if err != nil {
+			return nil, fmt.Errorf("rule \"%s\": %v", policyRule.ID, err)
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+func compilePolicyRule(env *cel.Env, policyRule policyRule) (*Rule, error) {
+	severity := SeverityError
+	if policyRule.Severity != "" {
+		var err error
+		if severity, err = ParseSeverity(policyRule.Severity); err != nil {
+			return nil, err
+		}
+	}
+
+	if policyRule.Expression == "" {
+		return nil, fmt.Errorf("expression is required")
+	}
+
+	ast, issues := env.Compile(policyRule.Expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+
+	if resultType := ast.ResultType(); !proto.Equal(resultType, decls.Bool) && !proto.Equal(resultType, decls.Dyn) {
+		return nil, fmt.Errorf("expression must evaluate to a bool")
+	}
+
+	program, err := env.Program(ast)
+	if err != nil {
+		return nil, err
+	}
+
+	message := policyRule.Message
+	if message == "" {
+		message = fmt.Sprintf("expression %s evaluated to false", policyRule.Expression)
+	}
+
+	return &Rule{
+		ID:          policyRule.ID,
+		Severity:    severity,
+		Description: policyRule.Description,
+		Check: func(doc *unstructured.Unstructured, _ []*unstructured.Unstructured) []Problem {
+			if !policyRule.Match.matches(doc) {
+				return nil
+			}
+
+			result, _, err := program.Eval(map[string]interface{}{"object": doc.Object})
+			if err != nil {
+				return []Problem{{Message: fmt.Sprintf("failed to evaluate expression: %v", err)}}
+			}
+
+			passed, ok := result.(types.Bool)
+			if !ok {
+				return []Problem{{Message: fmt.Sprintf("expression evaluated to %v, expected a bool", result.Value())}}
+			}
+
+			if !passed {
+				return []Problem{{Message: message}}
+			}
+
+			return nil
+		},
+	}, nil
+}
+
+func (m policyMatch) matches(doc *unstructured.Unstructured) bool {
+	if len(m.Resources) > 0 {
+		found := false
+		for _, resource := range m.Resources {
+			if (resource.APIVersion == "" || resource.APIVersion == doc.GetAPIVersion()) &&
+				(resource.Kind == "" || resource.Kind == doc.GetKind()) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	if len(m.Namespaces) > 0 {
+		found := false
+		for _, namespace := range m.Namespaces {
+			if namespace == doc.GetNamespace() {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return matchesSelector(doc.GetLabels(), m.Labels)
+}
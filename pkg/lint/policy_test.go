@@ -0,0 +1,144 @@
+package lint
+
+import (
+	"reflect"
+	"testing"
+)
+
+var testPolicy = `rules:
+- id: cost-center-label
+  description: Deployments in namespace payments must have a cost-center label.
+  match:
+    resources:
+    - apiVersion: apps/v1
+      kind: Deployment
+    namespaces: [payments]
+  expression: has(object.metadata.labels) && 'cost-center' in object.metadata.labels
+  message: deployment has no cost-center label
+- id: min-replicas
+  severity: warning
+  match:
+    labels:
+      tier: frontend
+  expression: object.spec.replicas >= 2
+- id: image-registry
+  severity: info
+  match:
+    resources:
+    - kind: Deployment
+  expression: object.spec.template.spec.containers.all(c, c.image.startsWith('registry.example.com/'))
+  message: images must come from registry.example.com
+`
+
+var testPolicyManifests = `---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+  namespace: payments
+  labels:
+    tier: frontend
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: api
+        image: registry.example.com/api
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: worker
+  namespace: payments
+  labels:
+    cost-center: "42"
+spec:
+  template:
+    spec:
+      containers:
+      - name: worker
+        image: kyml/worker
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: shop
+  labels:
+    tier: frontend
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - name: web
+        image: registry.example.com/web
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: shop
+  labels:
+    tier: frontend
+spec: {}
+`
+
+func TestParsePolicy(t *testing.T) {
+	rules, err := ParsePolicy([]byte(testPolicy))
+	if err != nil {
+		t.Fatalf("ParsePolicy() error = %v", err)
+	}
+
+	want := []string{
+		`Deployment/payments/api: deployment has no cost-center label (error: cost-center-label)`,
+		`Deployment/payments/api: expression object.spec.replicas >= 2 evaluated to false (warning: min-replicas)`,
+		`Deployment/payments/worker: images must come from registry.example.com (info: image-registry)`,
+		`Service/shop/web: failed to evaluate expression: no such key: replicas (warning: min-replicas)`,
+	}
+
+	got := Lint(mustDecode(t, testPolicyManifests), rules)
+	if !reflect.DeepEqual(findingStrings(got), want) {
+		t.Errorf("Lint() = %q, want %q", findingStrings(got), want)
+	}
+}
+
+func TestParsePolicy_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+	}{
+		{
+			name:   "unknown field",
+			policy: "rules:\n- id: foo\n  expresion: 'true'\n",
+		},
+		{
+			name:   "missing id",
+			policy: "rules:\n- expression: 'true'\n",
+		},
+		{
+			name:   "missing expression",
+			policy: "rules:\n- id: foo\n",
+		},
+		{
+			name:   "invalid severity",
+			policy: "rules:\n- id: foo\n  severity: fatal\n  expression: 'true'\n",
+		},
+		{
+			name:   "syntax error",
+			policy: "rules:\n- id: foo\n  expression: object.spec.replicas >=\n",
+		},
+		{
+			name:   "not a bool",
+			policy: "rules:\n- id: foo\n  expression: '1 + 2'\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParsePolicy([]byte(tt.policy)); err == nil {
+				t.Errorf("ParsePolicy() expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/frigus02/kyml/pkg/k8syaml"
+)
+
+// WriteText writes the findings in a human readable format, one per line.
+func WriteText(out io.Writer, findings []Finding) error {
+	for _, finding := range findings {
+		if _, err := fmt.Fprintln(out, finding.String()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type jsonResource struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+}
+
+type jsonFinding struct {
+	RuleID   string       `json:"ruleId"`
+	Severity string       `json:"severity"`
+	Resource jsonResource `json:"resource"`
+	Path     string       `json:"path,omitempty"`
+	Message  string       `json:"message"`
+}
+
+// WriteJSON writes the findings as a JSON array.
+func WriteJSON(out io.Writer, findings []Finding) error {
+	result := make([]jsonFinding, 0, len(findings))
+	for _, finding := range findings {
+		result = append(result, jsonFinding{
+			RuleID:   finding.Rule.ID,
+			Severity: finding.Rule.Severity.String(),
+			Resource: jsonResource{
+				APIVersion: finding.Document.GetAPIVersion(),
+				Kind:       finding.Document.GetKind(),
+				Namespace:  finding.Document.GetNamespace(),
+				Name:       finding.Document.GetName(),
+			},
+			Path:    finding.Path.String(),
+			Message: finding.Message,
+		})
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Version        string      `json:"version,omitempty"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string                 `json:"id"`
+	ShortDescription     sarifMessage           `json:"shortDescription"`
+	DefaultConfiguration sarifRuleConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifRuleConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// WriteSARIF writes the findings in the Static Analysis Results Interchange
+// Format (SARIF) 2.1.0, which is understood by many code scanning tools. All
+// rules are included in the output, even if they didn't report any findings.
+//
+// See: https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+func WriteSARIF(out io.Writer, findings []Finding, rules []*Rule, version string) error {
+	driver := sarifDriver{
+		Name:           "kyml",
+		InformationURI: "https://github.com/frigus02/kyml",
+		Version:        version,
+		Rules:          make([]sarifRule, 0, len(rules)),
+	}
+
+	ruleIndex := make(map[string]int)
+	for i, rule := range rules {
+		ruleIndex[rule.ID] = i
+		driver.Rules = append(driver.Rules, sarifRule{
+			ID:                   rule.ID,
+			ShortDescription:     sarifMessage{Text: rule.Description},
+			DefaultConfiguration: sarifRuleConfiguration{Level: sarifLevel(rule.Severity)},
+		})
+	}
+
+	results := make([]sarifResult, 0, len(findings))
+	for _, finding := range findings {
+		name := k8syaml.ResourceName(finding.Document)
+		if len(finding.Path) > 0 {
+			name += ":" + finding.Path.String()
+		}
+
+		results = append(results, sarifResult{
+			RuleID:    finding.Rule.ID,
+			RuleIndex: ruleIndex[finding.Rule.ID],
+			Level:     sarifLevel(finding.Rule.Severity),
+			Message:   sarifMessage{Text: finding.Message},
+			Locations: []sarifLocation{{
+				LogicalLocations: []sarifLogicalLocation{{FullyQualifiedName: name, Kind: "resource"}},
+			}},
+		})
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	})
+}
+
+func sarifLevel(severity Severity) string {
+	switch severity {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return "note"
+	}
+}
@@ -0,0 +1,115 @@
+package lint
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/frigus02/kyml/pkg/fieldpath"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var testRule = &Rule{
+	ID:          "host-network",
+	Severity:    SeverityError,
+	Description: "Pods should not use the host network.",
+}
+
+var testFindings = []Finding{
+	{
+		Rule: testRule,
+		Document: &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"metadata":   map[string]interface{}{"name": "the-pod", "namespace": "the-namespace"},
+		}},
+		Problem: Problem{
+			Path:    fieldpath.Path{fieldpath.Field("spec"), fieldpath.Field("hostNetwork")},
+			Message: "pod uses the host network",
+		},
+	},
+}
+
+func TestWriteJSON(t *testing.T) {
+	want := `[
+  {
+    "ruleId": "host-network",
+    "severity": "error",
+    "resource": {
+      "apiVersion": "v1",
+      "kind": "Pod",
+      "namespace": "the-namespace",
+      "name": "the-pod"
+    },
+    "path": "spec.hostNetwork",
+    "message": "pod uses the host network"
+  }
+]
+`
+
+	out := &bytes.Buffer{}
+	if err := WriteJSON(out, testFindings); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+	if got := out.String(); got != want {
+		t.Errorf("WriteJSON() = %v, want %v", got, want)
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	want := `{
+  "version": "2.1.0",
+  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+  "runs": [
+    {
+      "tool": {
+        "driver": {
+          "name": "kyml",
+          "informationUri": "https://github.com/frigus02/kyml",
+          "version": "dev",
+          "rules": [
+            {
+              "id": "host-network",
+              "shortDescription": {
+                "text": "Pods should not use the host network."
+              },
+              "defaultConfiguration": {
+                "level": "error"
+              }
+            }
+          ]
+        }
+      },
+      "results": [
+        {
+          "ruleId": "host-network",
+          "ruleIndex": 0,
+          "level": "error",
+          "message": {
+            "text": "pod uses the host network"
+          },
+          "locations": [
+            {
+              "logicalLocations": [
+                {
+                  "fullyQualifiedName": "Pod/the-namespace/the-pod:spec.hostNetwork",
+                  "kind": "resource"
+                }
+              ]
+            }
+          ]
+        }
+      ]
+    }
+  ]
+}
+`
+
+	out := &bytes.Buffer{}
+	if err := WriteSARIF(out, testFindings, []*Rule{testRule}, "dev"); err != nil {
+		t.Fatalf("WriteSARIF() error = %v", err)
+	}
+	if got := out.String(); got != want {
+		t.Errorf("WriteSARIF() = %v, want %v", got, want)
+	}
+}
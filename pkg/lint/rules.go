@@ -0,0 +1,349 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/frigus02/kyml/pkg/fieldpath"
+	"github.com/frigus02/kyml/pkg/k8syaml"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// BuiltinRules returns the best-practice rules, which are built into kyml.
+func BuiltinRules() []*Rule {
+	return []*Rule{
+		{
+			ID:          "missing-resources",
+			Severity:    SeverityWarning,
+			Description: "Containers should specify resource requests and limits.",
+			Check:       checkMissingResources,
+		},
+		{
+			ID:          "missing-probes",
+			Severity:    SeverityWarning,
+			Description: "Containers of long running workloads should specify liveness and readiness probes.",
+			Check:       checkMissingProbes,
+		},
+		{
+			ID:          "privileged",
+			Severity:    SeverityError,
+			Description: "Containers should not run in privileged mode.",
+			Check:       checkPrivileged,
+		},
+		{
+			ID:          "host-network",
+			Severity:    SeverityError,
+			Description: "Pods should not use the host network.",
+			Check:       checkHostNetwork,
+		},
+		{
+			ID:          "run-as-root",
+			Severity:    SeverityWarning,
+			Description: "Containers should not run as root.",
+			Check:       checkRunAsRoot,
+		},
+		{
+			ID:          "image-digest",
+			Severity:    SeverityInfo,
+			Description: "Images should be referenced by digest, e.g. using \"kyml resolve\".",
+			Check:       checkImageDigest,
+		},
+		{
+			ID:          "service-selector",
+			Severity:    SeverityWarning,
+			Description: "Service selectors should match the pod template of a workload in the same stream.",
+			Check:       checkServiceSelector,
+		},
+		{
+			ID:          "ingress-service",
+			Severity:    SeverityWarning,
+			Description: "Ingresses should only point to Services in the same stream.",
+			Check:       checkIngressService,
+		},
+	}
+}
+
+type container struct {
+	obj  map[string]interface{}
+	name string
+	path fieldpath.Path
+}
+
+func pathOf(fields ...string) fieldpath.Path {
+	var path fieldpath.Path
+	for _, field := range fields {
+		path = path.Child(fieldpath.Field(field))
+	}
+
+	return path
+}
+
+// podSpecPath returns the path to the pod spec in workloads and pods.
+func podSpecPath(doc *unstructured.Unstructured) []string {
+	gvk := doc.GroupVersionKind()
+	if gvk.Group == "" && gvk.Kind == "Pod" {
+		return []string{"spec"}
+	}
+
+	return k8syaml.PathToPodSpec(gvk)
+}
+
+// podLabels returns the labels of the pods created by the workload or pod.
+func podLabels(doc *unstructured.Unstructured) (map[string]string, bool) {
+	path := podSpecPath(doc)
+	if path == nil {
+		return nil, false
+	}
+
+	labelsPath := append(append([]string{}, path[:len(path)-1]...), "metadata", "labels")
+	labels, _, _ := unstructured.NestedStringMap(doc.Object, labelsPath...)
+	return labels, true
+}
+
+func containers(doc *unstructured.Unstructured, includeInit bool) []container {
+	path := podSpecPath(doc)
+	if path == nil {
+		return nil
+	}
+
+	lists := []string{"containers"}
+	if includeInit {
+		lists = append(lists, "initContainers")
+	}
+
+	var result []container
+	for _, list := range lists {
+		items, _, _ := unstructured.NestedSlice(doc.Object, append(append([]string{}, path...), list)...)
+		for i, item := range items {
+			obj, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			name, _ := obj["name"].(string)
+			result = append(result, container{
+				obj:  obj,
+				name: name,
+				path: pathOf(append(append([]string{}, path...), list)...).Child(fieldpath.Index(i)),
+			})
+		}
+	}
+
+	return result
+}
+
+func checkMissingResources(doc *unstructured.Unstructured, _ []*unstructured.Unstructured) []Problem {
+	var problems []Problem
+	for _, c := range containers(doc, false) {
+		for _, field := range []string{"requests", "limits"} {
+			if values, _, _ := unstructured.NestedMap(c.obj, "resources", field); len(values) == 0 {
+				problems = append(problems, Problem{
+					Path:    c.path.Child(fieldpath.Field("resources")),
+					Message: fmt.Sprintf("container %q has no resource %s", c.name, field),
+				})
+			}
+		}
+	}
+
+	return problems
+}
+
+func checkMissingProbes(doc *unstructured.Unstructured, _ []*unstructured.Unstructured) []Problem {
+	if gvk := doc.GroupVersionKind(); gvk.Group == "batch" || gvk.Kind == "Pod" {
+		return nil
+	}
+
+	var problems []Problem
+	for _, c := range containers(doc, false) {
+		for _, probe := range []string{"livenessProbe", "readinessProbe"} {
+			if _, ok := c.obj[probe]; !ok {
+				problems = append(problems, Problem{
+					Path:    c.path,
+					Message: fmt.Sprintf("container %q has no %s", c.name, probe),
+				})
+			}
+		}
+	}
+
+	return problems
+}
+
+func checkPrivileged(doc *unstructured.Unstructured, _ []*unstructured.Unstructured) []Problem {
+	var problems []Problem
+	for _, c := range containers(doc, true) {
+		if privileged, _, _ := unstructured.NestedBool(c.obj, "securityContext", "privileged"); privileged {
+			problems = append(problems, Problem{
+				Path:    c.path.Child(fieldpath.Field("securityContext")).Child(fieldpath.Field("privileged")),
+				Message: fmt.Sprintf("container %q runs in privileged mode", c.name),
+			})
+		}
+	}
+
+	return problems
+}
+
+func checkHostNetwork(doc *unstructured.Unstructured, _ []*unstructured.Unstructured) []Problem {
+	path := podSpecPath(doc)
+	if path == nil {
+		return nil
+	}
+
+	hostNetworkPath := append(append([]string{}, path...), "hostNetwork")
+	if hostNetwork, _, _ := unstructured.NestedBool(doc.Object, hostNetworkPath...); hostNetwork {
+		return []Problem{{Path: pathOf(hostNetworkPath...), Message: "pod uses the host network"}}
+	}
+
+	return nil
+}
+
+func checkRunAsRoot(doc *unstructured.Unstructured, _ []*unstructured.Unstructured) []Problem {
+	path := podSpecPath(doc)
+	if path == nil {
+		return nil
+	}
+
+	podSecurityContext, _, _ := unstructured.NestedMap(doc.Object, append(append([]string{}, path...), "securityContext")...)
+
+	var problems []Problem
+	for _, c := range containers(doc, true) {
+		securityContext, _, _ := unstructured.NestedMap(c.obj, "securityContext")
+
+		runAsUser, hasRunAsUser := securityContext["runAsUser"].(int64)
+		if !hasRunAsUser {
+			runAsUser, hasRunAsUser = podSecurityContext["runAsUser"].(int64)
+		}
+
+		runAsNonRoot, hasRunAsNonRoot := securityContext["runAsNonRoot"].(bool)
+		if !hasRunAsNonRoot {
+			runAsNonRoot = podSecurityContext["runAsNonRoot"] == true
+		}
+
+		if hasRunAsUser && runAsUser == 0 {
+			problems = append(problems, Problem{
+				Path:    c.path.Child(fieldpath.Field("securityContext")),
+				Message: fmt.Sprintf("container %q runs as root user", c.name),
+			})
+		} else if !hasRunAsUser && !runAsNonRoot {
+			problems = append(problems, Problem{
+				Path:    c.path.Child(fieldpath.Field("securityContext")),
+				Message: fmt.Sprintf("container %q may run as root user, set runAsNonRoot or runAsUser", c.name),
+			})
+		}
+	}
+
+	return problems
+}
+
+func checkImageDigest(doc *unstructured.Unstructured, _ []*unstructured.Unstructured) []Problem {
+	var problems []Problem
+	for _, c := range containers(doc, true) {
+		image, ok := c.obj["image"].(string)
+		if ok && !strings.Contains(image, "@") {
+			problems = append(problems, Problem{
+				Path:    c.path.Child(fieldpath.Field("image")),
+				Message: fmt.Sprintf("image %s is not referenced by digest", image),
+			})
+		}
+	}
+
+	return problems
+}
+
+func checkServiceSelector(doc *unstructured.Unstructured, documents []*unstructured.Unstructured) []Problem {
+	if gvk := doc.GroupVersionKind(); gvk.Group != "" || gvk.Kind != "Service" {
+		return nil
+	}
+
+	selector, _, _ := unstructured.NestedStringMap(doc.Object, "spec", "selector")
+	if len(selector) == 0 {
+		return nil
+	}
+
+	for _, other := range documents {
+		if other.GetNamespace() != doc.GetNamespace() {
+			continue
+		}
+
+		labels, ok := podLabels(other)
+		if ok && matchesSelector(labels, selector) {
+			return nil
+		}
+	}
+
+	return []Problem{{Path: pathOf("spec", "selector"), Message: "selector matches no workload"}}
+}
+
+func matchesSelector(labels, selector map[string]string) bool {
+	for key, value := range selector {
+		if labels[key] != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+func checkIngressService(doc *unstructured.Unstructured, documents []*unstructured.Unstructured) []Problem {
+	if doc.GetKind() != "Ingress" {
+		return nil
+	}
+
+	var problems []Problem
+	checkBackend := func(backend interface{}, path fieldpath.Path) {
+		backendObj, ok := backend.(map[string]interface{})
+		if !ok {
+			return
+		}
+
+		name, _, _ := unstructured.NestedString(backendObj, "service", "name")
+		namePath := path.Child(fieldpath.Field("service")).Child(fieldpath.Field("name"))
+		if legacyName, ok := backendObj["serviceName"].(string); ok {
+			name = legacyName
+			namePath = path.Child(fieldpath.Field("serviceName"))
+		}
+
+		if name != "" && !hasService(documents, doc.GetNamespace(), name) {
+			problems = append(problems, Problem{Path: namePath, Message: fmt.Sprintf("service %q does not exist", name)})
+		}
+	}
+
+	for _, field := range []string{"defaultBackend", "backend"} {
+		if backend, found, _ := unstructured.NestedFieldNoCopy(doc.Object, "spec", field); found {
+			checkBackend(backend, pathOf("spec", field))
+		}
+	}
+
+	rules, _, _ := unstructured.NestedSlice(doc.Object, "spec", "rules")
+	for i, rule := range rules {
+		rule, ok := rule.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		paths, _, _ := unstructured.NestedSlice(rule, "http", "paths")
+		for j, path := range paths {
+			if path, ok := path.(map[string]interface{}); ok {
+				checkBackend(path["backend"], pathOf("spec", "rules").
+					Child(fieldpath.Index(i)).
+					Child(fieldpath.Field("http")).
+					Child(fieldpath.Field("paths")).
+					Child(fieldpath.Index(j)).
+					Child(fieldpath.Field("backend")))
+			}
+		}
+	}
+
+	return problems
+}
+
+func hasService(documents []*unstructured.Unstructured, namespace, name string) bool {
+	for _, doc := range documents {
+		gvk := doc.GroupVersionKind()
+		if gvk.Group == "" && gvk.Kind == "Service" && doc.GetNamespace() == namespace && doc.GetName() == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,410 @@
+package migrate
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// convertWorkloadToAppsV1 converts Deployments, DaemonSets, ReplicaSets and
+// StatefulSets from extensions/v1beta1, apps/v1beta1 and apps/v1beta2 to
+// apps/v1.
+func convertWorkloadToAppsV1(obj map[string]interface{}) []string {
+	var messages []string
+
+	// The selector is required in apps/v1. Older versions defaulted it to the
+	// labels of the pod template.
+	if _, found, _ := unstructured.NestedFieldNoCopy(obj, "spec", "selector"); !found {
+		labels, found, _ := unstructured.NestedStringMap(obj, "spec", "template", "metadata", "labels")
+		if found && len(labels) > 0 {
+			_ = unstructured.SetNestedStringMap(obj, labels, "spec", "selector", "matchLabels")
+		} else {
+			messages = append(messages, "spec.selector is required, but spec.template.metadata.labels is empty")
+		}
+	}
+
+	for _, field := range []string{"rollbackTo", "templateGeneration"} {
+		if _, found, _ := unstructured.NestedFieldNoCopy(obj, "spec", field); found {
+			unstructured.RemoveNestedField(obj, "spec", field)
+			messages = append(messages, fmt.Sprintf("spec.%s was removed, because it doesn't exist in apps/v1", field))
+		}
+	}
+
+	kind, _, _ := unstructured.NestedString(obj, "kind")
+	apiVersion, _, _ := unstructured.NestedString(obj, "apiVersion")
+	if _, found, _ := unstructured.NestedFieldNoCopy(obj, "spec", "updateStrategy"); !found &&
+		((kind == "DaemonSet" && apiVersion == "extensions/v1beta1") || (kind == "StatefulSet" && apiVersion == "apps/v1beta1")) {
+		messages = append(messages, "spec.updateStrategy defaults to RollingUpdate in apps/v1 instead of OnDelete")
+	}
+
+	return messages
+}
+
+// convertIngressToV1 converts Ingresses from extensions/v1beta1 and
+// networking.k8s.io/v1beta1 to networking.k8s.io/v1.
+func convertIngressToV1(obj map[string]interface{}) []string {
+	var messages []string
+
+	if backend, found, _ := unstructured.NestedMap(obj, "spec", "backend"); found {
+		unstructured.RemoveNestedField(obj, "spec", "backend")
+		messages = append(messages, convertIngressBackend(backend, "spec.backend")...)
+		_ = unstructured.SetNestedMap(obj, backend, "spec", "defaultBackend")
+	}
+
+	rules, _, _ := unstructured.NestedSlice(obj, "spec", "rules")
+	for i, rule := range rules {
+		rule, ok := rule.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		paths, _, _ := unstructured.NestedSlice(rule, "http", "paths")
+		for j, path := range paths {
+			path, ok := path.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			if backend, ok := path["backend"].(map[string]interface{}); ok {
+				messages = append(messages, convertIngressBackend(backend, fmt.Sprintf("spec.rules[%d].http.paths[%d].backend", i, j))...)
+			}
+
+			if _, ok := path["pathType"]; !ok {
+				path["pathType"] = "ImplementationSpecific"
+			}
+		}
+
+		if paths != nil {
+			_ = unstructured.SetNestedSlice(rule, paths, "http", "paths")
+		}
+	}
+
+	if rules != nil {
+		_ = unstructured.SetNestedSlice(obj, rules, "spec", "rules")
+	}
+
+	if class, found, _ := unstructured.NestedString(obj, "metadata", "annotations", "kubernetes.io/ingress.class"); found {
+		messages = append(messages, fmt.Sprintf("the annotation kubernetes.io/ingress.class is deprecated, consider using spec.ingressClassName: %s", class))
+	}
+
+	return messages
+}
+
+// convertIngressBackend converts the specified backend in place. It returns
+// messages about fields, which could not be converted.
+func convertIngressBackend(backend map[string]interface{}, field string) []string {
+	serviceName, hasName := backend["serviceName"]
+	servicePort, hasPort := backend["servicePort"]
+	if !hasName && !hasPort {
+		return nil
+	}
+
+	delete(backend, "serviceName")
+	delete(backend, "servicePort")
+
+	port := make(map[string]interface{})
+	switch servicePort := servicePort.(type) {
+	case string:
+		port["name"] = servicePort
+	case nil:
+	default:
+		port["number"] = servicePort
+	}
+
+	service := map[string]interface{}{"port": port}
+	backend["service"] = service
+	if !hasName {
+		return []string{fmt.Sprintf("%s.serviceName is missing, but service.name is required in networking.k8s.io/v1", field)}
+	}
+
+	service["name"] = serviceName
+	return nil
+}
+
+// convertCRDToV1 converts CustomResourceDefinitions from
+// apiextensions.k8s.io/v1beta1 to apiextensions.k8s.io/v1.
+func convertCRDToV1(obj map[string]interface{}) []string {
+	var messages []string
+
+	spec, found, _ := unstructured.NestedMap(obj, "spec")
+	if !found {
+		return []string{"spec is missing"}
+	}
+
+	versions, _, _ := unstructured.NestedSlice(spec, "versions")
+	if version, ok := spec["version"].(string); ok {
+		if len(versions) == 0 {
+			versions = []interface{}{
+				map[string]interface{}{"name": version, "served": true, "storage": true},
+			}
+		}
+		delete(spec, "version")
+	}
+
+	// In v1beta1 schema, subresources and additional printer columns could be
+	// specified for all versions at once. In v1 they are per version.
+	validation, _, _ := unstructured.NestedMap(spec, "validation", "openAPIV3Schema")
+	subresources, _, _ := unstructured.NestedMap(spec, "subresources")
+	columns, _, _ := unstructured.NestedSlice(spec, "additionalPrinterColumns")
+	delete(spec, "validation")
+	delete(spec, "subresources")
+	delete(spec, "additionalPrinterColumns")
+
+	preserveUnknownFields, hasPreserveUnknownFields := spec["preserveUnknownFields"].(bool)
+	if !hasPreserveUnknownFields {
+		// v1beta1 defaulted preserveUnknownFields to true.
+		preserveUnknownFields = true
+	}
+	delete(spec, "preserveUnknownFields")
+
+	for _, version := range versions {
+		version, ok := version.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		name, _ := version["name"].(string)
+		if _, ok := version["schema"]; !ok {
+			if validation != nil {
+				version["schema"] = map[string]interface{}{
+					"openAPIV3Schema": runtime.DeepCopyJSONValue(validation),
+				}
+			} else {
+				version["schema"] = map[string]interface{}{
+					"openAPIV3Schema": map[string]interface{}{
+						"type":                                 "object",
+						"x-kubernetes-preserve-unknown-fields": true,
+					},
+				}
+				messages = append(messages, fmt.Sprintf("version %s has no schema, which is required in v1; added a schema, which accepts any fields", name))
+			}
+		}
+
+		if preserveUnknownFields {
+			if schema, found, _ := unstructured.NestedMap(version, "schema", "openAPIV3Schema"); found {
+				if _, ok := schema["x-kubernetes-preserve-unknown-fields"]; !ok {
+					schema["x-kubernetes-preserve-unknown-fields"] = true
+					version["schema"] = map[string]interface{}{"openAPIV3Schema": schema}
+					messages = append(messages, fmt.Sprintf("version %s preserved unknown fields; added x-kubernetes-preserve-unknown-fields to its schema, consider specifying all fields instead", name))
+				}
+			}
+		}
+
+		if _, ok := version["subresources"]; !ok && subresources != nil {
+			version["subresources"] = runtime.DeepCopyJSONValue(subresources)
+		}
+
+		if _, ok := version["additionalPrinterColumns"]; !ok && columns != nil {
+			version["additionalPrinterColumns"] = runtime.DeepCopyJSONValue(columns)
+		}
+
+		if versionColumns, ok := version["additionalPrinterColumns"].([]interface{}); ok {
+			for _, column := range versionColumns {
+				if column, ok := column.(map[string]interface{}); ok {
+					if jsonPath, ok := column["JSONPath"]; ok {
+						column["jsonPath"] = jsonPath
+						delete(column, "JSONPath")
+					}
+				}
+			}
+		}
+	}
+
+	spec["versions"] = versions
+
+	if clientConfig, found, _ := unstructured.NestedMap(spec, "conversion", "webhookClientConfig"); found {
+		unstructured.RemoveNestedField(spec, "conversion", "webhookClientConfig")
+		_ = unstructured.SetNestedMap(spec, clientConfig, "conversion", "webhook", "clientConfig")
+	}
+
+	if reviewVersions, found, _ := unstructured.NestedStringSlice(spec, "conversion", "conversionReviewVersions"); found {
+		unstructured.RemoveNestedField(spec, "conversion", "conversionReviewVersions")
+		_ = unstructured.SetNestedStringSlice(spec, reviewVersions, "conversion", "webhook", "conversionReviewVersions")
+	} else if _, found, _ := unstructured.NestedFieldNoCopy(spec, "conversion", "webhook"); found {
+		_ = unstructured.SetNestedStringSlice(spec, []string{"v1beta1"}, "conversion", "webhook", "conversionReviewVersions")
+		messages = append(messages, "spec.conversion.webhook.conversionReviewVersions is required; set to v1beta1, which was the default")
+	}
+
+	obj["spec"] = spec
+	return messages
+}
+
+// convertWebhookConfigurationToV1 converts Mutating- and
+// ValidatingWebhookConfigurations from admissionregistration.k8s.io/v1beta1 to
+// admissionregistration.k8s.io/v1.
+func convertWebhookConfigurationToV1(obj map[string]interface{}) []string {
+	var messages []string
+
+	webhooks, _, _ := unstructured.NestedSlice(obj, "webhooks")
+	for i, webhook := range webhooks {
+		webhook, ok := webhook.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if _, ok := webhook["admissionReviewVersions"]; !ok {
+			webhook["admissionReviewVersions"] = []interface{}{"v1beta1"}
+			messages = append(messages, fmt.Sprintf("webhooks[%d].admissionReviewVersions is required; set to v1beta1, which was the default", i))
+		}
+
+		switch webhook["sideEffects"] {
+		case "None", "NoneOnDryRun":
+		case nil:
+			messages = append(messages, fmt.Sprintf("webhooks[%d].sideEffects is required and must be None or NoneOnDryRun", i))
+		default:
+			messages = append(messages, fmt.Sprintf("webhooks[%d].sideEffects must be None or NoneOnDryRun", i))
+		}
+	}
+
+	if webhooks != nil {
+		_ = unstructured.SetNestedSlice(obj, webhooks, "webhooks")
+	}
+
+	return messages
+}
+
+// convertCSRToV1 converts CertificateSigningRequests from
+// certificates.k8s.io/v1beta1 to certificates.k8s.io/v1.
+func convertCSRToV1(obj map[string]interface{}) []string {
+	if _, found, _ := unstructured.NestedString(obj, "spec", "signerName"); !found {
+		return []string{"spec.signerName is required"}
+	}
+
+	return nil
+}
+
+// convertEndpointSliceToV1 converts EndpointSlices from
+// discovery.k8s.io/v1beta1 to discovery.k8s.io/v1.
+func convertEndpointSliceToV1(obj map[string]interface{}) []string {
+	endpoints, found, _ := unstructured.NestedSlice(obj, "endpoints")
+	if !found {
+		return nil
+	}
+
+	for _, endpoint := range endpoints {
+		endpoint, ok := endpoint.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		topology, ok := endpoint["topology"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		delete(endpoint, "topology")
+		if nodeName, ok := topology["kubernetes.io/hostname"]; ok {
+			endpoint["nodeName"] = nodeName
+			delete(topology, "kubernetes.io/hostname")
+		}
+		if zone, ok := topology["topology.kubernetes.io/zone"]; ok {
+			endpoint["zone"] = zone
+			delete(topology, "topology.kubernetes.io/zone")
+		}
+		if len(topology) > 0 {
+			endpoint["deprecatedTopology"] = topology
+		}
+	}
+
+	_ = unstructured.SetNestedSlice(obj, endpoints, "endpoints")
+	return nil
+}
+
+// convertHPAV2beta1ToV2 converts HorizontalPodAutoscalers from
+// autoscaling/v2beta1 to autoscaling/v2.
+func convertHPAV2beta1ToV2(obj map[string]interface{}) []string {
+	var messages []string
+
+	metrics, found, _ := unstructured.NestedSlice(obj, "spec", "metrics")
+	if !found {
+		return nil
+	}
+
+	for i, metric := range metrics {
+		metric, ok := metric.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		metricType, _ := metric["type"].(string)
+		source, ok := metric[lowerFirst(metricType)].(map[string]interface{})
+		if !ok {
+			messages = append(messages, fmt.Sprintf("spec.metrics[%d] has an unknown type %q", i, metricType))
+			continue
+		}
+
+		newSource := make(map[string]interface{})
+		if metricType == "Resource" {
+			newSource["name"] = source["name"]
+		} else {
+			metricIdentifier := map[string]interface{}{"name": source["metricName"]}
+			if selector, ok := source["selector"]; ok {
+				metricIdentifier["selector"] = selector
+			}
+			if selector, ok := source["metricSelector"]; ok {
+				metricIdentifier["selector"] = selector
+			}
+			newSource["metric"] = metricIdentifier
+		}
+
+		if metricType == "Object" {
+			newSource["describedObject"] = source["target"]
+		}
+
+		target := make(map[string]interface{})
+		if value, ok := source["targetAverageUtilization"]; ok {
+			target["type"] = "Utilization"
+			target["averageUtilization"] = value
+		} else if value, ok := source["targetAverageValue"]; ok {
+			target["type"] = "AverageValue"
+			target["averageValue"] = value
+		} else if value, ok := source["averageValue"]; ok {
+			target["type"] = "AverageValue"
+			target["averageValue"] = value
+		} else if value, ok := source["targetValue"]; ok {
+			target["type"] = "Value"
+			target["value"] = value
+		} else {
+			messages = append(messages, fmt.Sprintf("spec.metrics[%d] has no target", i))
+		}
+		newSource["target"] = target
+
+		metric[lowerFirst(metricType)] = newSource
+	}
+
+	_ = unstructured.SetNestedSlice(obj, metrics, "spec", "metrics")
+	return messages
+}
+
+// convertPDBToV1 converts PodDisruptionBudgets from policy/v1beta1 to
+// policy/v1.
+func convertPDBToV1(obj map[string]interface{}) []string {
+	selector, found, _ := unstructured.NestedMap(obj, "spec", "selector")
+	if !found || len(selector) == 0 {
+		return []string{"an empty spec.selector selects all pods in the namespace in policy/v1, but no pods in policy/v1beta1"}
+	}
+
+	return nil
+}
+
+// convertPriorityLevelConfigurationToV1 converts PriorityLevelConfigurations
+// from flowcontrol.apiserver.k8s.io/v1beta1 and v1beta2 to v1.
+func convertPriorityLevelConfigurationToV1(obj map[string]interface{}) []string {
+	shares, found, _ := unstructured.NestedFieldNoCopy(obj, "spec", "limited", "assuredConcurrencyShares")
+	if found {
+		unstructured.RemoveNestedField(obj, "spec", "limited", "assuredConcurrencyShares")
+		_ = unstructured.SetNestedField(obj, shares, "spec", "limited", "nominalConcurrencyShares")
+	}
+
+	return nil
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+
+	return strings.ToLower(s[:1]) + s[1:]
+}
@@ -0,0 +1,98 @@
+package migrate
+
+import (
+	"fmt"
+
+	"github.com/frigus02/kyml/pkg/deprecation"
+	"github.com/frigus02/kyml/pkg/kubeversion"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Note describes something in a document, which could not be converted
+// automatically and needs a manual review.
+type Note struct {
+	Document *unstructured.Unstructured
+	Message  string
+}
+
+// Migrate rewrites the specified document from an API version, which is
+// removed in the target Kubernetes version, to its replacement. If
+// includeDeprecated is true, API versions which are only deprecated are
+// migrated as well. Field conversions between the API versions are applied.
+// Anything, which can't be converted automatically, is reported as a note.
+//
+// The document is modified in place. Migrate returns true if the apiVersion or
+// kind of the document changed.
+func Migrate(doc *unstructured.Unstructured, target kubeversion.Version, includeDeprecated bool) (bool, []Note) {
+	var notes []Note
+	addNote := func(format string, a ...interface{}) {
+		notes = append(notes, Note{Document: doc, Message: fmt.Sprintf(format, a...)})
+	}
+
+	migrated := false
+	for {
+		gvk := doc.GroupVersionKind()
+		api, ok := deprecation.Lookup(gvk)
+		if !ok || !(api.IsRemoved(target) || (includeDeprecated && api.IsDeprecated(target))) {
+			break
+		}
+
+		if api.Replacement == nil {
+			addNote("%s %s has no replacement and cannot be migrated automatically", gvk.GroupVersion(), gvk.Kind)
+			break
+		}
+
+		convert, ok := converters[gvk]
+		if !ok {
+			if api.Replacement.Kind != gvk.Kind {
+				addNote("%s %s cannot be migrated to %s %s automatically", gvk.GroupVersion(), gvk.Kind, api.Replacement.GroupVersion(), api.Replacement.Kind)
+				break
+			}
+
+			convert = sameSchema
+		}
+
+		for _, message := range convert(doc.Object) {
+			addNote("%s", message)
+		}
+
+		doc.SetGroupVersionKind(*api.Replacement)
+		migrated = true
+	}
+
+	return migrated, notes
+}
+
+// converter converts the content of a document to the replacement API
+// version. It returns messages about fields, which could not be converted.
+type converter func(obj map[string]interface{}) []string
+
+var converters = map[schema.GroupVersionKind]converter{
+	{Group: "extensions", Version: "v1beta1", Kind: "DaemonSet"}:                                        convertWorkloadToAppsV1,
+	{Group: "extensions", Version: "v1beta1", Kind: "Deployment"}:                                       convertWorkloadToAppsV1,
+	{Group: "extensions", Version: "v1beta1", Kind: "ReplicaSet"}:                                       convertWorkloadToAppsV1,
+	{Group: "apps", Version: "v1beta1", Kind: "Deployment"}:                                             convertWorkloadToAppsV1,
+	{Group: "apps", Version: "v1beta1", Kind: "StatefulSet"}:                                            convertWorkloadToAppsV1,
+	{Group: "apps", Version: "v1beta2", Kind: "DaemonSet"}:                                              convertWorkloadToAppsV1,
+	{Group: "apps", Version: "v1beta2", Kind: "Deployment"}:                                             convertWorkloadToAppsV1,
+	{Group: "apps", Version: "v1beta2", Kind: "ReplicaSet"}:                                             convertWorkloadToAppsV1,
+	{Group: "apps", Version: "v1beta2", Kind: "StatefulSet"}:                                            convertWorkloadToAppsV1,
+	{Group: "extensions", Version: "v1beta1", Kind: "Ingress"}:                                          convertIngressToV1,
+	{Group: "networking.k8s.io", Version: "v1beta1", Kind: "Ingress"}:                                   convertIngressToV1,
+	{Group: "apiextensions.k8s.io", Version: "v1beta1", Kind: "CustomResourceDefinition"}:               convertCRDToV1,
+	{Group: "admissionregistration.k8s.io", Version: "v1beta1", Kind: "MutatingWebhookConfiguration"}:   convertWebhookConfigurationToV1,
+	{Group: "admissionregistration.k8s.io", Version: "v1beta1", Kind: "ValidatingWebhookConfiguration"}: convertWebhookConfigurationToV1,
+	{Group: "certificates.k8s.io", Version: "v1beta1", Kind: "CertificateSigningRequest"}:               convertCSRToV1,
+	{Group: "discovery.k8s.io", Version: "v1beta1", Kind: "EndpointSlice"}:                              convertEndpointSliceToV1,
+	{Group: "autoscaling", Version: "v2beta1", Kind: "HorizontalPodAutoscaler"}:                         convertHPAV2beta1ToV2,
+	{Group: "policy", Version: "v1beta1", Kind: "PodDisruptionBudget"}:                                  convertPDBToV1,
+	{Group: "flowcontrol.apiserver.k8s.io", Version: "v1beta1", Kind: "PriorityLevelConfiguration"}:     convertPriorityLevelConfigurationToV1,
+	{Group: "flowcontrol.apiserver.k8s.io", Version: "v1beta2", Kind: "PriorityLevelConfiguration"}:     convertPriorityLevelConfigurationToV1,
+}
+
+// sameSchema is used for API versions, which only changed their apiVersion.
+func sameSchema(obj map[string]interface{}) []string {
+	return nil
+}
@@ -0,0 +1,278 @@
+package migrate
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/frigus02/kyml/pkg/k8syaml"
+	"github.com/frigus02/kyml/pkg/kubeversion"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var testManifestIngress = `---
+apiVersion: extensions/v1beta1
+kind: Ingress
+metadata:
+  annotations:
+    kubernetes.io/ingress.class: nginx
+  name: the-ingress
+spec:
+  backend:
+    serviceName: the-default-service
+    servicePort: http
+  rules:
+  - http:
+      paths:
+      - backend:
+          serviceName: the-service
+          servicePort: 80
+        path: /the-path
+`
+
+var testManifestIngressMigrated = `---
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  annotations:
+    kubernetes.io/ingress.class: nginx
+  name: the-ingress
+spec:
+  defaultBackend:
+    service:
+      name: the-default-service
+      port:
+        name: http
+  rules:
+  - http:
+      paths:
+      - backend:
+          service:
+            name: the-service
+            port:
+              number: 80
+        path: /the-path
+        pathType: ImplementationSpecific
+`
+
+var testManifestIngressWithoutServiceName = `---
+apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: the-ingress
+spec:
+  rules:
+  - http:
+      paths:
+      - backend:
+          servicePort: 80
+        path: /the-path
+`
+
+var testManifestIngressWithoutServiceNameMigrated = `---
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: the-ingress
+spec:
+  rules:
+  - http:
+      paths:
+      - backend:
+          service:
+            port:
+              number: 80
+        path: /the-path
+        pathType: ImplementationSpecific
+`
+
+var testManifestCronJob = `---
+apiVersion: batch/v1beta1
+kind: CronJob
+metadata:
+  name: backup
+spec:
+  schedule: '@daily'
+`
+
+var testManifestCronJobMigrated = `---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: backup
+spec:
+  schedule: '@daily'
+`
+
+var testManifestCRD = `---
+apiVersion: apiextensions.k8s.io/v1beta1
+kind: CustomResourceDefinition
+metadata:
+  name: crontabs.stable.example.com
+spec:
+  additionalPrinterColumns:
+  - JSONPath: .spec.cronSpec
+    name: Spec
+    type: string
+  group: stable.example.com
+  names:
+    kind: CronTab
+    plural: crontabs
+  preserveUnknownFields: false
+  scope: Namespaced
+  validation:
+    openAPIV3Schema:
+      properties:
+        spec:
+          properties:
+            cronSpec:
+              type: string
+          type: object
+      type: object
+  version: v1
+`
+
+var testManifestCRDMigrated = `---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: crontabs.stable.example.com
+spec:
+  group: stable.example.com
+  names:
+    kind: CronTab
+    plural: crontabs
+  scope: Namespaced
+  versions:
+  - additionalPrinterColumns:
+    - jsonPath: .spec.cronSpec
+      name: Spec
+      type: string
+    name: v1
+    schema:
+      openAPIV3Schema:
+        properties:
+          spec:
+            properties:
+              cronSpec:
+                type: string
+            type: object
+        type: object
+    served: true
+    storage: true
+`
+
+var testManifestPodSecurityPolicy = `---
+apiVersion: policy/v1beta1
+kind: PodSecurityPolicy
+metadata:
+  name: restricted
+spec:
+  privileged: false
+`
+
+func mustDecode(t *testing.T, yaml string) *unstructured.Unstructured {
+	docs, err := k8syaml.Decode(strings.NewReader(yaml))
+	if err != nil || len(docs) != 1 {
+		t.Fatalf("error decoding test manifest: %v", err)
+	}
+
+	return docs[0]
+}
+
+func TestMigrate(t *testing.T) {
+	tests := []struct {
+		name              string
+		doc               string
+		version           string
+		includeDeprecated bool
+		want              string
+		wantMigrated      bool
+		wantNotes         []string
+	}{
+		{
+			name:         "ingress",
+			doc:          testManifestIngress,
+			version:      "1.29",
+			want:         testManifestIngressMigrated,
+			wantMigrated: true,
+			wantNotes:    []string{"the annotation kubernetes.io/ingress.class is deprecated, consider using spec.ingressClassName: nginx"},
+		},
+		{
+			name:         "ingress without service name",
+			doc:          testManifestIngressWithoutServiceName,
+			version:      "1.29",
+			want:         testManifestIngressWithoutServiceNameMigrated,
+			wantMigrated: true,
+			wantNotes:    []string{"spec.rules[0].http.paths[0].backend.serviceName is missing, but service.name is required in networking.k8s.io/v1"},
+		},
+		{
+			name:         "cron job",
+			doc:          testManifestCronJob,
+			version:      "1.29",
+			want:         testManifestCronJobMigrated,
+			wantMigrated: true,
+			wantNotes:    nil,
+		},
+		{
+			name:         "cron job still served",
+			doc:          testManifestCronJob,
+			version:      "1.22",
+			want:         testManifestCronJob,
+			wantMigrated: false,
+			wantNotes:    nil,
+		},
+		{
+			name:              "cron job deprecated",
+			doc:               testManifestCronJob,
+			version:           "1.22",
+			includeDeprecated: true,
+			want:              testManifestCronJobMigrated,
+			wantMigrated:      true,
+			wantNotes:         nil,
+		},
+		{
+			name:         "custom resource definition",
+			doc:          testManifestCRD,
+			version:      "1.29",
+			want:         testManifestCRDMigrated,
+			wantMigrated: true,
+			wantNotes:    nil,
+		},
+		{
+			name:         "no replacement",
+			doc:          testManifestPodSecurityPolicy,
+			version:      "1.29",
+			want:         testManifestPodSecurityPolicy,
+			wantMigrated: false,
+			wantNotes:    []string{"policy/v1beta1 PodSecurityPolicy has no replacement and cannot be migrated automatically"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := mustDecode(t, tt.doc)
+			gotMigrated, gotNotes := Migrate(doc, kubeversion.MustParse(tt.version), tt.includeDeprecated)
+			if gotMigrated != tt.wantMigrated {
+				t.Errorf("Migrate() migrated = %v, want %v", gotMigrated, tt.wantMigrated)
+			}
+
+			var gotMessages []string
+			for _, note := range gotNotes {
+				gotMessages = append(gotMessages, note.Message)
+			}
+			if !reflect.DeepEqual(gotMessages, tt.wantNotes) {
+				t.Errorf("Migrate() notes = %q, want %q", gotMessages, tt.wantNotes)
+			}
+
+			var out bytes.Buffer
+			if err := k8syaml.Encode(&out, []*unstructured.Unstructured{doc}); err != nil {
+				t.Fatal(err)
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("Migrate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package validate
+
+//go:generate go run gen_schemas.go 1.25 1.26 1.27 1.28 1.29 1.30 1.31 1.32 1.33 1.34
+
+import (
+	"compress/gzip"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/frigus02/kyml/pkg/kubeversion"
+)
+
+//go:embed schemas/*.json.gz
+var bundledSchemas embed.FS
+
+// BundledVersions returns all Kubernetes versions, for which schemas are
+// bundled with kyml, sorted from oldest to newest.
+func BundledVersions() []kubeversion.Version {
+	entries, err := bundledSchemas.ReadDir("schemas")
+	if err != nil {
+		panic(err)
+	}
+
+	var versions []kubeversion.Version
+	for _, entry := range entries {
+		versions = append(versions, kubeversion.MustParse(strings.TrimSuffix(entry.Name(), ".json.gz")))
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Less(versions[j])
+	})
+
+	return versions
+}
+
+// LatestBundledVersion returns the newest Kubernetes version, for which
+// schemas are bundled with kyml.
+func LatestBundledVersion() kubeversion.Version {
+	versions := BundledVersions()
+	return versions[len(versions)-1]
+}
+
+func loadBundledSchemas(version kubeversion.Version) (map[string]*Schema, error) {
+	file, err := bundledSchemas.Open(path.Join("schemas", version.String()+".json.gz"))
+	if err != nil {
+		var supported []string
+		for _, v := range BundledVersions() {
+			supported = append(supported, v.String())
+		}
+
+		return nil, fmt.Errorf("no schemas bundled for Kubernetes %s (supported are %s)", version, strings.Join(supported, ", "))
+	}
+	defer file.Close()
+
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var definitions map[string]*Schema
+	if err = json.NewDecoder(zr).Decode(&definitions); err != nil {
+		return nil, err
+	}
+
+	return definitions, nil
+}
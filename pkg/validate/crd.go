@@ -0,0 +1,133 @@
+package validate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/frigus02/kyml/pkg/k8syaml"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+// IsCRD returns true if the document is a CustomResourceDefinition.
+func IsCRD(doc *unstructured.Unstructured) bool {
+	gvk := doc.GroupVersionKind()
+	return gvk.Group == "apiextensions.k8s.io" && gvk.Kind == "CustomResourceDefinition"
+}
+
+// AddCRD adds the schemas of all versions defined in the specified
+// CustomResourceDefinition. Both apiextensions.k8s.io/v1 and v1beta1 are
+// supported. Versions without schema are skipped.
+func (v *Validator) AddCRD(doc *unstructured.Unstructured) error {
+	if !IsCRD(doc) {
+		return fmt.Errorf("%s is not a CustomResourceDefinition", k8syaml.ResourceName(doc))
+	}
+
+	obj := doc.UnstructuredContent()
+	group, _, _ := unstructured.NestedString(obj, "spec", "group")
+	kind, _, _ := unstructured.NestedString(obj, "spec", "names", "kind")
+	if group == "" || kind == "" {
+		return fmt.Errorf("%s: spec.group and spec.names.kind are required", k8syaml.ResourceName(doc))
+	}
+
+	// apiextensions.k8s.io/v1beta1 allowed a single schema for all versions.
+	commonSchema, _, _ := unstructured.NestedMap(obj, "spec", "validation", "openAPIV3Schema")
+	if version, _, _ := unstructured.NestedString(obj, "spec", "version"); version != "" && commonSchema != nil {
+		if err := v.addCRDVersion(schema.GroupVersionKind{Group: group, Version: version, Kind: kind}, commonSchema); err != nil {
+			return fmt.Errorf("%s: %v", k8syaml.ResourceName(doc), err)
+		}
+	}
+
+	versions, _, _ := unstructured.NestedSlice(obj, "spec", "versions")
+	for _, version := range versions {
+		version, ok := version.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		name, _, _ := unstructured.NestedString(version, "name")
+		versionSchema, _, _ := unstructured.NestedMap(version, "schema", "openAPIV3Schema")
+		if versionSchema == nil {
+			versionSchema = commonSchema
+		}
+		if name == "" || versionSchema == nil {
+			continue
+		}
+
+		if err := v.addCRDVersion(schema.GroupVersionKind{Group: group, Version: name, Kind: kind}, versionSchema); err != nil {
+			return fmt.Errorf("%s: %v", k8syaml.ResourceName(doc), err)
+		}
+	}
+
+	return nil
+}
+
+func (v *Validator) addCRDVersion(gvk schema.GroupVersionKind, openAPIV3Schema map[string]interface{}) error {
+	data, err := json.Marshal(openAPIV3Schema)
+	if err != nil {
+		return err
+	}
+
+	var s Schema
+	if err = json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("invalid schema for version %s: %v", gvk.Version, err)
+	}
+
+	// Custom resource schemas usually don't specify the fields every
+	// Kubernetes object has.
+	if s.Properties == nil {
+		s.Properties = make(map[string]*Schema)
+	}
+	if _, ok := s.Properties["apiVersion"]; !ok {
+		s.Properties["apiVersion"] = &Schema{Type: "string"}
+	}
+	if _, ok := s.Properties["kind"]; !ok {
+		s.Properties["kind"] = &Schema{Type: "string"}
+	}
+	if _, ok := s.Properties["metadata"]; !ok {
+		s.Properties["metadata"] = &Schema{Ref: "#/components/schemas/" + objectMetaDefinition}
+	}
+
+	s.GroupVersionKinds = []schema.GroupVersionKind{gvk}
+	v.addDefinitions(map[string]*Schema{"crd:" + gvk.String(): &s})
+
+	return nil
+}
+
+// AddSchemaFile adds schemas from the specified file content. The file can
+// either be an OpenAPI v3 document with schemas in components.schemas, or a
+// YAML stream containing CustomResourceDefinitions.
+func (v *Validator) AddSchemaFile(data []byte) error {
+	var openAPIDoc struct {
+		Components struct {
+			Schemas map[string]*Schema `json:"schemas"`
+		} `json:"components"`
+		Definitions map[string]*Schema `json:"definitions"`
+	}
+	if err := yaml.Unmarshal(data, &openAPIDoc); err == nil &&
+		(len(openAPIDoc.Components.Schemas) > 0 || len(openAPIDoc.Definitions) > 0) {
+		v.addDefinitions(openAPIDoc.Components.Schemas)
+		v.addDefinitions(openAPIDoc.Definitions)
+		return nil
+	}
+
+	documents, err := k8syaml.Decode(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	for _, doc := range documents {
+		if !IsCRD(doc) {
+			return fmt.Errorf("%s is neither an OpenAPI document nor a CustomResourceDefinition", k8syaml.ResourceName(doc))
+		}
+
+		if err = v.AddCRD(doc); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
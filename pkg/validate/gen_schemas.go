@@ -0,0 +1,132 @@
+//go:build ignore
+// +build ignore
+
+// This program generates the bundled schemas in the schemas directory from the
+// OpenAPI v3 specs published in the Kubernetes repository. Run it using
+// "go generate ./pkg/validate".
+//
+// Descriptions, defaults and merge strategies are removed from the schemas,
+// because validation doesn't need them and they make up most of the size.
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+var removedKeys = map[string]bool{
+	"default":                      true,
+	"x-kubernetes-list-map-keys":   true,
+	"x-kubernetes-list-type":       true,
+	"x-kubernetes-map-type":        true,
+	"x-kubernetes-patch-merge-key": true,
+	"x-kubernetes-patch-strategy":  true,
+	"x-kubernetes-unions":          true,
+}
+
+func main() {
+	for _, version := range os.Args[1:] {
+		if err := generate(version); err != nil {
+			fmt.Fprintf(os.Stderr, "error generating schemas for %s: %v\n", version, err)
+			os.Exit(1)
+		}
+	}
+}
+
+func generate(version string) error {
+	out, err := exec.Command("go", "mod", "download", "-json", "k8s.io/kubernetes@v"+version+".0").Output()
+	if err != nil {
+		return fmt.Errorf("download kubernetes module: %v", err)
+	}
+
+	var module struct{ Dir string }
+	if err = json.Unmarshal(out, &module); err != nil {
+		return err
+	}
+
+	specFiles, err := filepath.Glob(filepath.Join(module.Dir, "api", "openapi-spec", "v3", "*.json"))
+	if err != nil {
+		return err
+	}
+
+	schemas := make(map[string]interface{})
+	for _, specFile := range specFiles {
+		data, err := ioutil.ReadFile(specFile)
+		if err != nil {
+			return err
+		}
+
+		var spec struct {
+			Components struct {
+				Schemas map[string]map[string]interface{} `json:"schemas"`
+			} `json:"components"`
+		}
+		if err = json.Unmarshal(data, &spec); err != nil {
+			return fmt.Errorf("%s: %v", specFile, err)
+		}
+
+		for name, schema := range spec.Components.Schemas {
+			schemas[name] = strip(schema)
+		}
+	}
+
+	data, err := json.Marshal(schemas)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err = zw.Write(data); err != nil {
+		return err
+	}
+	if err = zw.Close(); err != nil {
+		return err
+	}
+
+	name := strings.Join(strings.Split(version, ".")[:2], ".")
+	return ioutil.WriteFile(filepath.Join("schemas", name+".json.gz"), buf.Bytes(), 0644)
+}
+
+func strip(schema map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(schema))
+	for key, value := range schema {
+		if removedKeys[key] {
+			continue
+		}
+
+		if _, ok := value.(string); ok && key == "description" {
+			continue
+		}
+
+		switch key {
+		case "properties":
+			properties := make(map[string]interface{})
+			for name, property := range value.(map[string]interface{}) {
+				properties[name] = strip(property.(map[string]interface{}))
+			}
+			value = properties
+		case "items", "additionalProperties":
+			if s, ok := value.(map[string]interface{}); ok {
+				value = strip(s)
+			}
+		case "allOf", "anyOf", "oneOf":
+			var list []interface{}
+			for _, s := range value.([]interface{}) {
+				list = append(list, strip(s.(map[string]interface{})))
+			}
+			value = list
+		}
+
+		result[key] = value
+	}
+
+	return result
+}
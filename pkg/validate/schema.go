@@ -0,0 +1,59 @@
+package validate
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Schema is the subset of an OpenAPI v3 schema, which is used by Kubernetes
+// and understood by the validator.
+type Schema struct {
+	Type                  string                    `json:"type,omitempty"`
+	Format                string                    `json:"format,omitempty"`
+	Properties            map[string]*Schema        `json:"properties,omitempty"`
+	AdditionalProperties  *Schema                   `json:"additionalProperties,omitempty"`
+	Items                 *Schema                   `json:"items,omitempty"`
+	Required              []string                  `json:"required,omitempty"`
+	Enum                  []interface{}             `json:"enum,omitempty"`
+	AllOf                 []*Schema                 `json:"allOf,omitempty"`
+	AnyOf                 []*Schema                 `json:"anyOf,omitempty"`
+	OneOf                 []*Schema                 `json:"oneOf,omitempty"`
+	Ref                   string                    `json:"$ref,omitempty"`
+	IntOrString           bool                      `json:"x-kubernetes-int-or-string,omitempty"`
+	PreserveUnknownFields bool                      `json:"x-kubernetes-preserve-unknown-fields,omitempty"`
+	EmbeddedResource      bool                      `json:"x-kubernetes-embedded-resource,omitempty"`
+	GroupVersionKinds     []schema.GroupVersionKind `json:"x-kubernetes-group-version-kind,omitempty"`
+}
+
+// UnmarshalJSON decodes a schema. In addition to schema objects it accepts
+// boolean schemas, which can appear in additionalProperties.
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	switch string(bytes.TrimSpace(data)) {
+	case "true":
+		*s = Schema{PreserveUnknownFields: true}
+		return nil
+	case "false":
+		*s = Schema{}
+		return nil
+	}
+
+	type plainSchema Schema
+	var plain plainSchema
+	if err := json.Unmarshal(data, &plain); err != nil {
+		return err
+	}
+
+	*s = Schema(plain)
+	return nil
+}
+
+func (s *Schema) isIntOrString() bool {
+	return s.IntOrString || s.Format == "int-or-string"
+}
+
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
@@ -0,0 +1,250 @@
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/frigus02/kyml/pkg/fieldpath"
+	"github.com/frigus02/kyml/pkg/kubeversion"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const objectMetaDefinition = "io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta"
+
+// FieldError describes a single problem in a Kubernetes document.
+type FieldError struct {
+	Path    fieldpath.Path
+	Message string
+}
+
+func (e FieldError) Error() string {
+	if len(e.Path) == 0 {
+		return e.Message
+	}
+
+	return e.Path.String() + ": " + e.Message
+}
+
+// Validator validates Kubernetes documents against OpenAPI v3 schemas.
+type Validator struct {
+	definitions map[string]*Schema
+	kinds       map[schema.GroupVersionKind]*Schema
+}
+
+// NewValidator creates a new validator using the schemas bundled for the
+// specified Kubernetes version.
+func NewValidator(version kubeversion.Version) (*Validator, error) {
+	definitions, err := loadBundledSchemas(version)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &Validator{
+		definitions: make(map[string]*Schema),
+		kinds:       make(map[schema.GroupVersionKind]*Schema),
+	}
+	v.addDefinitions(definitions)
+
+	return v, nil
+}
+
+func (v *Validator) addDefinitions(definitions map[string]*Schema) {
+	for name, s := range definitions {
+		v.definitions[name] = s
+		for _, gvk := range s.GroupVersionKinds {
+			v.kinds[gvk] = s
+		}
+	}
+}
+
+// Validate validates the specified document against the schema for its
+// apiVersion and kind. It returns an error if no schema is known for them.
+func (v *Validator) Validate(doc *unstructured.Unstructured) ([]FieldError, error) {
+	gvk := doc.GroupVersionKind()
+	s, ok := v.kinds[gvk]
+	if !ok {
+		return nil, fmt.Errorf("no schema found for %s", gvk)
+	}
+
+	var errs []FieldError
+	v.validateValue(doc.Object, s, nil, &errs)
+
+	return errs, nil
+}
+
+func (v *Validator) resolve(s *Schema) *Schema {
+	for s != nil && s.Ref != "" {
+		s = v.definitions[refName(s.Ref)]
+	}
+
+	return s
+}
+
+func (v *Validator) validateValue(value interface{}, s *Schema, path fieldpath.Path, errs *[]FieldError) {
+	s = v.resolve(s)
+	if s == nil || value == nil {
+		return
+	}
+
+	for _, sub := range s.AllOf {
+		v.validateValue(value, sub, path, errs)
+	}
+
+	if s.isIntOrString() {
+		switch value.(type) {
+		case int64, string:
+		default:
+			v.addError(errs, path, "must be an integer or a string, got %s", typeName(value))
+		}
+		return
+	}
+
+	if len(s.OneOf) > 0 || len(s.AnyOf) > 0 {
+		if !v.matchesAny(value, append(s.OneOf, s.AnyOf...), path) {
+			v.addError(errs, path, "must be %s, got %s", v.describeTypes(append(s.OneOf, s.AnyOf...)), typeName(value))
+			return
+		}
+	}
+
+	if s.Type != "" && !hasType(value, s.Type) {
+		v.addError(errs, path, "must be of type %s, got %s", s.Type, typeName(value))
+		return
+	}
+
+	if len(s.Enum) > 0 && !containsValue(s.Enum, value) {
+		v.addError(errs, path, "unsupported value %v", value)
+	}
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		v.validateObject(value, s, path, errs)
+	case []interface{}:
+		for i, item := range value {
+			v.validateValue(item, s.Items, path.Child(fieldpath.Index(i)), errs)
+		}
+	}
+}
+
+func (v *Validator) validateObject(obj map[string]interface{}, s *Schema, path fieldpath.Path, errs *[]FieldError) {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		childPath := path.Child(fieldpath.Field(key))
+		if property, ok := s.Properties[key]; ok {
+			v.validateValue(obj[key], property, childPath, errs)
+		} else if s.EmbeddedResource && (key == "apiVersion" || key == "kind" || key == "metadata") {
+			if key == "metadata" {
+				v.validateValue(obj[key], v.definitions[objectMetaDefinition], childPath, errs)
+			}
+		} else if s.AdditionalProperties != nil {
+			v.validateValue(obj[key], s.AdditionalProperties, childPath, errs)
+		} else if len(s.Properties) > 0 && !s.PreserveUnknownFields {
+			v.addError(errs, childPath, "unknown field")
+		}
+	}
+
+	for _, key := range s.Required {
+		if _, ok := obj[key]; !ok {
+			v.addError(errs, path.Child(fieldpath.Field(key)), "required field is missing")
+		}
+	}
+}
+
+func (v *Validator) matchesAny(value interface{}, schemas []*Schema, path fieldpath.Path) bool {
+	for _, s := range schemas {
+		var errs []FieldError
+		v.validateValue(value, s, path, &errs)
+		if len(errs) == 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (v *Validator) describeTypes(schemas []*Schema) string {
+	var types []string
+	for _, s := range schemas {
+		if s = v.resolve(s); s != nil && s.Type != "" {
+			types = append(types, s.Type)
+		}
+	}
+
+	return "one of " + strings.Join(types, ", ")
+}
+
+func (v *Validator) addError(errs *[]FieldError, path fieldpath.Path, format string, a ...interface{}) {
+	*errs = append(*errs, FieldError{Path: path, Message: fmt.Sprintf(format, a...)})
+}
+
+func hasType(value interface{}, t string) bool {
+	switch t {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "integer":
+		_, ok := value.(int64)
+		return ok
+	case "number":
+		switch value.(type) {
+		case int64, float64:
+			return true
+		}
+		return false
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	default:
+		return true
+	}
+}
+
+func typeName(value interface{}) string {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return fmt.Sprintf("string %q", value)
+	case int64:
+		return "integer"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if reflect.DeepEqual(v, value) {
+			return true
+		}
+
+		// Enums decoded from JSON contain float64 for all numbers.
+		if f, ok := v.(float64); ok {
+			if i, ok := value.(int64); ok && float64(i) == f {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,230 @@
+package validate
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/frigus02/kyml/pkg/k8syaml"
+	"github.com/frigus02/kyml/pkg/kubeversion"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var testManifestDeployment = `---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: the-deployment
+  labels:
+    app: hello
+spec:
+  replicas: 2
+  selector:
+    matchLabels:
+      app: hello
+  template:
+    metadata:
+      labels:
+        app: hello
+    spec:
+      containers:
+      - name: the-container
+        image: kyml/hello
+        ports:
+        - containerPort: 80
+        resources:
+          limits:
+            cpu: 0.5
+            memory: 128Mi
+        readinessProbe:
+          httpGet:
+            path: /
+            port: http
+`
+
+var testManifestDeploymentInvalid = `---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: the-deployment
+spec:
+  replicas: "3"
+  template:
+    spec:
+      contianers:
+      - name: the-container
+        image: kyml/hello
+`
+
+var testManifestCRD = `---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: crontabs.stable.example.com
+spec:
+  group: stable.example.com
+  names:
+    kind: CronTab
+    plural: crontabs
+  scope: Namespaced
+  versions:
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              cronSpec:
+                type: string
+              replicas:
+                type: integer
+`
+
+var testManifestCronTab = `---
+apiVersion: stable.example.com/v1
+kind: CronTab
+metadata:
+  name: the-crontab
+spec:
+  cronSpec: "* * * * */5"
+  replicas: "1"
+  image: kyml/hello
+`
+
+var testOpenAPIDocument = `
+components:
+  schemas:
+    com.example.stable.v1.CronTab:
+      type: object
+      x-kubernetes-group-version-kind:
+      - group: stable.example.com
+        version: v1
+        kind: CronTab
+      properties:
+        apiVersion:
+          type: string
+        kind:
+          type: string
+        metadata:
+          type: object
+        spec:
+          type: object
+          properties:
+            image:
+              type: string
+`
+
+func mustDecode(t *testing.T, yaml string) *unstructured.Unstructured {
+	docs, err := k8syaml.Decode(strings.NewReader(yaml))
+	if err != nil || len(docs) != 1 {
+		t.Fatalf("error decoding test manifest: %v", err)
+	}
+
+	return docs[0]
+}
+
+func errorStrings(errs []FieldError) []string {
+	var result []string
+	for _, err := range errs {
+		result = append(result, err.Error())
+	}
+
+	return result
+}
+
+func TestNewValidator(t *testing.T) {
+	if _, err := NewValidator(kubeversion.MustParse("1.29")); err != nil {
+		t.Errorf("NewValidator() error = %v", err)
+	}
+
+	if _, err := NewValidator(kubeversion.MustParse("1.2")); err == nil {
+		t.Errorf("NewValidator() expected error for unsupported version")
+	}
+}
+
+func TestValidator_Validate(t *testing.T) {
+	tests := []struct {
+		name       string
+		crd        string
+		schemaFile string
+		doc        string
+		want       []string
+		wantErr    bool
+	}{
+		{
+			name:    "valid",
+			doc:     testManifestDeployment,
+			want:    nil,
+			wantErr: false,
+		},
+		{
+			name: "invalid",
+			doc:  testManifestDeploymentInvalid,
+			want: []string{
+				`spec.replicas: must be of type integer, got string "3"`,
+				"spec.template.spec.contianers: unknown field",
+				"spec.template.spec.containers: required field is missing",
+				"spec.selector: required field is missing",
+			},
+			wantErr: false,
+		},
+		{
+			name:    "no schema",
+			doc:     testManifestCronTab,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "schema from crd",
+			crd:  testManifestCRD,
+			doc:  testManifestCronTab,
+			want: []string{
+				"spec.image: unknown field",
+				`spec.replicas: must be of type integer, got string "1"`,
+			},
+			wantErr: false,
+		},
+		{
+			name:       "schema from openapi document",
+			schemaFile: testOpenAPIDocument,
+			doc:        testManifestCronTab,
+			want: []string{
+				"spec.cronSpec: unknown field",
+				"spec.replicas: unknown field",
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := NewValidator(kubeversion.MustParse("1.29"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.crd != "" {
+				if err = v.AddCRD(mustDecode(t, tt.crd)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.schemaFile != "" {
+				if err = v.AddSchemaFile([]byte(tt.schemaFile)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := v.Validate(mustDecode(t, tt.doc))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validator.Validate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(errorStrings(got), tt.want) {
+				t.Errorf("Validator.Validate() = %q, want %q", errorStrings(got), tt.want)
+			}
+		})
+	}
+}